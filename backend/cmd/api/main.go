@@ -1,11 +1,13 @@
 package main
 
 import (
+	"context"
 	_ "embed"
 
 	configenv "guiio/backend/internal/config"
 	database "guiio/backend/internal/infra/db"
 	"guiio/backend/internal/repository"
+	"guiio/backend/internal/service"
 	httptransport "guiio/backend/internal/transport/http"
 	"guiio/backend/internal/util"
 
@@ -44,12 +46,21 @@ func main() {
 	defer db.Close()
 
 	repo := repository.NewObjectRepository(db)
-	handler, err := httptransport.NewHttpHandler(conf, Mlog, repo)
+	storageService, err := service.NewStorageService(repo)
 	if err != nil {
-		Mlog.Panic().Err(err).Msg("Failed to create http handler")
+		Mlog.Panic().Err(err).Msg("Failed to create storage service")
 		return
 	}
 
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go storageService.RunUploadCleaner(ctx, Mlog)
+	go storageService.RunTusCleaner(ctx, Mlog)
+	go storageService.RunTrashPurger(ctx, Mlog)
+	go storageService.RunBlobCollector(ctx, Mlog)
+	go storageService.RunLifecycleEvaluator(ctx, Mlog)
+
+	handler := httptransport.NewHttpHandler(conf, Mlog, storageService)
 	if err := handler.Start(); err != nil {
 		Mlog.Panic().Err(err).Msg("Failed to start server")
 	}
@@ -0,0 +1,39 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// Blob holds the schema definition for the Blob entity.
+// content-addressable 버킷에서 내용(SHA-256)으로 이름 붙인 공유 백엔드 객체입니다.
+// ref_count는 이 blob을 storage_path로 가리키는 objects 행(휴지통 포함) 수이고, 0이 되면 정리 대상입니다.
+// blob은 서버 전체에서 SHA-256 하나에 하나이며 blob_bucket 설정의 버킷에 저장하므로, 다른 버킷의 같은 본문도 한 blob을 함께 가리킵니다.
+type Blob struct {
+	ent.Schema
+}
+
+// Fields of the Blob.
+func (Blob) Fields() []ent.Field {
+	return []ent.Field{
+		// 16진수 SHA-256입니다.
+		field.String("sha256").NotEmpty().Immutable(),
+		field.String("storage_path").NotEmpty().Immutable(),
+		field.Int64("size").Default(0).Immutable(),
+		field.Int("ref_count").Default(0),
+		field.Time("created_at").Default(time.Now).Immutable(),
+		field.Time("updated_at").Default(time.Now).UpdateDefault(time.Now),
+	}
+}
+
+// Indexes of the Blob.
+func (Blob) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("sha256").Unique(),
+		index.Fields("storage_path").Unique(),
+		index.Fields("ref_count"),
+	}
+}
@@ -0,0 +1,34 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/field"
+)
+
+// BucketConfig holds the schema definition for the BucketConfig entity.
+// 버킷 자체는 스토리지 백엔드에 있고, 여기에는 guiio가 관리하는 버킷 단위 설정만 둡니다.
+type BucketConfig struct {
+	ent.Schema
+}
+
+// Fields of the BucketConfig.
+func (BucketConfig) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("bucket_name").NotEmpty().Unique().Immutable(),
+		// 빈 값(한 번도 켜지 않음), Enabled, Suspended 중 하나입니다.
+		field.String("versioning").Default(""),
+		// 업로드 Content-Type 판별 정책입니다. 빈 값은 sniff-if-missing과 같습니다.
+		field.String("content_type_policy").Default(""),
+		// true이면 새로 올린 객체를 내용 해시 경로(blobs)에 저장해 같은 내용을 한 벌만 둡니다.
+		field.Bool("content_addressable").Default(false),
+		// Object Lock(WORM)은 한 번 켜면 끌 수 없습니다. 기본 보존 설정이 있으면 새로 쓴 객체에 모드와 보존 기한을 붙입니다.
+		field.Bool("object_lock_enabled").Default(false),
+		// 빈 값(기본 보존 없음), GOVERNANCE, COMPLIANCE 중 하나입니다.
+		field.String("default_retention_mode").Default(""),
+		field.Int("default_retention_days").Default(0),
+		field.Time("created_at").Default(time.Now).Immutable(),
+		field.Time("updated_at").Default(time.Now).UpdateDefault(time.Now),
+	}
+}
@@ -0,0 +1,36 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// LifecycleEvent holds the schema definition for the LifecycleEvent entity.
+// 수명 주기 평가가 지운 객체, 버전, 업로드를 하나씩 남기는 기록입니다.
+type LifecycleEvent struct {
+	ent.Schema
+}
+
+// Fields of the LifecycleEvent.
+func (LifecycleEvent) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("bucket_name").NotEmpty().Immutable(),
+		field.String("rule_id").NotEmpty().Immutable(),
+		// expire_object, expire_version, abort_upload 중 하나입니다.
+		field.String("action").NotEmpty().Immutable(),
+		field.String("object_name").NotEmpty().Immutable(),
+		field.String("version_id").Optional().Immutable(),
+		field.String("upload_id").Optional().Immutable(),
+		field.Time("created_at").Default(time.Now).Immutable(),
+	}
+}
+
+// Indexes of the LifecycleEvent.
+func (LifecycleEvent) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("bucket_name", "created_at"),
+	}
+}
@@ -0,0 +1,44 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// LifecycleRule holds the schema definition for the LifecycleRule entity.
+// 버킷 수명 주기 규칙 하나입니다. 버킷 설정을 PUT하면 그 버킷의 행을 모두 대체합니다.
+// 일수 필드가 0이면 그 동작을 쓰지 않습니다.
+type LifecycleRule struct {
+	ent.Schema
+}
+
+// Fields of the LifecycleRule.
+func (LifecycleRule) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("bucket_name").NotEmpty().Immutable(),
+		field.String("rule_id").NotEmpty().MaxLen(255).Immutable(),
+		field.Bool("enabled").Default(true),
+		field.String("prefix").Default(""),
+		// 모든 키/값을 가진 현재 객체에만 만료를 적용합니다.
+		field.JSON("tags", map[string]string{}).Optional(),
+		field.Int("expiration_days").Default(0),
+		// 설정하면 이 시각 이후 필터에 맞는 현재 객체를 모두 만료합니다. expiration_days와 함께 쓸 수 없습니다.
+		field.Time("expiration_date").Optional().Nillable(),
+		// 더 새 버전이 생긴 지 이 일수가 지난 이전 버전을 지웁니다.
+		field.Int("noncurrent_expiration_days").Default(0),
+		field.Int("abort_incomplete_upload_days").Default(0),
+		field.Time("created_at").Default(time.Now).Immutable(),
+		field.Time("updated_at").Default(time.Now).UpdateDefault(time.Now),
+	}
+}
+
+// Indexes of the LifecycleRule.
+func (LifecycleRule) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("bucket_name", "rule_id").Unique(),
+		index.Fields("enabled"),
+	}
+}
@@ -0,0 +1,98 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/dialect"
+	"entgo.io/ent/dialect/entsql"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// Object holds the schema definition for the Object entity.
+type Object struct {
+	ent.Schema
+}
+
+// Fields of the Object.
+func (Object) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("bucket_name").NotEmpty(),
+		field.String("object_name").NotEmpty(),
+		field.String("storage_path").NotEmpty(),
+		field.String("content_type").Default("application/octet-stream"),
+		// content_type을 정한 방법입니다. declared(클라이언트 값), detected(본문/확장자로 판별), default(판별하지 않은 기본값) 중 하나이고,
+		// 이 값이 생기기 전에 올린 객체는 비어 있습니다.
+		field.String("content_type_source").Optional(),
+		field.Int64("size").Default(0),
+		field.String("etag").Default(""),
+		// 비어 있으면 object_cache_control 설정값을 사용합니다.
+		field.String("cache_control").Optional(),
+		// 업로드 스트림에서 계산한 SHA-256, CRC32C(Castagnoli)를 base64로 저장합니다.
+		// 멀티파트 업로드처럼 전체 본문을 서버가 읽지 않은 객체는 비어 있습니다.
+		field.String("checksum_sha256").Optional(),
+		field.String("checksum_crc32c").Optional(),
+		// 메타데이터 PATCH가 적용될 때마다 1씩 증가합니다.
+		field.Int("metadata_revision").Default(0),
+		// Object Lock 보존 설정입니다. retain_until이 지나기 전에는 삭제, 덮어쓰기를 거부하고,
+		// GOVERNANCE는 우회 권한이 있는 요청만 줄이거나 풀 수 있으며 COMPLIANCE는 누구도 줄일 수 없습니다.
+		field.String("retention_mode").Optional(),
+		field.Time("retain_until").Optional().Nillable(),
+		// legal hold는 기한 없이 보존하며, 풀기 전에는 우회 권한으로도 지울 수 없습니다.
+		field.Bool("legal_hold").Default(false),
+		field.Time("created_at").Default(time.Now).Immutable(),
+		field.Time("updated_at").Default(time.Now).UpdateDefault(time.Now),
+		// 휴지통으로 옮긴 시각입니다. nil이 아니면 목록/다운로드에서 보이지 않고,
+		// 보존 기간이 지나면 purge 작업이 백엔드 객체와 함께 지웁니다.
+		field.Time("deleted_at").Optional().Nillable(),
+		// 이름 조각(A 가중치)과 메타데이터 값(B 가중치)의 전문 검색 벡터입니다.
+		// ent가 아니라 repository의 refreshSearchVector가 원시 SQL로 갱신합니다.
+		field.String("search_vector").
+			Optional().
+			SchemaType(map[string]string{dialect.Postgres: "tsvector"}).
+			StructTag(`json:"-"`),
+	}
+}
+
+// Edges of the Object.
+func (Object) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.To("metadata", ObjectMetadata.Type),
+		edge.To("tags", ObjectTag.Type),
+	}
+}
+
+// Indexes of the Object.
+func (Object) Indexes() []ent.Index {
+	return []ent.Index{
+		// 키마다 살아 있는 행과 휴지통 행을 하나씩 둘 수 있습니다. 휴지통에 있는 키에 다시 쓰면 새 행을 만들고,
+		// 휴지통 행은 자기 storage_path와 함께 되살리거나 purge할 때까지 남습니다.
+		index.Fields("bucket_name", "object_name").
+			Unique().
+			Annotations(entsql.IndexWhere("deleted_at IS NULL")).
+			StorageKey("object_bucket_name_object_name"),
+		index.Fields("bucket_name", "object_name").
+			Unique().
+			Annotations(entsql.IndexWhere("deleted_at IS NOT NULL")).
+			StorageKey("object_bucket_name_object_name_trashed"),
+		index.Fields("deleted_at"),
+		// 검색(SearchObjects)의 필터/정렬용입니다. 버킷 조건은 위 unique 인덱스와 함께 씁니다.
+		index.Fields("object_name"),
+		// 이름 접두사/glob 검색(LIKE 'abc%')용입니다. Postgres는 C가 아닌 collation에서 기본 연산자 클래스 인덱스를
+		// LIKE에 쓰지 않으므로 text_pattern_ops로 따로 만듭니다. 와일드카드로 시작하는 glob은 여전히 순차 스캔입니다.
+		index.Fields("bucket_name", "object_name").
+			Annotations(entsql.OpClassColumn("object_name", "text_pattern_ops")).
+			StorageKey("object_bucket_name_object_name_pattern"),
+		index.Fields("object_name").
+			Annotations(entsql.OpClass("text_pattern_ops")).
+			StorageKey("object_object_name_pattern"),
+		index.Fields("content_type"),
+		index.Fields("size"),
+		index.Fields("created_at"),
+		index.Fields("updated_at"),
+		index.Fields("search_vector").
+			Annotations(entsql.IndexType("GIN")),
+	}
+}
@@ -0,0 +1,46 @@
+package schema
+
+import (
+	"entgo.io/ent"
+	"entgo.io/ent/dialect/entsql"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// ObjectMetadata holds the schema definition for the ObjectMetadata entity.
+type ObjectMetadata struct {
+	ent.Schema
+}
+
+// Fields of the ObjectMetadata.
+func (ObjectMetadata) Fields() []ent.Field {
+	return []ent.Field{
+		field.Int("object_id"),
+		field.String("key").NotEmpty(),
+		field.String("value"),
+	}
+}
+
+// Edges of the ObjectMetadata.
+func (ObjectMetadata) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.From("object", Object.Type).
+			Ref("metadata").
+			Field("object_id").
+			Unique().
+			Required(),
+	}
+}
+
+// Indexes of the ObjectMetadata.
+func (ObjectMetadata) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("object_id", "key").Unique(),
+		// 메타데이터 값 검색(일치/접두사)용입니다. 접두사 검색은 LIKE이므로 value를 text_pattern_ops로 둔 인덱스를 씁니다.
+		index.Fields("key", "value"),
+		index.Fields("key", "value").
+			Annotations(entsql.OpClassColumn("value", "text_pattern_ops")).
+			StorageKey("objectmetadata_key_value_pattern"),
+	}
+}
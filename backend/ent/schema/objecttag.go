@@ -0,0 +1,43 @@
+package schema
+
+import (
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// ObjectTag holds the schema definition for the ObjectTag entity.
+// 업로드 때 고정되는 ObjectMetadata와 달리 분류/수명 주기 규칙용으로 언제든 바꿀 수 있는 태그입니다.
+type ObjectTag struct {
+	ent.Schema
+}
+
+// Fields of the ObjectTag.
+func (ObjectTag) Fields() []ent.Field {
+	return []ent.Field{
+		field.Int("object_id"),
+		field.String("key").NotEmpty().MaxLen(128),
+		field.String("value").MaxLen(256),
+	}
+}
+
+// Edges of the ObjectTag.
+func (ObjectTag) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.From("object", Object.Type).
+			Ref("tags").
+			Field("object_id").
+			Unique().
+			Required(),
+	}
+}
+
+// Indexes of the ObjectTag.
+func (ObjectTag) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("object_id", "key").Unique(),
+		// 목록의 tag=k:v 필터용입니다.
+		index.Fields("key", "value"),
+	}
+}
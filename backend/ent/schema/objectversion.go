@@ -0,0 +1,45 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// ObjectVersion holds the schema definition for the ObjectVersion entity.
+// 버전 관리가 켜진 버킷에서 업로드/삭제마다 한 행씩 쌓이는 불변 이력입니다.
+// 현재 상태는 여전히 objects/object_metadata 행이고, 이 행은 그 시점의 스냅샷입니다.
+type ObjectVersion struct {
+	ent.Schema
+}
+
+// Fields of the ObjectVersion.
+func (ObjectVersion) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("version_id").NotEmpty().Immutable(),
+		field.String("bucket_name").NotEmpty().Immutable(),
+		field.String("object_name").NotEmpty().Immutable(),
+		// 버전 콘텐츠의 백엔드 복사본 경로입니다. 삭제 마커는 비어 있습니다.
+		field.String("storage_path").Optional().Immutable(),
+		field.String("content_type").Default("application/octet-stream").Immutable(),
+		// 버전을 복원할 때 objects.content_type_source로 되돌립니다. 이 값이 생기기 전의 버전은 비어 있습니다.
+		field.String("content_type_source").Optional().Immutable(),
+		field.Int64("size").Default(0).Immutable(),
+		field.String("etag").Default("").Immutable(),
+		field.String("checksum_sha256").Optional().Immutable(),
+		field.String("checksum_crc32c").Optional().Immutable(),
+		field.Bool("is_delete_marker").Default(false).Immutable(),
+		field.JSON("metadata", map[string]string{}).Optional().Immutable(),
+		field.Time("created_at").Default(time.Now).Immutable(),
+	}
+}
+
+// Indexes of the ObjectVersion.
+func (ObjectVersion) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("bucket_name", "object_name", "version_id").Unique(),
+		index.Fields("bucket_name", "object_name", "created_at"),
+	}
+}
@@ -0,0 +1,40 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// TusUpload holds the schema definition for the TusUpload entity.
+// tus 프로토콜 업로드 한 건의 진행 상태입니다. 받은 바이트는 로컬 스테이징 파일에 쌓이고,
+// 업로드가 끝나면 객체로 저장한 뒤 행을 삭제합니다.
+type TusUpload struct {
+	ent.Schema
+}
+
+// Fields of the TusUpload.
+func (TusUpload) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("upload_id").NotEmpty().Unique().Immutable(),
+		field.String("bucket_name").NotEmpty().Immutable(),
+		field.String("object_name").NotEmpty().Immutable(),
+		field.String("content_type").Default("application/octet-stream"),
+		field.Int64("upload_length").NonNegative().Immutable(),
+		field.Int64("upload_offset").NonNegative().Default(0),
+		// 클라이언트가 보낸 Upload-Metadata 헤더 원문으로, HEAD 응답에 그대로 돌려줍니다.
+		field.String("upload_metadata").Optional(),
+		field.Time("expires_at"),
+		field.Time("created_at").Default(time.Now).Immutable(),
+		field.Time("updated_at").Default(time.Now).UpdateDefault(time.Now),
+	}
+}
+
+// Indexes of the TusUpload.
+func (TusUpload) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("expires_at"),
+	}
+}
@@ -0,0 +1,45 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// UploadPart holds the schema definition for the UploadPart entity.
+type UploadPart struct {
+	ent.Schema
+}
+
+// Fields of the UploadPart.
+func (UploadPart) Fields() []ent.Field {
+	return []ent.Field{
+		field.Int("session_id"),
+		field.Int("part_number").Positive(),
+		field.String("etag").NotEmpty(),
+		field.Int64("size").NonNegative(),
+		field.Time("created_at").Default(time.Now).Immutable(),
+		field.Time("updated_at").Default(time.Now).UpdateDefault(time.Now),
+	}
+}
+
+// Edges of the UploadPart.
+func (UploadPart) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.From("session", UploadSession.Type).
+			Ref("parts").
+			Field("session_id").
+			Unique().
+			Required(),
+	}
+}
+
+// Indexes of the UploadPart.
+func (UploadPart) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("session_id", "part_number").Unique(),
+	}
+}
@@ -0,0 +1,48 @@
+package schema
+
+import (
+	"time"
+
+	"entgo.io/ent"
+	"entgo.io/ent/schema/edge"
+	"entgo.io/ent/schema/field"
+	"entgo.io/ent/schema/index"
+)
+
+// UploadSession holds the schema definition for the UploadSession entity.
+// 멀티파트 업로드 한 건을 나타내며, 완료/중단되면 파트와 함께 삭제됩니다.
+type UploadSession struct {
+	ent.Schema
+}
+
+// Fields of the UploadSession.
+func (UploadSession) Fields() []ent.Field {
+	return []ent.Field{
+		field.String("upload_id").NotEmpty().Unique().Immutable(),
+		field.String("bucket_name").NotEmpty().Immutable(),
+		field.String("object_name").NotEmpty().Immutable(),
+		field.String("storage_path").NotEmpty().Immutable(),
+		field.String("backend_upload_id").NotEmpty().Immutable(),
+		field.String("content_type").Default("application/octet-stream"),
+		field.JSON("metadata", map[string]string{}).Optional(),
+		field.Time("created_at").Default(time.Now).Immutable(),
+		// updated_at은 파트를 올릴 때마다 갱신되는 마지막 활동 시각입니다.
+		field.Time("updated_at").Default(time.Now).UpdateDefault(time.Now),
+	}
+}
+
+// Edges of the UploadSession.
+func (UploadSession) Edges() []ent.Edge {
+	return []ent.Edge{
+		edge.To("parts", UploadPart.Type),
+	}
+}
+
+// Indexes of the UploadSession.
+func (UploadSession) Indexes() []ent.Index {
+	return []ent.Index{
+		index.Fields("bucket_name", "object_name"),
+		// 정리 작업은 마지막 활동 시각으로 만료된 세션을 찾습니다.
+		index.Fields("updated_at"),
+	}
+}
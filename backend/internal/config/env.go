@@ -23,6 +23,43 @@ var (
 		"storage_use_ssl":      false,
 		"cors_allow_origin":    "*",
 		"object_cache_control": "public, max-age=60",
+		// 0 이하이면 업로드 크기를 제한하지 않습니다.
+		"object_max_upload_size": int64(5 << 30),
+		// 멀티파트 세션은 마지막 파트 업로드(없으면 시작) 후 max_age가 지나면 정리 주기에 맞춰 중단됩니다.
+		"multipart_max_age_hours":            24,
+		"multipart_cleanup_interval_minutes": 60,
+		// tus 업로드 스테이징 디렉터리입니다. 비어 있으면 OS 임시 디렉터리 아래 guiio-tus를 씁니다.
+		"tus_upload_dir": "",
+		// 마지막 PATCH 이후 이 시간이 지나도록 끝나지 않은 tus 업로드는 만료됩니다.
+		"tus_upload_expiry_hours": 24,
+		// 만료된 tus 업로드를 지우는 주기입니다. 0 이하이면 정리하지 않습니다.
+		"tus_cleanup_interval_minutes": 60,
+		// 삭제한 객체를 휴지통에 보관하는 시간입니다. 0 이하이면 휴지통 없이 바로 삭제합니다.
+		"trash_retention_hours":        168,
+		"trash_purge_interval_minutes": 60,
+		// 아카이브 다운로드 한 번에 담을 수 있는 객체 수와 원본 크기 합계입니다. 0 이하이면 제한하지 않습니다.
+		"archive_max_objects": 10000,
+		"archive_max_size":    int64(10 << 30),
+		// 업로드한 아카이브를 풀 때 허용하는 항목 수와 압축 해제 후 크기 합계입니다. 0 이하이면 제한하지 않습니다.
+		"extract_max_entries": 10000,
+		"extract_max_size":    int64(10 << 30),
+		// 다운로드를 Accept-Encoding에 맞춰 압축할 Content-Type 목록(쉼표 구분, "text/*" 같은 패턴 허용)과 최소 크기입니다.
+		// 목록이 비어 있으면 압축하지 않고, 이미 압축된 형식(이미지, 영상, zip 등)은 목록에 걸려도 건너뜁니다.
+		"download_compression_types":    "text/*,application/json,application/*+json,application/x-ndjson,application/xml,application/*+xml,application/javascript,image/svg+xml",
+		"download_compression_min_size": int64(1024),
+		// ?w=&h= 이미지 리사이즈에 허용하는 가로/세로 크기 목록(쉼표 구분)과 디코딩할 원본의 최대 픽셀 수입니다.
+		"image_resize_sizes":      "64,128,256,512,1024,2048",
+		"image_resize_max_pixels": int64(50_000_000),
+		// content-addressable 버킷에서 참조가 모두 사라진 blob을 지우는 주기입니다. 0 이하이면 정리하지 않습니다.
+		"blob_gc_interval_minutes": 60,
+		// content-addressable 버킷의 blob을 모아 두는 백엔드 버킷입니다. 없으면 처음 blob을 저장할 때 만들고, 사용자 버킷 이름으로는 쓸 수 없습니다.
+		"blob_bucket": "guiio-blobs",
+		// X-Guiio-Bypass-Governance-Retention 헤더로 이 값을 보낸 요청만 GOVERNANCE 보존을 우회할 수 있습니다. 비어 있으면 아무도 우회할 수 없습니다.
+		"object_lock_bypass_token": "",
+		// 다운로드 링크의 response-* 쿼리를 서명하는 HMAC 키입니다. 비어 있으면 response-* 쿼리를 받지 않습니다.
+		"response_override_secret": "",
+		// 버킷 수명 주기 규칙을 평가하는 주기입니다. 0 이하이면 규칙을 적용하지 않습니다.
+		"lifecycle_interval_minutes": 60,
 	}
 )
 
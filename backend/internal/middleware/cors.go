@@ -12,9 +12,16 @@ func CORSMiddleware(allowOrigin string) func(next http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			w.Header().Set("Access-Control-Allow-Origin", allowOrigin)
-			w.Header().Set("Access-Control-Allow-Methods", "GET,POST,DELETE,OPTIONS")
-			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
-			if r.Method == http.MethodOptions {
+			w.Header().Set("Access-Control-Allow-Methods", "GET,HEAD,POST,PUT,PATCH,DELETE,OPTIONS")
+			// X-Guiio-Meta-* 처럼 이름이 정해지지 않은 헤더가 있어 preflight 요청 헤더를 그대로 허용합니다.
+			allowHeaders := r.Header.Get("Access-Control-Request-Headers")
+			if allowHeaders == "" {
+				allowHeaders = "Content-Type, Authorization, If-Match, If-None-Match, If-Modified-Since, Range, If-Range, Tus-Resumable, Upload-Length, Upload-Offset, Upload-Metadata"
+			}
+			w.Header().Set("Access-Control-Allow-Headers", allowHeaders)
+			w.Header().Set("Access-Control-Expose-Headers", "*")
+			// tus OPTIONS 같은 일반 OPTIONS 요청은 라우터까지 보내고 preflight만 여기서 끝냅니다.
+			if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
 				w.WriteHeader(http.StatusNoContent)
 				return
 			}
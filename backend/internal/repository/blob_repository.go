@@ -0,0 +1,136 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"guiio/backend/ent"
+	"guiio/backend/ent/blob"
+)
+
+// BlobRepository는 content-addressable 버킷들이 함께 쓰는 blob과 참조 수를 다룹니다.
+// 참조 수는 objects 행의 storage_path가 바뀌거나 행이 지워질 때 upsertObject/deleteObject가 같은 트랜잭션에서 맞추므로,
+// 여기서는 참조가 없는 blob을 찾아 지우는 일만 합니다.
+type BlobRepository interface {
+	// BeginBlobTx는 blob 경로를 잠급니다. 행이 없으면 Current가 nil입니다.
+	BeginBlobTx(ctx context.Context, storagePath string) (BlobTx, error)
+	// ListUnreferencedBlobs는 ref_count가 0인 blob을 반환합니다.
+	ListUnreferencedBlobs(ctx context.Context, limit int) ([]*ent.Blob, error)
+}
+
+// BlobTx는 한 blob 경로에 대한 참조 추가와 정리를 직렬화하는 트랜잭션입니다.
+// 정리하는 쪽은 잠근 채 ref_count가 0인지 확인하고 백엔드 객체를 지운 뒤 Delete, Commit합니다.
+type BlobTx interface {
+	Current() *ent.Blob
+	Delete(ctx context.Context) error
+	Commit() error
+	// Rollback은 Commit 이후에 호출해도 안전하므로 defer로 걸어 둡니다.
+	Rollback() error
+}
+
+type blobTx struct {
+	tx      *ent.Tx
+	current *ent.Blob
+	done    bool
+}
+
+func (r *objectRepository) BeginBlobTx(ctx context.Context, storagePath string) (BlobTx, error) {
+	tx, err := r.db.Tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockBlob(ctx, tx, storagePath); err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	current, err := tx.Blob.
+		Query().
+		Where(blob.StoragePathEQ(storagePath)).
+		Only(ctx)
+	if err != nil {
+		if !ent.IsNotFound(err) {
+			tx.Rollback()
+			return nil, err
+		}
+		current = nil
+	}
+	return &blobTx{tx: tx, current: current}, nil
+}
+
+func (t *blobTx) Current() *ent.Blob {
+	return t.current
+}
+
+func (t *blobTx) Delete(ctx context.Context) error {
+	if t.current == nil {
+		return &ent.NotFoundError{}
+	}
+	return t.tx.Blob.DeleteOneID(t.current.ID).Exec(ctx)
+}
+
+func (t *blobTx) Commit() error {
+	t.done = true
+	return t.tx.Commit()
+}
+
+func (t *blobTx) Rollback() error {
+	if t.done {
+		return nil
+	}
+	t.done = true
+	return t.tx.Rollback()
+}
+
+func (r *objectRepository) ListUnreferencedBlobs(ctx context.Context, limit int) ([]*ent.Blob, error) {
+	return r.db.Blob.
+		Query().
+		Where(blob.RefCountLTE(0)).
+		Order(blob.ByID()).
+		Limit(limit).
+		All(ctx)
+}
+
+// lockBlob은 blob 경로에 트랜잭션 단위 advisory lock을 잡습니다.
+// 객체 키 잠금(BeginObjectTx)과 함께 잡을 때는 항상 객체 키를 먼저 잠급니다.
+func lockBlob(ctx context.Context, tx *ent.Tx, storagePath string) error {
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext($1))", "blob:"+storagePath); err != nil {
+		return fmt.Errorf("lock blob: %w", err)
+	}
+	return nil
+}
+
+// acquireBlob은 in.StoragePath blob의 참조를 하나 더합니다. 행이 없으면 참조 1로 만듭니다.
+// 호출자는 같은 트랜잭션에서 LockBlob으로 잠근 뒤 백엔드 객체가 있는지 확인해 두어야 합니다.
+func (r *objectRepository) acquireBlob(ctx context.Context, tx *ent.Tx, in ObjectUpsertInput) error {
+	if err := lockBlob(ctx, tx, in.StoragePath); err != nil {
+		return err
+	}
+	n, err := tx.Blob.
+		Update().
+		Where(blob.StoragePathEQ(in.StoragePath)).
+		AddRefCount(1).
+		Save(ctx)
+	if err != nil || n > 0 {
+		return err
+	}
+	return tx.Blob.
+		Create().
+		SetSha256(in.BlobSHA256).
+		SetStoragePath(in.StoragePath).
+		SetSize(in.Size).
+		SetRefCount(1).
+		Exec(ctx)
+}
+
+// releaseBlob은 storagePath가 blob이면 참조를 하나 뺍니다. blob이 아닌 경로면 아무것도 하지 않습니다.
+func (r *objectRepository) releaseBlob(ctx context.Context, tx *ent.Tx, storagePath string) error {
+	return tx.Blob.
+		Update().
+		Where(
+			blob.StoragePathEQ(storagePath),
+			blob.RefCountGT(0),
+		).
+		AddRefCount(-1).
+		Exec(ctx)
+}
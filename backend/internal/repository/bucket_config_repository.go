@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+
+	"guiio/backend/ent"
+	"guiio/backend/ent/bucketconfig"
+)
+
+// BucketConfigRepository는 버전 관리 외의 bucket_configs 설정을 다룹니다.
+type BucketConfigRepository interface {
+	// GetBucketContentTypePolicy는 설정 행이 없으면 빈 문자열을 반환합니다.
+	GetBucketContentTypePolicy(ctx context.Context, bucketName string) (string, error)
+	SetBucketContentTypePolicy(ctx context.Context, bucketName, policy string) error
+	// GetBucketContentAddressable은 설정 행이 없으면 false를 반환합니다.
+	GetBucketContentAddressable(ctx context.Context, bucketName string) (bool, error)
+	SetBucketContentAddressable(ctx context.Context, bucketName string, enabled bool) error
+	// GetBucketObjectLock은 설정 행이 없으면 꺼진 설정을 반환합니다.
+	GetBucketObjectLock(ctx context.Context, bucketName string) (BucketObjectLock, error)
+	SetBucketObjectLock(ctx context.Context, bucketName string, lock BucketObjectLock) error
+}
+
+// BucketObjectLock은 버킷의 Object Lock 설정입니다. DefaultRetentionMode가 비어 있으면 기본 보존이 없습니다.
+type BucketObjectLock struct {
+	Enabled              bool
+	DefaultRetentionMode string
+	DefaultRetentionDays int
+}
+
+func (r *objectRepository) GetBucketContentTypePolicy(ctx context.Context, bucketName string) (string, error) {
+	cfg, err := r.db.BucketConfig.
+		Query().
+		Where(bucketconfig.BucketNameEQ(bucketName)).
+		Only(ctx)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return cfg.ContentTypePolicy, nil
+}
+
+func (r *objectRepository) SetBucketContentTypePolicy(ctx context.Context, bucketName, policy string) error {
+	n, err := r.db.BucketConfig.
+		Update().
+		Where(bucketconfig.BucketNameEQ(bucketName)).
+		SetContentTypePolicy(policy).
+		Save(ctx)
+	if err != nil || n > 0 {
+		return err
+	}
+
+	err = r.db.BucketConfig.
+		Create().
+		SetBucketName(bucketName).
+		SetContentTypePolicy(policy).
+		Exec(ctx)
+	if ent.IsConstraintError(err) {
+		return r.SetBucketContentTypePolicy(ctx, bucketName, policy)
+	}
+	return err
+}
+
+func (r *objectRepository) GetBucketContentAddressable(ctx context.Context, bucketName string) (bool, error) {
+	cfg, err := r.db.BucketConfig.
+		Query().
+		Where(bucketconfig.BucketNameEQ(bucketName)).
+		Only(ctx)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return cfg.ContentAddressable, nil
+}
+
+func (r *objectRepository) SetBucketContentAddressable(ctx context.Context, bucketName string, enabled bool) error {
+	n, err := r.db.BucketConfig.
+		Update().
+		Where(bucketconfig.BucketNameEQ(bucketName)).
+		SetContentAddressable(enabled).
+		Save(ctx)
+	if err != nil || n > 0 {
+		return err
+	}
+
+	err = r.db.BucketConfig.
+		Create().
+		SetBucketName(bucketName).
+		SetContentAddressable(enabled).
+		Exec(ctx)
+	if ent.IsConstraintError(err) {
+		return r.SetBucketContentAddressable(ctx, bucketName, enabled)
+	}
+	return err
+}
+
+func (r *objectRepository) GetBucketObjectLock(ctx context.Context, bucketName string) (BucketObjectLock, error) {
+	cfg, err := r.db.BucketConfig.
+		Query().
+		Where(bucketconfig.BucketNameEQ(bucketName)).
+		Only(ctx)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return BucketObjectLock{}, nil
+		}
+		return BucketObjectLock{}, err
+	}
+	return BucketObjectLock{
+		Enabled:              cfg.ObjectLockEnabled,
+		DefaultRetentionMode: cfg.DefaultRetentionMode,
+		DefaultRetentionDays: cfg.DefaultRetentionDays,
+	}, nil
+}
+
+func (r *objectRepository) SetBucketObjectLock(ctx context.Context, bucketName string, lock BucketObjectLock) error {
+	n, err := r.db.BucketConfig.
+		Update().
+		Where(bucketconfig.BucketNameEQ(bucketName)).
+		SetObjectLockEnabled(lock.Enabled).
+		SetDefaultRetentionMode(lock.DefaultRetentionMode).
+		SetDefaultRetentionDays(lock.DefaultRetentionDays).
+		Save(ctx)
+	if err != nil || n > 0 {
+		return err
+	}
+
+	err = r.db.BucketConfig.
+		Create().
+		SetBucketName(bucketName).
+		SetObjectLockEnabled(lock.Enabled).
+		SetDefaultRetentionMode(lock.DefaultRetentionMode).
+		SetDefaultRetentionDays(lock.DefaultRetentionDays).
+		Exec(ctx)
+	if ent.IsConstraintError(err) {
+		return r.SetBucketObjectLock(ctx, bucketName, lock)
+	}
+	return err
+}
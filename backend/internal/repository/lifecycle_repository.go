@@ -0,0 +1,222 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"guiio/backend/ent"
+	"guiio/backend/ent/lifecycleevent"
+	"guiio/backend/ent/lifecyclerule"
+	"guiio/backend/ent/object"
+	"guiio/backend/ent/objecttag"
+	"guiio/backend/ent/objectversion"
+	"guiio/backend/ent/uploadsession"
+
+	"entgo.io/ent/dialect/sql"
+)
+
+// LifecycleRepository는 버킷 수명 주기 규칙과 평가 대상 조회, 평가 기록을 다룹니다.
+// 실제 삭제는 서비스가 일반 삭제 경로(deleteObject, 업로드 중단)로 처리합니다.
+type LifecycleRepository interface {
+	GetLifecycleRules(ctx context.Context, bucketName string) ([]*ent.LifecycleRule, error)
+	// PutLifecycleRules는 버킷의 규칙을 모두 rules로 대체합니다.
+	PutLifecycleRules(ctx context.Context, bucketName string, rules []LifecycleRuleInput) ([]*ent.LifecycleRule, error)
+	DeleteLifecycleRules(ctx context.Context, bucketName string) error
+	ListEnabledLifecycleRules(ctx context.Context) ([]*ent.LifecycleRule, error)
+	// ListLifecycleObjects는 휴지통에 없는 객체 중 조건에 맞는 것을 object_name 순으로 반환합니다.
+	ListLifecycleObjects(ctx context.Context, in LifecycleObjectInput) ([]*ent.Object, error)
+	// ListLifecycleVersions는 createdBefore 이전에 만든 버전을 id 순으로 반환합니다. 삭제 마커도 포함합니다.
+	ListLifecycleVersions(ctx context.Context, in LifecycleVersionInput) ([]*ent.ObjectVersion, error)
+	ListLifecycleUploadSessions(ctx context.Context, bucketName, prefix string, createdBefore time.Time, limit int) ([]*ent.UploadSession, error)
+	CreateLifecycleEvent(ctx context.Context, in LifecycleEventInput) error
+	// ListLifecycleEvents는 최신 기록부터 반환합니다. BeforeID가 0보다 크면 그보다 작은 id만 반환합니다.
+	ListLifecycleEvents(ctx context.Context, bucketName string, beforeID, limit int) ([]*ent.LifecycleEvent, error)
+}
+
+type LifecycleRuleInput struct {
+	RuleID                    string
+	Enabled                   bool
+	Prefix                    string
+	Tags                      map[string]string
+	ExpirationDays            int
+	ExpirationDate            *time.Time
+	NoncurrentExpirationDays  int
+	AbortIncompleteUploadDays int
+}
+
+// LifecycleObjectInput은 object_name 기준 keyset 페이지네이션 조건입니다.
+// ModifiedBefore 이전에 마지막으로 바뀐 객체만 반환합니다.
+type LifecycleObjectInput struct {
+	BucketName     string
+	Prefix         string
+	Tags           map[string]string
+	ModifiedBefore time.Time
+	StartAfter     string
+	Limit          int
+}
+
+// LifecycleVersionInput은 id 기준 keyset 페이지네이션 조건입니다.
+type LifecycleVersionInput struct {
+	BucketName    string
+	Prefix        string
+	CreatedBefore time.Time
+	AfterID       int
+	Limit         int
+}
+
+type LifecycleEventInput struct {
+	BucketName string
+	RuleID     string
+	Action     string
+	ObjectName string
+	VersionID  string
+	UploadID   string
+}
+
+func (r *objectRepository) GetLifecycleRules(ctx context.Context, bucketName string) ([]*ent.LifecycleRule, error) {
+	return r.db.LifecycleRule.
+		Query().
+		Where(lifecyclerule.BucketNameEQ(bucketName)).
+		Order(lifecyclerule.ByRuleID()).
+		All(ctx)
+}
+
+func (r *objectRepository) PutLifecycleRules(ctx context.Context, bucketName string, rules []LifecycleRuleInput) ([]*ent.LifecycleRule, error) {
+	tx, err := r.db.Tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.LifecycleRule.
+		Delete().
+		Where(lifecyclerule.BucketNameEQ(bucketName)).
+		Exec(ctx); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("clear lifecycle rules: %w", err)
+	}
+
+	bulk := make([]*ent.LifecycleRuleCreate, 0, len(rules))
+	for _, in := range rules {
+		bulk = append(bulk, tx.LifecycleRule.
+			Create().
+			SetBucketName(bucketName).
+			SetRuleID(in.RuleID).
+			SetEnabled(in.Enabled).
+			SetPrefix(in.Prefix).
+			SetTags(in.Tags).
+			SetExpirationDays(in.ExpirationDays).
+			SetNillableExpirationDate(in.ExpirationDate).
+			SetNoncurrentExpirationDays(in.NoncurrentExpirationDays).
+			SetAbortIncompleteUploadDays(in.AbortIncompleteUploadDays))
+	}
+	saved, err := tx.LifecycleRule.CreateBulk(bulk...).Save(ctx)
+	if err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("create lifecycle rules: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return saved, nil
+}
+
+func (r *objectRepository) DeleteLifecycleRules(ctx context.Context, bucketName string) error {
+	_, err := r.db.LifecycleRule.
+		Delete().
+		Where(lifecyclerule.BucketNameEQ(bucketName)).
+		Exec(ctx)
+	return err
+}
+
+func (r *objectRepository) ListEnabledLifecycleRules(ctx context.Context) ([]*ent.LifecycleRule, error) {
+	return r.db.LifecycleRule.
+		Query().
+		Where(lifecyclerule.EnabledEQ(true)).
+		Order(lifecyclerule.ByBucketName(), lifecyclerule.ByRuleID()).
+		All(ctx)
+}
+
+func (r *objectRepository) ListLifecycleObjects(ctx context.Context, in LifecycleObjectInput) ([]*ent.Object, error) {
+	q := r.db.Object.
+		Query().
+		Where(
+			object.BucketNameEQ(in.BucketName),
+			object.ObjectNameGT(in.StartAfter),
+			object.UpdatedAtLT(in.ModifiedBefore),
+			object.DeletedAtIsNil(),
+		)
+	if in.Prefix != "" {
+		q = q.Where(object.ObjectNameHasPrefix(in.Prefix))
+	}
+	for k, v := range in.Tags {
+		q = q.Where(object.HasTagsWith(objecttag.KeyEQ(k), objecttag.ValueEQ(v)))
+	}
+
+	return q.
+		Order(object.ByObjectName()).
+		Limit(in.Limit).
+		All(ctx)
+}
+
+func (r *objectRepository) ListLifecycleVersions(ctx context.Context, in LifecycleVersionInput) ([]*ent.ObjectVersion, error) {
+	q := r.db.ObjectVersion.
+		Query().
+		Where(
+			objectversion.BucketNameEQ(in.BucketName),
+			objectversion.IDGT(in.AfterID),
+			objectversion.CreatedAtLT(in.CreatedBefore),
+		)
+	if in.Prefix != "" {
+		q = q.Where(objectversion.ObjectNameHasPrefix(in.Prefix))
+	}
+
+	return q.
+		Order(objectversion.ByID()).
+		Limit(in.Limit).
+		All(ctx)
+}
+
+func (r *objectRepository) ListLifecycleUploadSessions(ctx context.Context, bucketName, prefix string, createdBefore time.Time, limit int) ([]*ent.UploadSession, error) {
+	q := r.db.UploadSession.
+		Query().
+		Where(
+			uploadsession.BucketNameEQ(bucketName),
+			uploadsession.CreatedAtLT(createdBefore),
+		)
+	if prefix != "" {
+		q = q.Where(uploadsession.ObjectNameHasPrefix(prefix))
+	}
+
+	return q.
+		Order(uploadsession.ByCreatedAt()).
+		Limit(limit).
+		All(ctx)
+}
+
+func (r *objectRepository) CreateLifecycleEvent(ctx context.Context, in LifecycleEventInput) error {
+	return r.db.LifecycleEvent.
+		Create().
+		SetBucketName(in.BucketName).
+		SetRuleID(in.RuleID).
+		SetAction(in.Action).
+		SetObjectName(in.ObjectName).
+		SetVersionID(in.VersionID).
+		SetUploadID(in.UploadID).
+		Exec(ctx)
+}
+
+func (r *objectRepository) ListLifecycleEvents(ctx context.Context, bucketName string, beforeID, limit int) ([]*ent.LifecycleEvent, error) {
+	q := r.db.LifecycleEvent.
+		Query().
+		Where(lifecycleevent.BucketNameEQ(bucketName))
+	if beforeID > 0 {
+		q = q.Where(lifecycleevent.IDLT(beforeID))
+	}
+
+	return q.
+		Order(lifecycleevent.ByID(sql.OrderDesc())).
+		Limit(limit).
+		All(ctx)
+}
@@ -3,16 +3,31 @@ package repository
 import (
 	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	"guiio/backend/ent"
 	"guiio/backend/ent/object"
 	"guiio/backend/ent/objectmetadata"
+	"guiio/backend/ent/objecttag"
 )
 
 type ObjectRepository interface {
 	UpsertObject(ctx context.Context, in ObjectUpsertInput) (*ent.Object, error)
+	// GetObject는 살아 있는 행이 없으면 휴지통에 있는 행(DeletedAt != nil)을 반환합니다. 호출하는 쪽에서 걸러야 합니다.
 	GetObject(ctx context.Context, bucketName, objectName string) (*ent.Object, error)
 	DeleteObject(ctx context.Context, bucketName, objectName string) error
+	ListObjects(ctx context.Context, in ObjectListInput) (*ObjectListResult, error)
+	BeginObjectTx(ctx context.Context, bucketName, objectName string) (ObjectTx, error)
+	UploadRepository
+	TusRepository
+	VersionRepository
+	TrashRepository
+	TagRepository
+	SearchRepository
+	BucketConfigRepository
+	BlobRepository
+	LifecycleRepository
 }
 
 type ObjectUpsertInput struct {
@@ -20,9 +35,57 @@ type ObjectUpsertInput struct {
 	ObjectName  string
 	StoragePath string
 	ContentType string
-	Size        int64
-	ETag        string
-	Metadata    map[string]string
+	// ContentTypeSource는 declared, detected, default 중 하나입니다.
+	ContentTypeSource string
+	Size              int64
+	ETag              string
+	// ChecksumSHA256, ChecksumCRC32C는 base64 값이며, 비어 있으면 기존 값을 지웁니다.
+	ChecksumSHA256 string
+	ChecksumCRC32C string
+	Metadata       map[string]string
+	// BlobSHA256은 StoragePath가 content-addressable blob일 때의 16진수 SHA-256입니다.
+	// 설정하면 blob 참조를 더하므로, 호출자는 ObjectTx.LockBlob으로 잠그고 백엔드 blob이 있는지 확인한 뒤 Upsert해야 합니다.
+	BlobSHA256 string
+	// RetentionMode, RetainUntil은 새 내용에 붙일 Object Lock 보존 설정입니다. 비어 있으면 보존하지 않습니다.
+	// 덮어쓰기는 기존 객체가 잠겨 있지 않을 때만 일어나므로 이전 값은 이어받지 않습니다.
+	RetentionMode string
+	RetainUntil   *time.Time
+}
+
+// ObjectMetadataUpdateInput은 콘텐츠를 다시 올리지 않고 바꾸는 객체 속성입니다.
+// Replace가 false이면 Set은 기존 키에 덮어쓰고 Remove 키는 지웁니다.
+// Replace가 true이면 기존 메타데이터를 모두 지우고 Set으로 대체합니다.
+// ContentType, CacheControl은 nil이 아닐 때만 바꿉니다.
+type ObjectMetadataUpdateInput struct {
+	BucketName  string
+	ObjectName  string
+	Replace     bool
+	Set         map[string]string
+	Remove      []string
+	ContentType *string
+	// ContentTypeSource는 ContentType을 바꿀 때 함께 기록합니다.
+	ContentTypeSource string
+	CacheControl      *string
+}
+
+// ObjectListInput은 object_name 기준 keyset 페이지네이션 조건입니다.
+// StartAfter보다 큰 이름부터 최대 Limit개의 항목(객체 + 공통 접두사)을 반환합니다.
+// Tags가 있으면 모든 키/값 태그를 가진 객체만 반환합니다.
+type ObjectListInput struct {
+	BucketName string
+	Prefix     string
+	Delimiter  string
+	StartAfter string
+	Limit      int
+	Tags       map[string]string
+}
+
+type ObjectListResult struct {
+	Objects        []*ent.Object
+	CommonPrefixes []string
+	// NextMarker는 마지막으로 반환된 객체 이름 또는 공통 접두사입니다.
+	NextMarker  string
+	IsTruncated bool
 }
 
 type objectRepository struct {
@@ -39,23 +102,53 @@ func (r *objectRepository) UpsertObject(ctx context.Context, in ObjectUpsertInpu
 		return nil, err
 	}
 
+	obj, err := r.upsertObject(ctx, tx, in)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return obj, nil
+}
+
+// upsertObject는 키의 살아 있는 행을 in으로 덮어쓰고, 없으면 새로 만듭니다.
+// 휴지통에 있는 행은 건드리지 않으므로 같은 키에 다시 써도 휴지통 항목과 그 본문은 그대로 남습니다.
+func (r *objectRepository) upsertObject(ctx context.Context, tx *ent.Tx, in ObjectUpsertInput) (*ent.Object, error) {
 	obj, err := tx.Object.
 		Query().
 		Where(
 			object.BucketNameEQ(in.BucketName),
 			object.ObjectNameEQ(in.ObjectName),
+			object.DeletedAtIsNil(),
 		).
 		Only(ctx)
 
 	create := false
 	if err != nil {
 		if !ent.IsNotFound(err) {
-			tx.Rollback()
 			return nil, err
 		}
 		create = true
 	}
 
+	// 가리키는 경로가 바뀌면 새 blob의 참조를 더하고 이전 blob의 참조를 뺍니다. blob이 아닌 경로는 참조 수가 없습니다.
+	if create || obj.StoragePath != in.StoragePath {
+		if in.BlobSHA256 != "" {
+			if err := r.acquireBlob(ctx, tx, in); err != nil {
+				return nil, fmt.Errorf("acquire blob: %w", err)
+			}
+		}
+		if !create {
+			if err := r.releaseBlob(ctx, tx, obj.StoragePath); err != nil {
+				return nil, fmt.Errorf("release blob: %w", err)
+			}
+		}
+	}
+
 	if create {
 		obj, err = tx.Object.
 			Create().
@@ -63,31 +156,43 @@ func (r *objectRepository) UpsertObject(ctx context.Context, in ObjectUpsertInpu
 			SetObjectName(in.ObjectName).
 			SetStoragePath(in.StoragePath).
 			SetContentType(in.ContentType).
+			SetContentTypeSource(in.ContentTypeSource).
 			SetSize(in.Size).
 			SetEtag(in.ETag).
+			SetChecksumSha256(in.ChecksumSHA256).
+			SetChecksumCrc32c(in.ChecksumCRC32C).
+			SetRetentionMode(in.RetentionMode).
+			SetNillableRetainUntil(in.RetainUntil).
 			Save(ctx)
 	} else {
-		obj, err = obj.Update().
+		update := obj.Update().
 			SetStoragePath(in.StoragePath).
 			SetContentType(in.ContentType).
+			SetContentTypeSource(in.ContentTypeSource).
 			SetSize(in.Size).
 			SetEtag(in.ETag).
-			Save(ctx)
+			SetChecksumSha256(in.ChecksumSHA256).
+			SetChecksumCrc32c(in.ChecksumCRC32C).
+			SetRetentionMode(in.RetentionMode)
+		if in.RetainUntil != nil {
+			update.SetRetainUntil(*in.RetainUntil)
+		} else {
+			update.ClearRetainUntil()
+		}
+		obj, err = update.Save(ctx)
 	}
 
 	if err != nil {
-		tx.Rollback()
 		return nil, err
 	}
 
 	if in.Metadata != nil {
 		if err := r.replaceMetadata(ctx, tx, obj.ID, in.Metadata); err != nil {
-			tx.Rollback()
 			return nil, err
 		}
 	}
 
-	if err := tx.Commit(); err != nil {
+	if err := r.refreshSearchVector(ctx, tx, obj.ID, in.ObjectName); err != nil {
 		return nil, err
 	}
 
@@ -122,15 +227,114 @@ func (r *objectRepository) replaceMetadata(ctx context.Context, tx *ent.Tx, obje
 	return nil
 }
 
+// updateObjectMetadata는 tx 안에서 obj의 메타데이터 행과 객체 속성을 바꾸고,
+// updated_at과 metadata_revision을 올린 객체를 메타데이터와 함께 반환합니다.
+func (r *objectRepository) updateObjectMetadata(ctx context.Context, tx *ent.Tx, obj *ent.Object, in ObjectMetadataUpdateInput) (*ent.Object, error) {
+	if in.Replace {
+		if err := r.replaceMetadata(ctx, tx, obj.ID, in.Set); err != nil {
+			return nil, err
+		}
+	} else if err := r.mergeMetadata(ctx, tx, obj.ID, in.Set, in.Remove); err != nil {
+		return nil, err
+	}
+
+	update := tx.Object.UpdateOneID(obj.ID).
+		SetUpdatedAt(time.Now()).
+		AddMetadataRevision(1)
+	if in.ContentType != nil {
+		update.SetContentType(*in.ContentType).SetContentTypeSource(in.ContentTypeSource)
+	}
+	if in.CacheControl != nil {
+		update.SetCacheControl(*in.CacheControl)
+	}
+	if _, err := update.Save(ctx); err != nil {
+		return nil, fmt.Errorf("update object: %w", err)
+	}
+
+	if err := r.refreshSearchVector(ctx, tx, obj.ID, obj.ObjectName); err != nil {
+		return nil, err
+	}
+
+	return tx.Object.
+		Query().
+		Where(object.ID(obj.ID)).
+		WithMetadata().
+		Only(ctx)
+}
+
+// mergeMetadata는 set의 키를 덮어쓰고 remove의 키를 지웁니다. 나머지 키는 그대로 둡니다.
+func (r *objectRepository) mergeMetadata(ctx context.Context, tx *ent.Tx, objectID int, set map[string]string, remove []string) error {
+	keys := append([]string(nil), remove...)
+	for k := range set {
+		keys = append(keys, k)
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	if _, err := tx.ObjectMetadata.
+		Delete().
+		Where(
+			objectmetadata.ObjectIDEQ(objectID),
+			objectmetadata.KeyIn(keys...),
+		).
+		Exec(ctx); err != nil {
+		return fmt.Errorf("clear metadata: %w", err)
+	}
+
+	bulk := make([]*ent.ObjectMetadataCreate, 0, len(set))
+	for k, v := range set {
+		bulk = append(bulk, tx.ObjectMetadata.
+			Create().
+			SetObjectID(objectID).
+			SetKey(k).
+			SetValue(v))
+	}
+
+	if len(bulk) == 0 {
+		return nil
+	}
+
+	if err := tx.ObjectMetadata.CreateBulk(bulk...).Exec(ctx); err != nil {
+		return fmt.Errorf("create metadata: %w", err)
+	}
+
+	return nil
+}
+
 func (r *objectRepository) GetObject(ctx context.Context, bucketName, objectName string) (*ent.Object, error) {
-	return r.db.Object.
+	rows, err := r.db.Object.
 		Query().
 		Where(
 			object.BucketNameEQ(bucketName),
 			object.ObjectNameEQ(objectName),
 		).
 		WithMetadata().
-		Only(ctx)
+		All(ctx)
+	if err != nil {
+		return nil, err
+	}
+	live, trashed := splitTrashed(rows)
+	if live != nil {
+		return live, nil
+	}
+	if trashed != nil {
+		return trashed, nil
+	}
+	return nil, &ent.NotFoundError{}
+}
+
+// splitTrashed는 한 키의 행을 살아 있는 행과 휴지통 행으로 나눕니다.
+// 키마다 살아 있는 행과 휴지통 행이 각각 하나까지 있을 수 있습니다(objects의 부분 unique 인덱스).
+func splitTrashed(rows []*ent.Object) (live, trashed *ent.Object) {
+	for _, row := range rows {
+		if row.DeletedAt == nil {
+			live = row
+		} else {
+			trashed = row
+		}
+	}
+	return live, trashed
 }
 
 func (r *objectRepository) DeleteObject(ctx context.Context, bucketName, objectName string) error {
@@ -144,6 +348,7 @@ func (r *objectRepository) DeleteObject(ctx context.Context, bucketName, objectN
 		Where(
 			object.BucketNameEQ(bucketName),
 			object.ObjectNameEQ(objectName),
+			object.DeletedAtIsNil(),
 		).
 		Only(ctx)
 	if err != nil {
@@ -151,15 +356,109 @@ func (r *objectRepository) DeleteObject(ctx context.Context, bucketName, objectN
 		return err
 	}
 
-	if _, err := tx.ObjectMetadata.Delete().Where(objectmetadata.ObjectIDEQ(obj.ID)).Exec(ctx); err != nil {
+	if err := r.deleteObject(ctx, tx, obj); err != nil {
 		tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *objectRepository) deleteObject(ctx context.Context, tx *ent.Tx, obj *ent.Object) error {
+	objectID := obj.ID
+	if _, err := tx.ObjectMetadata.Delete().Where(objectmetadata.ObjectIDEQ(objectID)).Exec(ctx); err != nil {
 		return fmt.Errorf("delete metadata: %w", err)
 	}
 
-	if err := tx.Object.DeleteOneID(obj.ID).Exec(ctx); err != nil {
-		tx.Rollback()
+	if _, err := tx.ObjectTag.Delete().Where(objecttag.ObjectIDEQ(objectID)).Exec(ctx); err != nil {
+		return fmt.Errorf("delete tags: %w", err)
+	}
+
+	if err := tx.Object.DeleteOneID(objectID).Exec(ctx); err != nil {
 		return fmt.Errorf("delete object: %w", err)
 	}
 
-	return tx.Commit()
+	if err := r.releaseBlob(ctx, tx, obj.StoragePath); err != nil {
+		return fmt.Errorf("release blob: %w", err)
+	}
+
+	return nil
+}
+
+func (r *objectRepository) ListObjects(ctx context.Context, in ObjectListInput) (*ObjectListResult, error) {
+	if in.Limit <= 0 {
+		return nil, fmt.Errorf("list limit must be positive")
+	}
+
+	res := &ObjectListResult{}
+	after := in.StartAfter
+	// 이전 페이지가 공통 접두사로 끝났다면 그 아래 객체는 다시 내려주지 않습니다.
+	skip := CommonPrefix(in.StartAfter, in.Prefix, in.Delimiter)
+	count := 0
+
+	for {
+		q := r.db.Object.
+			Query().
+			Where(
+				object.BucketNameEQ(in.BucketName),
+				object.ObjectNameGT(after),
+				object.DeletedAtIsNil(),
+			)
+		if in.Prefix != "" {
+			q = q.Where(object.ObjectNameHasPrefix(in.Prefix))
+		}
+		if skip != "" {
+			q = q.Where(object.Not(object.ObjectNameHasPrefix(skip)))
+		}
+		for k, v := range in.Tags {
+			q = q.Where(object.HasTagsWith(objecttag.KeyEQ(k), objecttag.ValueEQ(v)))
+		}
+
+		batch, err := q.
+			Order(object.ByObjectName()).
+			Limit(in.Limit + 1).
+			All(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("list objects: %w", err)
+		}
+
+		for _, obj := range batch {
+			cp := CommonPrefix(obj.ObjectName, in.Prefix, in.Delimiter)
+			if cp != "" && cp == skip {
+				continue
+			}
+			if count == in.Limit {
+				res.IsTruncated = true
+				return res, nil
+			}
+			count++
+			if cp != "" {
+				res.CommonPrefixes = append(res.CommonPrefixes, cp)
+				res.NextMarker = cp
+				skip = cp
+				continue
+			}
+			res.Objects = append(res.Objects, obj)
+			res.NextMarker = obj.ObjectName
+		}
+
+		if len(batch) <= in.Limit {
+			return res, nil
+		}
+		after = batch[len(batch)-1].ObjectName
+	}
+}
+
+// CommonPrefix는 prefix 뒤에 delimiter가 나오면 그 delimiter까지를 공통 접두사로 반환합니다.
+// 폴더로 묶이지 않는 이름이면 빈 문자열을 반환합니다.
+func CommonPrefix(name, prefix, delimiter string) string {
+	if delimiter == "" || !strings.HasPrefix(name, prefix) {
+		return ""
+	}
+	rest := strings.TrimPrefix(name, prefix)
+	idx := strings.Index(rest, delimiter)
+	if idx < 0 {
+		return ""
+	}
+	return prefix + rest[:idx+len(delimiter)]
 }
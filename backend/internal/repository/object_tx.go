@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"guiio/backend/ent"
+	"guiio/backend/ent/object"
+	"guiio/backend/ent/objectversion"
+)
+
+// ObjectTx는 한 객체 키에 대한 쓰기를 직렬화하는 트랜잭션입니다.
+// 조건부 쓰기는 Current로 현재 상태를 확인하고, 스토리지 백엔드에 쓴 뒤 같은 트랜잭션에서
+// Upsert/Delete 후 Commit합니다. 끝나기 전에 같은 키로 BeginObjectTx를 호출한 요청은 기다립니다.
+type ObjectTx interface {
+	// Current는 트랜잭션 시작 시점의 objects 행을 반환합니다. 행이 없으면 nil입니다.
+	// 살아 있는 행이 없으면 휴지통에 있는 행을 반환하므로 DeletedAt을 확인해야 합니다.
+	Current() *ent.Object
+	// Trashed는 이 키의 휴지통 행을 반환합니다. 없으면 nil입니다.
+	// 휴지통 행은 같은 키에 새로 쓴 살아 있는 행과 따로 있으며, 자기 storage_path를 가리킵니다.
+	Trashed() *ent.Object
+	// Upsert는 살아 있는 행을 덮어쓰거나 새로 만듭니다. 휴지통 행은 건드리지 않습니다.
+	Upsert(ctx context.Context, in ObjectUpsertInput) (*ent.Object, error)
+	// Delete는 Current 행을 지웁니다.
+	Delete(ctx context.Context) error
+	// DeleteTrashed는 휴지통 행을 지웁니다. 없으면 NotFoundError이고, 백엔드 본문은 호출자가 커밋한 뒤 지웁니다.
+	DeleteTrashed(ctx context.Context) error
+	// Trash는 살아 있는 행과 메타데이터는 남긴 채 deleted_at만 기록해 휴지통으로 옮깁니다.
+	// 키마다 휴지통 행은 하나이므로, 이미 있으면 먼저 DeleteTrashed로 지워야 합니다.
+	Trash(ctx context.Context) error
+	// Restore는 휴지통에 있는 행의 deleted_at을 지우고 메타데이터와 함께 반환합니다. 휴지통에 없으면 NotFoundError입니다.
+	// 살아 있는 행이 있으면 되살릴 수 없으므로 호출자가 Current로 먼저 확인합니다.
+	Restore(ctx context.Context) (*ent.Object, error)
+	// CreateVersion은 CreateObjectVersion과 같지만 이 트랜잭션 안에서 기록하므로, 잠금을 놓기 전에 버전 이력을 남길 수 있습니다.
+	CreateVersion(ctx context.Context, in ObjectVersionInput) (*ent.ObjectVersion, error)
+	// DeleteVersion은 이 키의 버전 행 id만 지웁니다. 없으면 NotFoundError이고, 백엔드 복사본은 호출자가 커밋 전에 지웁니다.
+	DeleteVersion(ctx context.Context, id int) error
+	// UpdateMetadata는 콘텐츠를 그대로 두고 메타데이터와 객체 속성만 바꿉니다. 행이 없거나 휴지통에 있으면 NotFoundError입니다.
+	UpdateMetadata(ctx context.Context, in ObjectMetadataUpdateInput) (*ent.Object, error)
+	// LockBlob은 Upsert할 blob 경로를 이 트랜잭션이 끝날 때까지 잠가 정리 작업이 지우지 못하게 합니다.
+	LockBlob(ctx context.Context, storagePath string) error
+	// SetRetention, SetLegalHold는 Object Lock 설정만 바꿉니다. 허용 여부는 호출자가 Current로 확인합니다.
+	SetRetention(ctx context.Context, mode string, retainUntil *time.Time) (*ent.Object, error)
+	SetLegalHold(ctx context.Context, hold bool) (*ent.Object, error)
+	Commit() error
+	// Rollback은 Commit 이후에 호출해도 안전하므로 defer로 걸어 둡니다.
+	Rollback() error
+}
+
+type objectTx struct {
+	r          *objectRepository
+	tx         *ent.Tx
+	bucketName string
+	objectName string
+	current    *ent.Object
+	trashed    *ent.Object
+	done       bool
+}
+
+// BeginObjectTx는 bucketName/objectName 키에 트랜잭션 단위 advisory lock을 잡습니다.
+// 행이 아직 없는 키(If-None-Match: * 생성)도 잠가야 하므로 행 잠금 대신 키 해시로 잠급니다.
+func (r *objectRepository) BeginObjectTx(ctx context.Context, bucketName, objectName string) (ObjectTx, error) {
+	tx, err := r.db.Tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, "SELECT pg_advisory_xact_lock(hashtext($1))", bucketName+"/"+objectName); err != nil {
+		tx.Rollback()
+		return nil, fmt.Errorf("lock object: %w", err)
+	}
+
+	rows, err := tx.Object.
+		Query().
+		Where(
+			object.BucketNameEQ(bucketName),
+			object.ObjectNameEQ(objectName),
+		).
+		All(ctx)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	current, trashed := splitTrashed(rows)
+	if current == nil {
+		current = trashed
+	}
+
+	return &objectTx{r: r, tx: tx, bucketName: bucketName, objectName: objectName, current: current, trashed: trashed}, nil
+}
+
+func (t *objectTx) Current() *ent.Object {
+	return t.current
+}
+
+func (t *objectTx) Trashed() *ent.Object {
+	return t.trashed
+}
+
+func (t *objectTx) Upsert(ctx context.Context, in ObjectUpsertInput) (*ent.Object, error) {
+	return t.r.upsertObject(ctx, t.tx, in)
+}
+
+func (t *objectTx) Delete(ctx context.Context) error {
+	if t.current == nil {
+		return &ent.NotFoundError{}
+	}
+	return t.r.deleteObject(ctx, t.tx, t.current)
+}
+
+func (t *objectTx) DeleteTrashed(ctx context.Context) error {
+	if t.trashed == nil {
+		return &ent.NotFoundError{}
+	}
+	return t.r.deleteObject(ctx, t.tx, t.trashed)
+}
+
+func (t *objectTx) CreateVersion(ctx context.Context, in ObjectVersionInput) (*ent.ObjectVersion, error) {
+	return createObjectVersion(ctx, t.tx, in)
+}
+
+func (t *objectTx) UpdateMetadata(ctx context.Context, in ObjectMetadataUpdateInput) (*ent.Object, error) {
+	if t.current == nil || t.current.DeletedAt != nil {
+		return nil, &ent.NotFoundError{}
+	}
+	return t.r.updateObjectMetadata(ctx, t.tx, t.current, in)
+}
+
+func (t *objectTx) DeleteVersion(ctx context.Context, id int) error {
+	n, err := t.tx.ObjectVersion.
+		Delete().
+		Where(
+			objectversion.IDEQ(id),
+			objectversion.BucketNameEQ(t.bucketName),
+			objectversion.ObjectNameEQ(t.objectName),
+		).
+		Exec(ctx)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return &ent.NotFoundError{}
+	}
+	return nil
+}
+
+func (t *objectTx) LockBlob(ctx context.Context, storagePath string) error {
+	return lockBlob(ctx, t.tx, storagePath)
+}
+
+func (t *objectTx) SetRetention(ctx context.Context, mode string, retainUntil *time.Time) (*ent.Object, error) {
+	if t.current == nil {
+		return nil, &ent.NotFoundError{}
+	}
+	update := t.tx.Object.UpdateOneID(t.current.ID).SetRetentionMode(mode)
+	if retainUntil != nil {
+		update.SetRetainUntil(*retainUntil)
+	} else {
+		update.ClearRetainUntil()
+	}
+	return update.Save(ctx)
+}
+
+func (t *objectTx) SetLegalHold(ctx context.Context, hold bool) (*ent.Object, error) {
+	if t.current == nil {
+		return nil, &ent.NotFoundError{}
+	}
+	return t.tx.Object.UpdateOneID(t.current.ID).SetLegalHold(hold).Save(ctx)
+}
+
+func (t *objectTx) Trash(ctx context.Context) error {
+	if t.current == nil || t.current.DeletedAt != nil {
+		return &ent.NotFoundError{}
+	}
+	return t.tx.Object.UpdateOneID(t.current.ID).SetDeletedAt(time.Now()).Exec(ctx)
+}
+
+func (t *objectTx) Restore(ctx context.Context) (*ent.Object, error) {
+	if t.trashed == nil {
+		return nil, &ent.NotFoundError{}
+	}
+	if err := t.tx.Object.UpdateOneID(t.trashed.ID).ClearDeletedAt().Exec(ctx); err != nil {
+		return nil, err
+	}
+	return t.tx.Object.
+		Query().
+		Where(object.IDEQ(t.trashed.ID)).
+		WithMetadata().
+		Only(ctx)
+}
+
+func (t *objectTx) Commit() error {
+	t.done = true
+	return t.tx.Commit()
+}
+
+func (t *objectTx) Rollback() error {
+	if t.done {
+		return nil
+	}
+	t.done = true
+	return t.tx.Rollback()
+}
@@ -0,0 +1,80 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"guiio/backend/ent"
+	"guiio/backend/ent/object"
+	"guiio/backend/ent/objectmetadata"
+	"guiio/backend/ent/predicate"
+
+	"entgo.io/ent/dialect/sql"
+)
+
+// searchTextConfig는 언어별 형태소 처리 없이 소문자 단어만 비교하는 Postgres 텍스트 검색 설정입니다.
+// SearchTokens와 같은 규칙이므로 서비스 계층의 하이라이트가 인덱스 매칭과 어긋나지 않습니다.
+const searchTextConfig = "simple"
+
+// SearchTokens는 문자/숫자가 아닌 글자를 경계로 나눈 소문자 단어 목록입니다.
+// "reports/2024-March_invoice.pdf"는 reports, 2024, march, invoice, pdf가 됩니다.
+func SearchTokens(s string) []string {
+	return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+}
+
+// refreshSearchVector는 객체 이름과 현재 메타데이터 값으로 objects.search_vector를 다시 계산합니다.
+func (r *objectRepository) refreshSearchVector(ctx context.Context, tx *ent.Tx, objectID int, objectName string) error {
+	meta, err := tx.ObjectMetadata.
+		Query().
+		Where(objectmetadata.ObjectIDEQ(objectID)).
+		All(ctx)
+	if err != nil {
+		return fmt.Errorf("load metadata: %w", err)
+	}
+
+	values := make([]string, 0, len(meta))
+	for _, m := range meta {
+		values = append(values, SearchTokens(m.Value)...)
+	}
+
+	if _, err := tx.ExecContext(ctx,
+		"UPDATE objects SET search_vector = "+
+			"setweight(to_tsvector('"+searchTextConfig+"', $1), 'A') || "+
+			"setweight(to_tsvector('"+searchTextConfig+"', $2), 'B') "+
+			"WHERE id = $3",
+		strings.Join(SearchTokens(objectName), " "),
+		strings.Join(values, " "),
+		objectID,
+	); err != nil {
+		return fmt.Errorf("update search vector: %w", err)
+	}
+	return nil
+}
+
+// matchesText는 search_vector가 websearch 문법("invoice 2024", "a OR b", -제외)의 query와 맞는 행입니다.
+func matchesText(query string) predicate.Object {
+	return predicate.Object(func(s *sql.Selector) {
+		s.Where(sql.P(func(b *sql.Builder) {
+			b.WriteString(s.C(object.FieldSearchVector)).
+				WriteString(" @@ websearch_to_tsquery('" + searchTextConfig + "', ").
+				Arg(query).
+				WriteByte(')')
+		}))
+	})
+}
+
+// byTextRank는 ts_rank가 높은 순으로 정렬합니다. 이름 일치(A)가 메타데이터 일치(B)보다 앞섭니다.
+// OrderExprFunc는 인자를 버리고 자리표시자를 $1부터 다시 매기므로, 앞선 WHERE 인자 뒤에 번호를 잇는 ExprFunc를 씁니다.
+func byTextRank(query string) object.OrderOption {
+	return func(s *sql.Selector) {
+		s.OrderExpr(sql.ExprFunc(func(b *sql.Builder) {
+			b.WriteString("ts_rank(" + s.C(object.FieldSearchVector) + ", websearch_to_tsquery('" + searchTextConfig + "', ").
+				Arg(query).
+				WriteString(")) DESC")
+		}))
+	}
+}
@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"guiio/backend/ent"
+	"guiio/backend/ent/object"
+	"guiio/backend/ent/objectmetadata"
+	"guiio/backend/ent/predicate"
+
+	"entgo.io/ent/dialect/sql"
+)
+
+// 검색 정렬 기준입니다. 같은 값끼리는 id 순으로 정렬해 페이지 경계를 고정합니다.
+const (
+	// SearchSortRelevance는 Query가 있을 때만 쓸 수 있고, offset으로 페이지를 넘깁니다.
+	SearchSortRelevance = "relevance"
+	SearchSortName      = "name"
+	SearchSortSize      = "size"
+	SearchSortCreated   = "created"
+	SearchSortUpdated   = "updated"
+)
+
+// SearchRepository는 objects/object_metadata 행을 조건으로 검색합니다.
+type SearchRepository interface {
+	SearchObjects(ctx context.Context, in ObjectSearchInput) (*ObjectSearchResult, error)
+}
+
+// ObjectSearchInput의 조건은 모두 AND로 묶입니다. 비어 있거나 nil인 조건은 무시합니다.
+type ObjectSearchInput struct {
+	// BucketName이 비어 있으면 모든 버킷을 검색합니다.
+	BucketName string
+	// Query는 이름 조각과 메타데이터 값에 대한 전문 검색어입니다(websearch_to_tsquery 문법).
+	Query string
+	// NameGlob은 *(임의 문자열)와 ?(한 글자)를 쓰는 객체 이름 패턴입니다.
+	NameGlob string
+	// ContentType이 "image/*"처럼 /*로 끝나면 접두사로 비교합니다.
+	ContentType    string
+	Metadata       map[string]string
+	MetadataPrefix map[string]string
+	MinSize        *int64
+	MaxSize        *int64
+	CreatedAfter   *time.Time
+	CreatedBefore  *time.Time
+	UpdatedAfter   *time.Time
+	UpdatedBefore  *time.Time
+	Sort           string
+	Desc           bool
+	// After는 이전 페이지의 ObjectSearchResult.Next입니다.
+	After *ObjectSearchCursor
+	Limit int
+}
+
+// ObjectSearchCursor는 마지막으로 반환된 객체의 정렬 키입니다. Sort에 해당하는 값과 ID만 사용합니다.
+// 관련도 정렬은 점수가 실수라 keyset 대신 Offset을 사용합니다.
+type ObjectSearchCursor struct {
+	Name   string    `json:"n,omitempty"`
+	Size   int64     `json:"s,omitempty"`
+	Time   time.Time `json:"t,omitempty"`
+	ID     int       `json:"i,omitempty"`
+	Offset int       `json:"o,omitempty"`
+}
+
+type ObjectSearchResult struct {
+	Objects []*ent.Object
+	// Next는 다음 페이지가 있을 때만 설정됩니다.
+	Next *ObjectSearchCursor
+}
+
+func (r *objectRepository) SearchObjects(ctx context.Context, in ObjectSearchInput) (*ObjectSearchResult, error) {
+	if in.Limit <= 0 {
+		return nil, fmt.Errorf("search limit must be positive")
+	}
+
+	q := r.db.Object.
+		Query().
+		Where(object.DeletedAtIsNil())
+	if in.BucketName != "" {
+		q = q.Where(object.BucketNameEQ(in.BucketName))
+	}
+	if in.Query != "" {
+		q = q.Where(matchesText(in.Query))
+	}
+	if in.NameGlob != "" {
+		// 와일드카드 앞부분은 text_pattern_ops 인덱스로 좁히고 나머지는 LIKE로 거릅니다.
+		// 와일드카드로 시작하는 패턴은 좁힐 접두사가 없으므로 다른 조건이 없으면 순차 스캔입니다.
+		if prefix := globPrefix(in.NameGlob); prefix != "" {
+			q = q.Where(object.ObjectNameHasPrefix(prefix))
+		}
+		pattern := globToLike(in.NameGlob)
+		q = q.Where(predicate.Object(func(s *sql.Selector) {
+			s.Where(sql.Like(s.C(object.FieldObjectName), pattern))
+		}))
+	}
+	if in.ContentType != "" {
+		if prefix, ok := strings.CutSuffix(in.ContentType, "*"); ok {
+			q = q.Where(object.ContentTypeHasPrefix(prefix))
+		} else {
+			q = q.Where(object.ContentTypeEQ(in.ContentType))
+		}
+	}
+	for k, v := range in.Metadata {
+		q = q.Where(object.HasMetadataWith(objectmetadata.KeyEQ(k), objectmetadata.ValueEQ(v)))
+	}
+	for k, v := range in.MetadataPrefix {
+		q = q.Where(object.HasMetadataWith(objectmetadata.KeyEQ(k), objectmetadata.ValueHasPrefix(v)))
+	}
+	if in.MinSize != nil {
+		q = q.Where(object.SizeGTE(*in.MinSize))
+	}
+	if in.MaxSize != nil {
+		q = q.Where(object.SizeLTE(*in.MaxSize))
+	}
+	if in.CreatedAfter != nil {
+		q = q.Where(object.CreatedAtGTE(*in.CreatedAfter))
+	}
+	if in.CreatedBefore != nil {
+		q = q.Where(object.CreatedAtLT(*in.CreatedBefore))
+	}
+	if in.UpdatedAfter != nil {
+		q = q.Where(object.UpdatedAtGTE(*in.UpdatedAfter))
+	}
+	if in.UpdatedBefore != nil {
+		q = q.Where(object.UpdatedAtLT(*in.UpdatedBefore))
+	}
+	if in.After != nil {
+		if in.Sort == SearchSortRelevance {
+			q = q.Offset(in.After.Offset)
+		} else {
+			q = q.Where(searchAfter(in.Sort, in.Desc, in.After))
+		}
+	}
+
+	term := sql.OrderAsc()
+	if in.Desc {
+		term = sql.OrderDesc()
+	}
+	var order object.OrderOption
+	switch in.Sort {
+	case SearchSortRelevance:
+		order = byTextRank(in.Query)
+		term = sql.OrderAsc()
+	case SearchSortSize:
+		order = object.BySize(term)
+	case SearchSortCreated:
+		order = object.ByCreatedAt(term)
+	case SearchSortUpdated:
+		order = object.ByUpdatedAt(term)
+	default:
+		order = object.ByObjectName(term)
+	}
+
+	objects, err := q.
+		Order(order, object.ByID(term)).
+		Limit(in.Limit + 1).
+		WithMetadata().
+		All(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("search objects: %w", err)
+	}
+
+	res := &ObjectSearchResult{Objects: objects}
+	if len(objects) > in.Limit {
+		res.Objects = objects[:in.Limit]
+		last := res.Objects[in.Limit-1]
+		res.Next = &ObjectSearchCursor{ID: last.ID}
+		switch in.Sort {
+		case SearchSortRelevance:
+			res.Next = &ObjectSearchCursor{Offset: in.Limit}
+			if in.After != nil {
+				res.Next.Offset += in.After.Offset
+			}
+		case SearchSortSize:
+			res.Next.Size = last.Size
+		case SearchSortCreated:
+			res.Next.Time = last.CreatedAt
+		case SearchSortUpdated:
+			res.Next.Time = last.UpdatedAt
+		default:
+			res.Next.Name = last.ObjectName
+		}
+	}
+	return res, nil
+}
+
+// searchAfter는 (정렬 키, id)가 커서보다 뒤에 오는 행만 남기는 keyset 조건입니다.
+func searchAfter(sort string, desc bool, c *ObjectSearchCursor) predicate.Object {
+	idAfter := object.IDGT(c.ID)
+	if desc {
+		idAfter = object.IDLT(c.ID)
+	}
+	switch sort {
+	case SearchSortSize:
+		after := object.SizeGT(c.Size)
+		if desc {
+			after = object.SizeLT(c.Size)
+		}
+		return object.Or(after, object.And(object.SizeEQ(c.Size), idAfter))
+	case SearchSortCreated:
+		after := object.CreatedAtGT(c.Time)
+		if desc {
+			after = object.CreatedAtLT(c.Time)
+		}
+		return object.Or(after, object.And(object.CreatedAtEQ(c.Time), idAfter))
+	case SearchSortUpdated:
+		after := object.UpdatedAtGT(c.Time)
+		if desc {
+			after = object.UpdatedAtLT(c.Time)
+		}
+		return object.Or(after, object.And(object.UpdatedAtEQ(c.Time), idAfter))
+	default:
+		after := object.ObjectNameGT(c.Name)
+		if desc {
+			after = object.ObjectNameLT(c.Name)
+		}
+		return object.Or(after, object.And(object.ObjectNameEQ(c.Name), idAfter))
+	}
+}
+
+// globPrefix는 첫 와일드카드 앞의 고정 접두사입니다.
+func globPrefix(glob string) string {
+	if i := strings.IndexAny(glob, "*?"); i >= 0 {
+		return glob[:i]
+	}
+	return glob
+}
+
+// globToLike는 glob 패턴을 LIKE 패턴으로 바꿉니다. 이름에 들어 있는 %, _, \는 이스케이프합니다.
+func globToLike(glob string) string {
+	var b strings.Builder
+	for _, r := range glob {
+		switch r {
+		case '*':
+			b.WriteByte('%')
+		case '?':
+			b.WriteByte('_')
+		case '%', '_', '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
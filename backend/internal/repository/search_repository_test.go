@@ -0,0 +1,298 @@
+package repository
+
+import (
+	"context"
+	stdsql "database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"guiio/backend/ent"
+	"guiio/backend/ent/object"
+	"guiio/backend/ent/predicate"
+
+	"entgo.io/ent/dialect"
+	"entgo.io/ent/dialect/sql"
+	_ "github.com/lib/pq"
+)
+
+// recordingDriver는 실행한 SQL과 인자를 기록하고 빈 결과를 돌려주는 Postgres 드라이버입니다.
+// 데이터베이스 없이 SearchObjects가 만드는 쿼리를 확인합니다.
+type recordingDriver struct {
+	queries []string
+	args    [][]any
+}
+
+func (d *recordingDriver) Exec(_ context.Context, query string, args, _ any) error {
+	return errors.New("unexpected exec: " + query)
+}
+
+func (d *recordingDriver) Query(_ context.Context, query string, args, v any) error {
+	d.queries = append(d.queries, query)
+	d.args = append(d.args, args.([]any))
+	v.(*sql.Rows).ColumnScanner = emptyRows{}
+	return nil
+}
+
+func (d *recordingDriver) Tx(context.Context) (dialect.Tx, error) {
+	return nil, errors.New("unexpected transaction")
+}
+
+func (d *recordingDriver) Close() error    { return nil }
+func (d *recordingDriver) Dialect() string { return dialect.Postgres }
+
+type emptyRows struct{}
+
+func (emptyRows) Close() error                               { return nil }
+func (emptyRows) ColumnTypes() ([]*stdsql.ColumnType, error) { return nil, nil }
+func (emptyRows) Columns() ([]string, error)                 { return nil, nil }
+func (emptyRows) Err() error                                 { return nil }
+func (emptyRows) Next() bool                                 { return false }
+func (emptyRows) NextResultSet() bool                        { return false }
+func (emptyRows) Scan(...any) error                          { return driver.ErrSkip }
+
+// searchSQL은 in으로 SearchObjects를 호출했을 때 실행한 SELECT와 인자를 반환합니다.
+func searchSQL(t *testing.T, in ObjectSearchInput) (string, []any) {
+	t.Helper()
+	drv := &recordingDriver{}
+	repo := NewObjectRepository(ent.NewClient(ent.Driver(drv)))
+	if _, err := repo.SearchObjects(context.Background(), in); err != nil {
+		t.Fatal(err)
+	}
+	if len(drv.queries) != 1 {
+		t.Fatalf("expected one query, got %q", drv.queries)
+	}
+	return drv.queries[0], drv.args[0]
+}
+
+// predicateSQL은 p를 objects 조회에 적용한 WHERE 절과 인자를 반환합니다.
+func predicateSQL(p predicate.Object) (string, []any) {
+	s := sql.Dialect(dialect.Postgres).Select("*").From(sql.Table(object.Table))
+	p(s)
+	query, args := s.Query()
+	_, where, _ := strings.Cut(query, " WHERE ")
+	return where, args
+}
+
+func TestSearchAfter(t *testing.T) {
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	cursor := &ObjectSearchCursor{Name: "b.txt", Size: 42, Time: at, ID: 7}
+
+	cases := []struct {
+		sort  string
+		desc  bool
+		where string
+		args  []any
+	}{
+		{SearchSortName, false, `"objects"."object_name" > $1 OR ("objects"."object_name" = $2 AND "objects"."id" > $3)`, []any{"b.txt", "b.txt", 7}},
+		{SearchSortName, true, `"objects"."object_name" < $1 OR ("objects"."object_name" = $2 AND "objects"."id" < $3)`, []any{"b.txt", "b.txt", 7}},
+		{SearchSortSize, false, `"objects"."size" > $1 OR ("objects"."size" = $2 AND "objects"."id" > $3)`, []any{int64(42), int64(42), 7}},
+		{SearchSortSize, true, `"objects"."size" < $1 OR ("objects"."size" = $2 AND "objects"."id" < $3)`, []any{int64(42), int64(42), 7}},
+		{SearchSortCreated, false, `"objects"."created_at" > $1 OR ("objects"."created_at" = $2 AND "objects"."id" > $3)`, []any{at, at, 7}},
+		{SearchSortUpdated, true, `"objects"."updated_at" < $1 OR ("objects"."updated_at" = $2 AND "objects"."id" < $3)`, []any{at, at, 7}},
+	}
+	for _, tc := range cases {
+		where, args := predicateSQL(searchAfter(tc.sort, tc.desc, cursor))
+		if where != tc.where {
+			t.Errorf("%s desc=%v: unexpected predicate\n got: %s\nwant: %s", tc.sort, tc.desc, where, tc.where)
+		}
+		if !reflect.DeepEqual(args, tc.args) {
+			t.Errorf("%s desc=%v: unexpected args %v", tc.sort, tc.desc, args)
+		}
+	}
+}
+
+func TestSearchObjectsKeysetQuery(t *testing.T) {
+	query, args := searchSQL(t, ObjectSearchInput{
+		BucketName: "docs",
+		Sort:       SearchSortSize,
+		Desc:       true,
+		After:      &ObjectSearchCursor{Size: 42, ID: 7},
+		Limit:      10,
+	})
+
+	// 커서 조건과 정렬 키가 같은 방향이어야 페이지가 겹치거나 빠지지 않습니다.
+	for _, want := range []string{
+		`"objects"."deleted_at" IS NULL`,
+		`"objects"."bucket_name" = $1`,
+		`("objects"."size" < $2 OR ("objects"."size" = $3 AND "objects"."id" < $4))`,
+		`ORDER BY "objects"."size" DESC, "objects"."id" DESC`,
+		`LIMIT 11`,
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("query is missing %s\n%s", want, query)
+		}
+	}
+	if !reflect.DeepEqual(args, []any{"docs", int64(42), int64(42), 7}) {
+		t.Errorf("unexpected args %v", args)
+	}
+}
+
+func TestSearchObjectsRelevanceQuery(t *testing.T) {
+	query, args := searchSQL(t, ObjectSearchInput{
+		BucketName: "docs",
+		Query:      "invoice -draft",
+		Sort:       SearchSortRelevance,
+		Desc:       true,
+		After:      &ObjectSearchCursor{Offset: 20},
+		Limit:      10,
+	})
+
+	// 정렬식의 검색어 인자는 WHERE 인자 뒤에 이어 붙습니다.
+	// 관련도 정렬은 keyset 조건 없이 offset으로 넘기고, id는 방향과 관계없이 오름차순입니다.
+	for _, want := range []string{
+		`"objects"."bucket_name" = $1`,
+		`"objects"."search_vector" @@ websearch_to_tsquery('simple', $2)`,
+		`ORDER BY ts_rank("objects"."search_vector", websearch_to_tsquery('simple', $3)) DESC, "objects"."id"`,
+		`LIMIT 11 OFFSET 20`,
+	} {
+		if !strings.Contains(query, want) {
+			t.Errorf("query is missing %s\n%s", want, query)
+		}
+	}
+	if strings.Contains(query, `"objects"."id" >`) || strings.Contains(query, `"objects"."id" DESC`) {
+		t.Errorf("relevance query must not use a keyset on id\n%s", query)
+	}
+	if !reflect.DeepEqual(args, []any{"docs", "invoice -draft", "invoice -draft"}) {
+		t.Errorf("unexpected args %v", args)
+	}
+}
+
+func TestSearchObjectsNameGlobQuery(t *testing.T) {
+	query, args := searchSQL(t, ObjectSearchInput{
+		NameGlob: `reports/50%_off\*.pdf`,
+		Limit:    10,
+	})
+
+	// 와일드카드 앞 접두사는 그대로 LIKE 접두사 조건이 되고, 이름의 %, _, \는 글자로 비교합니다.
+	if !strings.Contains(query, `"objects"."object_name" LIKE $1`) || !strings.Contains(query, `"objects"."object_name" LIKE $2`) {
+		t.Fatalf("query is missing the name conditions\n%s", query)
+	}
+	want := []any{`reports/50\%\_off\\%`, `reports/50\%\_off\\%.pdf`}
+	if !reflect.DeepEqual(args, want) {
+		t.Errorf("unexpected args %q, want %q", args, want)
+	}
+}
+
+func TestGlobToLike(t *testing.T) {
+	cases := []struct {
+		glob, like, prefix string
+	}{
+		{"*.pdf", "%.pdf", ""},
+		{"reports/2024-??.csv", "reports/2024-__.csv", "reports/2024-"},
+		{"100%_done", `100\%\_done`, "100%_done"},
+		{`dir\name*`, `dir\\name%`, `dir\name`},
+	}
+	for _, tc := range cases {
+		if got := globToLike(tc.glob); got != tc.like {
+			t.Errorf("globToLike(%q) = %q, want %q", tc.glob, got, tc.like)
+		}
+		if got := globPrefix(tc.glob); got != tc.prefix {
+			t.Errorf("globPrefix(%q) = %q, want %q", tc.glob, got, tc.prefix)
+		}
+	}
+}
+
+// TestSearchObjectsPostgres는 GUIIO_TEST_DB_DSN의 Postgres에 스키마를 만들고 실제 검색 결과를 확인합니다.
+// 실행마다 새 버킷에 행을 남기므로 테스트 전용 데이터베이스를 지정해야 하며, 지정하지 않으면 건너뜁니다.
+func TestSearchObjectsPostgres(t *testing.T) {
+	dsn := os.Getenv("GUIIO_TEST_DB_DSN")
+	if dsn == "" {
+		t.Skip("GUIIO_TEST_DB_DSN is not set")
+	}
+	ctx := context.Background()
+	client, err := ent.Open(dialect.Postgres, dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	if err := client.Schema.Create(ctx); err != nil {
+		t.Fatal(err)
+	}
+	bucket := fmt.Sprintf("search-test-%d", time.Now().UnixNano())
+	repo := NewObjectRepository(client)
+
+	objects := []struct {
+		name     string
+		size     int64
+		metadata map[string]string
+	}{
+		{"reports/2024-march_invoice.pdf", 30, map[string]string{"customer": "acme"}},
+		{"reports/2024-april_invoice.pdf", 10, map[string]string{"customer": "globex"}},
+		{"reports/50%_off.pdf", 20, nil},
+		{"reports/50x_off.pdf", 20, nil},
+		{"notes/invoice-draft.txt", 40, map[string]string{"stage": "draft"}},
+		{"notes/todo.txt", 50, map[string]string{"customer": "invoice"}},
+	}
+	for _, o := range objects {
+		if _, err := repo.UpsertObject(ctx, ObjectUpsertInput{
+			BucketName:  bucket,
+			ObjectName:  o.name,
+			StoragePath: o.name,
+			Size:        o.size,
+			Metadata:    o.metadata,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	names := func(in ObjectSearchInput) []string {
+		t.Helper()
+		in.BucketName = bucket
+		res, err := repo.SearchObjects(ctx, in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		out := make([]string, 0, len(res.Objects))
+		for _, o := range res.Objects {
+			out = append(out, o.ObjectName)
+		}
+		return out
+	}
+
+	// 이름 일치(A)가 메타데이터 값 일치(B)보다 앞서고, -는 제외 조건입니다.
+	got := names(ObjectSearchInput{Query: "invoice -april", Sort: SearchSortRelevance, Limit: 10})
+	if len(got) != 3 || got[2] != "notes/todo.txt" {
+		t.Errorf("unexpected relevance order %v", got)
+	}
+	if got := names(ObjectSearchInput{Query: "invoice draft", Limit: 10}); !reflect.DeepEqual(got, []string{"notes/invoice-draft.txt"}) {
+		t.Errorf("unexpected AND match %v", got)
+	}
+
+	// %와 _는 와일드카드가 아니라 글자로 비교합니다.
+	if got := names(ObjectSearchInput{NameGlob: "reports/50%_*", Limit: 10}); !reflect.DeepEqual(got, []string{"reports/50%_off.pdf"}) {
+		t.Errorf("unexpected glob match %v", got)
+	}
+
+	// 크기가 같은 객체가 페이지 경계에 걸려도 keyset 커서로 빠짐없이 한 번씩 돌려줍니다.
+	var paged []string
+	in := ObjectSearchInput{BucketName: bucket, Sort: SearchSortSize, Desc: true, Limit: 2}
+	for {
+		res, err := repo.SearchObjects(ctx, in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, o := range res.Objects {
+			paged = append(paged, o.ObjectName)
+		}
+		if res.Next == nil {
+			break
+		}
+		in.After = res.Next
+	}
+	if len(paged) != len(objects) || paged[0] != "notes/todo.txt" || paged[len(paged)-1] != "reports/2024-april_invoice.pdf" {
+		t.Errorf("unexpected pages %v", paged)
+	}
+	seen := map[string]bool{}
+	for _, name := range paged {
+		if seen[name] {
+			t.Errorf("%s returned twice", name)
+		}
+		seen[name] = true
+	}
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"guiio/backend/ent"
+	"guiio/backend/ent/object"
+	"guiio/backend/ent/objecttag"
+)
+
+// TagRepository는 객체 태그를 관리합니다. 휴지통에 있는 객체는 찾을 수 없는 것으로 봅니다.
+type TagRepository interface {
+	GetObjectTags(ctx context.Context, bucketName, objectName string) (map[string]string, error)
+	// PutObjectTags는 기존 태그를 모두 tags로 대체합니다. 비어 있으면 태그를 모두 지웁니다.
+	PutObjectTags(ctx context.Context, bucketName, objectName string, tags map[string]string) error
+}
+
+func (r *objectRepository) GetObjectTags(ctx context.Context, bucketName, objectName string) (map[string]string, error) {
+	obj, err := r.db.Object.
+		Query().
+		Where(
+			object.BucketNameEQ(bucketName),
+			object.ObjectNameEQ(objectName),
+			object.DeletedAtIsNil(),
+		).
+		WithTags().
+		Only(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make(map[string]string, len(obj.Edges.Tags))
+	for _, t := range obj.Edges.Tags {
+		tags[t.Key] = t.Value
+	}
+	return tags, nil
+}
+
+func (r *objectRepository) PutObjectTags(ctx context.Context, bucketName, objectName string, tags map[string]string) error {
+	tx, err := r.db.Tx(ctx)
+	if err != nil {
+		return err
+	}
+
+	obj, err := tx.Object.
+		Query().
+		Where(
+			object.BucketNameEQ(bucketName),
+			object.ObjectNameEQ(objectName),
+			object.DeletedAtIsNil(),
+		).
+		Only(ctx)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if _, err := tx.ObjectTag.
+		Delete().
+		Where(objecttag.ObjectIDEQ(obj.ID)).
+		Exec(ctx); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("clear tags: %w", err)
+	}
+
+	bulk := make([]*ent.ObjectTagCreate, 0, len(tags))
+	for k, v := range tags {
+		bulk = append(bulk, tx.ObjectTag.
+			Create().
+			SetObjectID(obj.ID).
+			SetKey(k).
+			SetValue(v))
+	}
+	if len(bulk) > 0 {
+		if err := tx.ObjectTag.CreateBulk(bulk...).Exec(ctx); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("create tags: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"guiio/backend/ent"
+	"guiio/backend/ent/object"
+)
+
+// TrashRepository는 휴지통으로 옮겨진(deleted_at이 설정된) objects 행을 조회합니다.
+// 되살리기와 영구 삭제는 같은 키의 업로드와 겹치지 않도록 ObjectTx의 Restore/DeleteTrashed로 처리합니다.
+// 키마다 휴지통 행은 하나이므로 object_name만으로 페이지를 나눌 수 있습니다.
+type TrashRepository interface {
+	ListTrashedObjects(ctx context.Context, in TrashListInput) ([]*ent.Object, error)
+	ListExpiredTrash(ctx context.Context, deletedBefore time.Time, limit int) ([]*ent.Object, error)
+}
+
+// TrashListInput은 object_name 기준 keyset 페이지네이션 조건입니다.
+type TrashListInput struct {
+	BucketName string
+	Prefix     string
+	StartAfter string
+	Limit      int
+}
+
+func (r *objectRepository) ListTrashedObjects(ctx context.Context, in TrashListInput) ([]*ent.Object, error) {
+	q := r.db.Object.
+		Query().
+		Where(
+			object.BucketNameEQ(in.BucketName),
+			object.ObjectNameGT(in.StartAfter),
+			object.DeletedAtNotNil(),
+		)
+	if in.Prefix != "" {
+		q = q.Where(object.ObjectNameHasPrefix(in.Prefix))
+	}
+
+	return q.
+		Order(object.ByObjectName()).
+		Limit(in.Limit).
+		All(ctx)
+}
+
+func (r *objectRepository) ListExpiredTrash(ctx context.Context, deletedBefore time.Time, limit int) ([]*ent.Object, error) {
+	return r.db.Object.
+		Query().
+		Where(object.DeletedAtLT(deletedBefore)).
+		Order(object.ByDeletedAt()).
+		Limit(limit).
+		All(ctx)
+}
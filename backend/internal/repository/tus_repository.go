@@ -0,0 +1,72 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"guiio/backend/ent"
+	"guiio/backend/ent/tusupload"
+)
+
+// TusRepository는 tus 업로드의 오프셋과 만료 시각을 관리합니다.
+type TusRepository interface {
+	CreateTusUpload(ctx context.Context, in TusUploadInput) (*ent.TusUpload, error)
+	GetTusUpload(ctx context.Context, bucketName, uploadID string) (*ent.TusUpload, error)
+	UpdateTusUploadOffset(ctx context.Context, id int, offset int64, expiresAt time.Time) (*ent.TusUpload, error)
+	DeleteTusUpload(ctx context.Context, id int) error
+	ListExpiredTusUploads(ctx context.Context, expiredBefore time.Time, limit int) ([]*ent.TusUpload, error)
+}
+
+type TusUploadInput struct {
+	UploadID       string
+	BucketName     string
+	ObjectName     string
+	ContentType    string
+	UploadLength   int64
+	UploadMetadata string
+	ExpiresAt      time.Time
+}
+
+func (r *objectRepository) CreateTusUpload(ctx context.Context, in TusUploadInput) (*ent.TusUpload, error) {
+	return r.db.TusUpload.
+		Create().
+		SetUploadID(in.UploadID).
+		SetBucketName(in.BucketName).
+		SetObjectName(in.ObjectName).
+		SetContentType(in.ContentType).
+		SetUploadLength(in.UploadLength).
+		SetUploadMetadata(in.UploadMetadata).
+		SetExpiresAt(in.ExpiresAt).
+		Save(ctx)
+}
+
+func (r *objectRepository) GetTusUpload(ctx context.Context, bucketName, uploadID string) (*ent.TusUpload, error) {
+	return r.db.TusUpload.
+		Query().
+		Where(
+			tusupload.BucketNameEQ(bucketName),
+			tusupload.UploadIDEQ(uploadID),
+		).
+		Only(ctx)
+}
+
+func (r *objectRepository) UpdateTusUploadOffset(ctx context.Context, id int, offset int64, expiresAt time.Time) (*ent.TusUpload, error) {
+	return r.db.TusUpload.
+		UpdateOneID(id).
+		SetUploadOffset(offset).
+		SetExpiresAt(expiresAt).
+		Save(ctx)
+}
+
+func (r *objectRepository) DeleteTusUpload(ctx context.Context, id int) error {
+	return r.db.TusUpload.DeleteOneID(id).Exec(ctx)
+}
+
+func (r *objectRepository) ListExpiredTusUploads(ctx context.Context, expiredBefore time.Time, limit int) ([]*ent.TusUpload, error) {
+	return r.db.TusUpload.
+		Query().
+		Where(tusupload.ExpiresAtLT(expiredBefore)).
+		Order(tusupload.ByExpiresAt()).
+		Limit(limit).
+		All(ctx)
+}
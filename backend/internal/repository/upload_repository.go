@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"guiio/backend/ent"
+	"guiio/backend/ent/uploadpart"
+	"guiio/backend/ent/uploadsession"
+)
+
+// UploadRepository는 멀티파트 업로드 세션과 파트 상태를 관리합니다.
+type UploadRepository interface {
+	CreateUploadSession(ctx context.Context, in UploadSessionInput) (*ent.UploadSession, error)
+	GetUploadSession(ctx context.Context, bucketName, uploadID string) (*ent.UploadSession, error)
+	UpsertUploadPart(ctx context.Context, in UploadPartInput) (*ent.UploadPart, error)
+	ListUploadParts(ctx context.Context, sessionID int) ([]*ent.UploadPart, error)
+	DeleteUploadSession(ctx context.Context, sessionID int) error
+	// ListExpiredUploadSessions는 마지막 활동(시작 또는 파트 업로드)이 idleSince보다 오래된 세션을 반환합니다.
+	ListExpiredUploadSessions(ctx context.Context, idleSince time.Time, limit int) ([]*ent.UploadSession, error)
+}
+
+type UploadSessionInput struct {
+	UploadID        string
+	BucketName      string
+	ObjectName      string
+	StoragePath     string
+	BackendUploadID string
+	ContentType     string
+	Metadata        map[string]string
+}
+
+type UploadPartInput struct {
+	SessionID  int
+	PartNumber int
+	ETag       string
+	Size       int64
+}
+
+func (r *objectRepository) CreateUploadSession(ctx context.Context, in UploadSessionInput) (*ent.UploadSession, error) {
+	return r.db.UploadSession.
+		Create().
+		SetUploadID(in.UploadID).
+		SetBucketName(in.BucketName).
+		SetObjectName(in.ObjectName).
+		SetStoragePath(in.StoragePath).
+		SetBackendUploadID(in.BackendUploadID).
+		SetContentType(in.ContentType).
+		SetMetadata(in.Metadata).
+		Save(ctx)
+}
+
+func (r *objectRepository) GetUploadSession(ctx context.Context, bucketName, uploadID string) (*ent.UploadSession, error) {
+	return r.db.UploadSession.
+		Query().
+		Where(
+			uploadsession.BucketNameEQ(bucketName),
+			uploadsession.UploadIDEQ(uploadID),
+		).
+		Only(ctx)
+}
+
+// UpsertUploadPart는 같은 파트 번호가 다시 올라오면 기존 행을 덮어씁니다.
+// 동시에 같은 파트를 재시도해 unique 제약에 걸리면 갱신으로 한 번 더 시도합니다.
+// 세션의 updated_at도 갱신해, 오래 걸리지만 계속 파트를 올리는 업로드는 정리하지 않습니다.
+func (r *objectRepository) UpsertUploadPart(ctx context.Context, in UploadPartInput) (*ent.UploadPart, error) {
+	part, err := r.saveUploadPart(ctx, in)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.db.UploadSession.UpdateOneID(in.SessionID).SetUpdatedAt(time.Now()).Exec(ctx); err != nil {
+		return nil, fmt.Errorf("touch upload session: %w", err)
+	}
+	return part, nil
+}
+
+func (r *objectRepository) saveUploadPart(ctx context.Context, in UploadPartInput) (*ent.UploadPart, error) {
+	part, err := r.updateUploadPart(ctx, in)
+	if err == nil || !ent.IsNotFound(err) {
+		return part, err
+	}
+
+	part, err = r.db.UploadPart.
+		Create().
+		SetSessionID(in.SessionID).
+		SetPartNumber(in.PartNumber).
+		SetEtag(in.ETag).
+		SetSize(in.Size).
+		Save(ctx)
+	if ent.IsConstraintError(err) {
+		return r.updateUploadPart(ctx, in)
+	}
+	return part, err
+}
+
+func (r *objectRepository) updateUploadPart(ctx context.Context, in UploadPartInput) (*ent.UploadPart, error) {
+	part, err := r.db.UploadPart.
+		Query().
+		Where(
+			uploadpart.SessionIDEQ(in.SessionID),
+			uploadpart.PartNumberEQ(in.PartNumber),
+		).
+		Only(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return part.Update().
+		SetEtag(in.ETag).
+		SetSize(in.Size).
+		Save(ctx)
+}
+
+func (r *objectRepository) ListUploadParts(ctx context.Context, sessionID int) ([]*ent.UploadPart, error) {
+	return r.db.UploadPart.
+		Query().
+		Where(uploadpart.SessionIDEQ(sessionID)).
+		Order(uploadpart.ByPartNumber()).
+		All(ctx)
+}
+
+func (r *objectRepository) DeleteUploadSession(ctx context.Context, sessionID int) error {
+	tx, err := r.db.Tx(ctx)
+	if err != nil {
+		return err
+	}
+
+	if _, err := tx.UploadPart.Delete().Where(uploadpart.SessionIDEQ(sessionID)).Exec(ctx); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("delete upload parts: %w", err)
+	}
+
+	if err := tx.UploadSession.DeleteOneID(sessionID).Exec(ctx); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("delete upload session: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+func (r *objectRepository) ListExpiredUploadSessions(ctx context.Context, idleSince time.Time, limit int) ([]*ent.UploadSession, error) {
+	return r.db.UploadSession.
+		Query().
+		Where(uploadsession.UpdatedAtLT(idleSince)).
+		Order(uploadsession.ByUpdatedAt()).
+		Limit(limit).
+		All(ctx)
+}
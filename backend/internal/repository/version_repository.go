@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"guiio/backend/ent"
+	"guiio/backend/ent/bucketconfig"
+	"guiio/backend/ent/objectversion"
+
+	"entgo.io/ent/dialect/sql"
+)
+
+// VersionRepository는 버킷 버전 관리 상태와 객체 버전 이력을 관리합니다.
+type VersionRepository interface {
+	GetBucketVersioning(ctx context.Context, bucketName string) (string, error)
+	SetBucketVersioning(ctx context.Context, bucketName, status string) error
+	CreateObjectVersion(ctx context.Context, in ObjectVersionInput) (*ent.ObjectVersion, error)
+	GetObjectVersion(ctx context.Context, bucketName, objectName, versionID string) (*ent.ObjectVersion, error)
+	ListObjectVersions(ctx context.Context, bucketName, objectName string) ([]*ent.ObjectVersion, error)
+}
+
+type ObjectVersionInput struct {
+	VersionID   string
+	BucketName  string
+	ObjectName  string
+	StoragePath string
+	ContentType string
+	// ContentTypeSource는 ObjectUpsertInput.ContentTypeSource와 같은 값입니다.
+	ContentTypeSource string
+	Size              int64
+	ETag              string
+	ChecksumSHA256    string
+	ChecksumCRC32C    string
+	IsDeleteMarker    bool
+	Metadata          map[string]string
+}
+
+// GetBucketVersioning은 설정 행이 없으면 빈 문자열(버전 관리 미사용)을 반환합니다.
+func (r *objectRepository) GetBucketVersioning(ctx context.Context, bucketName string) (string, error) {
+	cfg, err := r.db.BucketConfig.
+		Query().
+		Where(bucketconfig.BucketNameEQ(bucketName)).
+		Only(ctx)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	return cfg.Versioning, nil
+}
+
+func (r *objectRepository) SetBucketVersioning(ctx context.Context, bucketName, status string) error {
+	n, err := r.db.BucketConfig.
+		Update().
+		Where(bucketconfig.BucketNameEQ(bucketName)).
+		SetVersioning(status).
+		Save(ctx)
+	if err != nil || n > 0 {
+		return err
+	}
+
+	err = r.db.BucketConfig.
+		Create().
+		SetBucketName(bucketName).
+		SetVersioning(status).
+		Exec(ctx)
+	if ent.IsConstraintError(err) {
+		return r.SetBucketVersioning(ctx, bucketName, status)
+	}
+	return err
+}
+
+// CreateObjectVersion은 같은 version_id 행이 있으면 대체합니다.
+// 버전 관리가 일시 중지된 버킷의 "null" 버전은 항상 하나만 남습니다.
+func (r *objectRepository) CreateObjectVersion(ctx context.Context, in ObjectVersionInput) (*ent.ObjectVersion, error) {
+	tx, err := r.db.Tx(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	v, err := createObjectVersion(ctx, tx, in)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func createObjectVersion(ctx context.Context, tx *ent.Tx, in ObjectVersionInput) (*ent.ObjectVersion, error) {
+	if _, err := tx.ObjectVersion.
+		Delete().
+		Where(
+			objectversion.BucketNameEQ(in.BucketName),
+			objectversion.ObjectNameEQ(in.ObjectName),
+			objectversion.VersionIDEQ(in.VersionID),
+		).
+		Exec(ctx); err != nil {
+		return nil, fmt.Errorf("replace version: %w", err)
+	}
+
+	return tx.ObjectVersion.
+		Create().
+		SetVersionID(in.VersionID).
+		SetBucketName(in.BucketName).
+		SetObjectName(in.ObjectName).
+		SetStoragePath(in.StoragePath).
+		SetContentType(in.ContentType).
+		SetContentTypeSource(in.ContentTypeSource).
+		SetSize(in.Size).
+		SetEtag(in.ETag).
+		SetChecksumSha256(in.ChecksumSHA256).
+		SetChecksumCrc32c(in.ChecksumCRC32C).
+		SetIsDeleteMarker(in.IsDeleteMarker).
+		SetMetadata(in.Metadata).
+		Save(ctx)
+}
+
+func (r *objectRepository) GetObjectVersion(ctx context.Context, bucketName, objectName, versionID string) (*ent.ObjectVersion, error) {
+	return r.db.ObjectVersion.
+		Query().
+		Where(
+			objectversion.BucketNameEQ(bucketName),
+			objectversion.ObjectNameEQ(objectName),
+			objectversion.VersionIDEQ(versionID),
+		).
+		Only(ctx)
+}
+
+// ListObjectVersions는 최신 버전부터 반환합니다.
+func (r *objectRepository) ListObjectVersions(ctx context.Context, bucketName, objectName string) ([]*ent.ObjectVersion, error) {
+	return r.db.ObjectVersion.
+		Query().
+		Where(
+			objectversion.BucketNameEQ(bucketName),
+			objectversion.ObjectNameEQ(objectName),
+		).
+		Order(
+			objectversion.ByCreatedAt(sql.OrderDesc()),
+			objectversion.ByID(sql.OrderDesc()),
+		).
+		All(ctx)
+}
@@ -0,0 +1,229 @@
+package service
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+
+	"guiio/backend/ent"
+	httpctx "guiio/backend/internal/port/httpctx"
+	"guiio/backend/internal/repository"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/sphynx/config"
+)
+
+const (
+	archiveFormatZip   = "zip"
+	archiveFormatTarGz = "tar.gz"
+)
+
+var errArchiveTooLarge = errors.New("archive exceeds the allowed object count or size")
+
+// archiveEntry는 아카이브에 넣을 객체와 아카이브 안의 상대 경로입니다.
+type archiveEntry struct {
+	Name   string
+	Object *ent.Object
+}
+
+// DownloadArchive는 prefix로 시작하는 객체를 ZIP 또는 tar.gz로 묶어 내려줍니다.
+// 아카이브는 디스크에 만들지 않고 객체를 하나씩 읽어 응답으로 바로 씁니다.
+// 목록을 먼저 만들어 개수/크기 제한을 확인하므로 제한을 넘으면 스트림을 시작하기 전에 413을 반환합니다.
+func (s *StorageService) DownloadArchive(ctx httpctx.Context) {
+	bucketName := strings.TrimSpace(ctx.Param("bucketName"))
+	if err := validateBucketName(bucketName); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	prefix := ctx.Query("prefix")
+	format := strings.ToLower(strings.TrimSpace(ctx.Query("format")))
+	switch format {
+	case "":
+		format = archiveFormatZip
+	case archiveFormatZip, archiveFormatTarGz:
+	default:
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "format must be zip or tar.gz"})
+		return
+	}
+
+	reqCtx := ctx.Context()
+	exists, err := s.client.BucketExists(reqCtx, bucketName)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("check bucket failed: %v", err)})
+		return
+	}
+	if !exists {
+		ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "bucket not found"})
+		return
+	}
+
+	entries, err := s.collectArchiveEntries(reqCtx, bucketName, prefix)
+	if err != nil {
+		if errors.Is(err, errArchiveTooLarge) {
+			ctx.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Error: err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("list objects failed: %v", err)})
+		return
+	}
+	if len(entries) == 0 {
+		ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "no objects match prefix"})
+		return
+	}
+
+	pr, pw := io.Pipe()
+	defer pr.Close()
+
+	go func() {
+		var err error
+		if format == archiveFormatTarGz {
+			err = s.writeTarGz(reqCtx, pw, bucketName, entries)
+		} else {
+			err = s.writeZip(reqCtx, pw, bucketName, entries)
+		}
+		pw.CloseWithError(err)
+	}()
+
+	contentType := "application/zip"
+	if format == archiveFormatTarGz {
+		contentType = "application/gzip"
+	}
+	ctx.SetHeader("Content-Disposition", formatContentDisposition(dispositionAttachment, archiveFileName(bucketName, prefix)+"."+format))
+	if err := ctx.Stream(http.StatusOK, contentType, pr); err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("stream failed: %v", err)})
+	}
+}
+
+// collectArchiveEntries는 prefix 아래 객체를 이름순으로 모두 모읍니다.
+// archive_max_objects, archive_max_size(0 이하이면 제한 없음)를 넘으면 errArchiveTooLarge를 반환합니다.
+func (s *StorageService) collectArchiveEntries(ctx context.Context, bucketName, prefix string) ([]archiveEntry, error) {
+	maxObjects := config.Get[int]("archive_max_objects")
+	maxSize := config.Get[int64]("archive_max_size")
+
+	var entries []archiveEntry
+	var total int64
+	in := repository.ObjectListInput{
+		BucketName: bucketName,
+		Prefix:     prefix,
+		Limit:      maxListLimit,
+	}
+	for {
+		var result *repository.ObjectListResult
+		var err error
+		if s.repo != nil {
+			result, err = s.repo.ListObjects(ctx, in)
+		} else {
+			result, err = s.listStorageObjects(ctx, in)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for _, obj := range result.Objects {
+			name := archiveEntryName(prefix, obj.ObjectName)
+			if name == "" {
+				continue
+			}
+			total += obj.Size
+			entries = append(entries, archiveEntry{Name: name, Object: obj})
+			if (maxObjects > 0 && len(entries) > maxObjects) || (maxSize > 0 && total > maxSize) {
+				return nil, errArchiveTooLarge
+			}
+		}
+		if !result.IsTruncated {
+			return entries, nil
+		}
+		in.StartAfter = result.NextMarker
+	}
+}
+
+// writeZip은 항목을 Deflate로 압축해 씁니다.
+// archive/zip은 항목 크기나 오프셋이 4GiB, 항목 수가 65535를 넘으면 ZIP64 레코드를 자동으로 씁니다.
+func (s *StorageService) writeZip(ctx context.Context, w io.Writer, bucketName string, entries []archiveEntry) error {
+	zw := zip.NewWriter(w)
+	for _, e := range entries {
+		fw, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     e.Name,
+			Method:   zip.Deflate,
+			Modified: e.Object.UpdatedAt,
+		})
+		if err != nil {
+			return err
+		}
+		if err := s.copyArchiveObject(ctx, fw, bucketName, e.Object); err != nil {
+			return err
+		}
+	}
+	return zw.Close()
+}
+
+// writeTarGz는 목록에서 얻은 크기로 tar 헤더를 쓰므로 그 사이 객체 크기가 바뀌면 실패합니다.
+func (s *StorageService) writeTarGz(ctx context.Context, w io.Writer, bucketName string, entries []archiveEntry) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	for _, e := range entries {
+		if err := tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     e.Name,
+			Mode:     0o644,
+			Size:     e.Object.Size,
+			ModTime:  e.Object.UpdatedAt,
+		}); err != nil {
+			return err
+		}
+		if err := s.copyArchiveObject(ctx, tw, bucketName, e.Object); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+func (s *StorageService) copyArchiveObject(ctx context.Context, w io.Writer, bucketName string, obj *ent.Object) error {
+	storageKey := normalizeStoragePath(bucketName, obj.StoragePath, encodeObjectKey(obj.ObjectName))
+	r, err := s.client.GetObject(ctx, storageBucket(bucketName, storageKey), storageKey, minio.GetObjectOptions{})
+	if err != nil {
+		return fmt.Errorf("read %s: %w", obj.ObjectName, err)
+	}
+	defer r.Close()
+	if _, err := io.CopyN(w, r, obj.Size); err != nil {
+		return fmt.Errorf("read %s: %w", obj.ObjectName, err)
+	}
+	return nil
+}
+
+// archiveEntryName은 prefix의 마지막 '/'까지를 떼어 낸 상대 경로입니다.
+// prefix가 "photos/2024"이면 "photos/2024-01/a.jpg"는 "2024-01/a.jpg"가 됩니다.
+// 압축을 풀 때 디렉터리 밖으로 나가지 않도록 빈 조각, ".", ".."은 버리고, "/"로 끝나는 폴더 표시 객체는 건너뜁니다.
+func archiveEntryName(prefix, objectName string) string {
+	if strings.HasSuffix(objectName, "/") {
+		return ""
+	}
+	rel := strings.TrimPrefix(objectName, prefix[:strings.LastIndex(prefix, "/")+1])
+	segments := make([]string, 0, strings.Count(rel, "/")+1)
+	for _, seg := range strings.Split(rel, "/") {
+		if seg == "" || seg == "." || seg == ".." {
+			continue
+		}
+		segments = append(segments, seg)
+	}
+	return strings.Join(segments, "/")
+}
+
+// archiveFileName은 prefix의 마지막 경로 조각, prefix가 없으면 버킷 이름입니다.
+func archiveFileName(bucketName, prefix string) string {
+	if name := path.Base(strings.TrimSuffix(prefix, "/")); prefix != "" && name != "." && name != "/" {
+		return name
+	}
+	return bucketName
+}
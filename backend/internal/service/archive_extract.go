@@ -0,0 +1,263 @@
+package service
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+
+	httpctx "guiio/backend/internal/port/httpctx"
+
+	"github.com/sphynx/config"
+)
+
+const archiveFormatTar = "tar"
+
+var (
+	errUnsupportedArchive = errors.New("unsupported archive format (zip, tar, tar.gz)")
+	errUnsafeArchivePath  = errors.New("entry path escapes the target prefix")
+)
+
+type ExtractEntryResult struct {
+	Entry     string `json:"entry"`
+	Key       string `json:"key,omitempty"`
+	Size      int64  `json:"size"`
+	ETag      string `json:"etag,omitempty"`
+	Extracted bool   `json:"extracted"`
+	Error     string `json:"error,omitempty"`
+}
+
+type ExtractArchiveResponse struct {
+	Bucket    string               `json:"bucket"`
+	Prefix    string               `json:"prefix"`
+	Format    string               `json:"format"`
+	Extracted int                  `json:"extracted"`
+	Failed    int                  `json:"failed"`
+	Results   []ExtractEntryResult `json:"results"`
+}
+
+// archiveItem은 zip/tar 항목을 같은 방식으로 다루기 위한 값입니다. open은 본문이 필요할 때만 호출합니다.
+type archiveItem struct {
+	name    string
+	size    int64
+	dir     bool
+	regular bool
+	open    func() (io.ReadCloser, error)
+}
+
+// extractUpload는 업로드한 zip/tar/tar.gz의 각 항목을 prefix 아래 객체로 저장합니다.
+// 먼저 항목 헤더만 훑어 extract_max_entries, extract_max_size를 확인하므로 제한을 넘으면 아무것도 쓰지 않고 413을 반환합니다.
+// 항목은 storeObject(StorageClient.PutObject, repo.UpsertObject)로 하나씩 저장하고, 실패해도 나머지는 계속 처리합니다.
+func (s *StorageService) extractUpload(ctx httpctx.Context, bucketName string, file multipart.File, header *multipart.FileHeader, metadata map[string]string) {
+	r := ctx.Request()
+	prefix := strings.TrimLeft(strings.TrimSpace(r.FormValue("prefix")), "/")
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+
+	format, err := detectArchiveFormat(file)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	reqCtx := ctx.Context()
+	exists, err := s.client.BucketExists(reqCtx, bucketName)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("check bucket failed: %v", err)})
+		return
+	}
+	if !exists {
+		ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "bucket not found"})
+		return
+	}
+
+	maxEntries := config.Get[int]("extract_max_entries")
+	maxSize := config.Get[int64]("extract_max_size")
+	var count int
+	var total int64
+	err = walkArchive(file, header.Size, format, func(item archiveItem) error {
+		if item.dir {
+			return nil
+		}
+		count++
+		total += item.size
+		if (maxEntries > 0 && count > maxEntries) || (maxSize > 0 && total > maxSize) {
+			return errArchiveTooLarge
+		}
+		return nil
+	})
+	if err != nil {
+		if errors.Is(err, errArchiveTooLarge) {
+			ctx.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Error: err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("invalid archive: %v", err)})
+		return
+	}
+
+	resp := ExtractArchiveResponse{
+		Bucket:  bucketName,
+		Prefix:  prefix,
+		Format:  format,
+		Results: make([]ExtractEntryResult, 0, count),
+	}
+	err = walkArchive(file, header.Size, format, func(item archiveItem) error {
+		if item.dir {
+			return nil
+		}
+		result := ExtractEntryResult{Entry: item.name, Size: item.size}
+		if err := s.extractItem(ctx, bucketName, prefix, item, metadata, &result); err != nil {
+			result.Error = err.Error()
+			resp.Failed++
+		} else {
+			result.Extracted = true
+			resp.Extracted++
+		}
+		resp.Results = append(resp.Results, result)
+		return nil
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("extract failed: %v", err)})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, resp)
+}
+
+func (s *StorageService) extractItem(ctx httpctx.Context, bucketName, prefix string, item archiveItem, metadata map[string]string, result *ExtractEntryResult) error {
+	if !item.regular {
+		return errors.New("only regular files can be extracted")
+	}
+	name, err := safeArchivePath(item.name)
+	if err != nil {
+		return err
+	}
+	objectName := prefix + name
+	if err := validateObjectName(objectName); err != nil {
+		return err
+	}
+	result.Key = objectName
+
+	body, err := item.open()
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	// 항목에는 Content-Type이 없으므로 storeObject가 버킷 정책에 따라 확장자와 본문으로 정합니다.
+	resp, err := s.storeObject(ctx.Context(), putObjectInput{
+		BucketName: bucketName,
+		ObjectName: objectName,
+		Size:       item.size,
+		Metadata:   metadata,
+		Body:       body,
+	})
+	if err != nil {
+		return err
+	}
+	result.ETag = resp.ETag
+	return nil
+}
+
+// safeArchivePath는 항목 이름을 prefix 아래 상대 경로로 정리합니다.
+// 절대 경로나 ".." 조각이 있는 이름은 zip-slip을 막기 위해 거부하고, Windows 도구가 쓰는 '\'는 '/'로 바꿉니다.
+func safeArchivePath(name string) (string, error) {
+	name = strings.ReplaceAll(name, "\\", "/")
+	if strings.HasPrefix(name, "/") || (len(name) >= 2 && name[1] == ':') {
+		return "", errUnsafeArchivePath
+	}
+	segments := make([]string, 0, strings.Count(name, "/")+1)
+	for _, seg := range strings.Split(name, "/") {
+		switch seg {
+		case "..":
+			return "", errUnsafeArchivePath
+		case "", ".":
+			continue
+		}
+		segments = append(segments, seg)
+	}
+	if len(segments) == 0 {
+		return "", errUnsafeArchivePath
+	}
+	return strings.Join(segments, "/"), nil
+}
+
+// detectArchiveFormat은 파일 앞부분의 시그니처로 형식을 고릅니다. tar는 257바이트 위치의 "ustar"로 판단합니다.
+func detectArchiveFormat(file io.ReaderAt) (string, error) {
+	head := make([]byte, 262)
+	n, err := file.ReadAt(head, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	head = head[:n]
+	switch {
+	case bytes.HasPrefix(head, []byte("PK\x03\x04")), bytes.HasPrefix(head, []byte("PK\x05\x06")):
+		return archiveFormatZip, nil
+	case bytes.HasPrefix(head, []byte{0x1f, 0x8b}):
+		return archiveFormatTarGz, nil
+	case n >= 262 && bytes.HasPrefix(head[257:], []byte("ustar")):
+		return archiveFormatTar, nil
+	}
+	return "", errUnsupportedArchive
+}
+
+// walkArchive는 항목마다 fn을 호출합니다. 매번 처음부터 읽으므로 같은 파일을 여러 번 훑을 수 있습니다.
+func walkArchive(file multipart.File, size int64, format string, fn func(archiveItem) error) error {
+	if format == archiveFormatZip {
+		zr, err := zip.NewReader(file, size)
+		if err != nil {
+			return err
+		}
+		for _, f := range zr.File {
+			if err := fn(archiveItem{
+				name:    f.Name,
+				size:    int64(f.UncompressedSize64),
+				dir:     f.FileInfo().IsDir(),
+				regular: f.Mode().IsRegular(),
+				open:    f.Open,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	var src io.Reader = file
+	if format == archiveFormatTarGz {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		src = gz
+	}
+	tr := tar.NewReader(src)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(archiveItem{
+			name:    hdr.Name,
+			size:    hdr.Size,
+			dir:     hdr.Typeflag == tar.TypeDir,
+			regular: hdr.Typeflag == tar.TypeReg,
+			open:    func() (io.ReadCloser, error) { return io.NopCloser(tr), nil },
+		}); err != nil {
+			return err
+		}
+	}
+}
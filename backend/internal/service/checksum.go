@@ -0,0 +1,141 @@
+package service
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// 체크섬 헤더 값은 S3의 x-amz-checksum-*와 같이 다이제스트 바이트의 base64입니다.
+const (
+	checksumSHA256Header = "X-Guiio-Checksum-Sha256"
+	checksumCRC32CHeader = "X-Guiio-Checksum-Crc32c"
+)
+
+var (
+	errChecksumMismatch = errors.New("checksum does not match request body")
+	crc32cTable         = crc32.MakeTable(crc32.Castagnoli)
+)
+
+// expectedChecksums는 클라이언트가 보낸 Content-MD5, X-Guiio-Checksum-* 값을 디코딩한 다이제스트입니다.
+type expectedChecksums struct {
+	MD5    []byte
+	SHA256 []byte
+	CRC32C []byte
+}
+
+// checksumsFromHeader는 체크섬 헤더를 읽습니다. Content-MD5는 includeMD5일 때만 봅니다.
+// 멀티파트 폼 업로드의 Content-MD5는 파일이 아니라 요청 전체에 대한 값이라 무시합니다.
+func checksumsFromHeader(h http.Header, includeMD5 bool) (expectedChecksums, error) {
+	var exp expectedChecksums
+	var err error
+	if includeMD5 {
+		if exp.MD5, err = decodeChecksumHeader(h, "Content-MD5", md5.Size); err != nil {
+			return exp, err
+		}
+	}
+	if exp.SHA256, err = decodeChecksumHeader(h, checksumSHA256Header, sha256.Size); err != nil {
+		return exp, err
+	}
+	if exp.CRC32C, err = decodeChecksumHeader(h, checksumCRC32CHeader, crc32.Size); err != nil {
+		return exp, err
+	}
+	return exp, nil
+}
+
+func decodeChecksumHeader(h http.Header, name string, size int) ([]byte, error) {
+	raw := strings.TrimSpace(h.Get(name))
+	if raw == "" {
+		return nil, nil
+	}
+	sum, err := base64.StdEncoding.DecodeString(raw)
+	if err != nil || len(sum) != size {
+		return nil, fmt.Errorf("invalid %s header", name)
+	}
+	return sum, nil
+}
+
+// checksumReader는 본문을 흘려보내면서 SHA-256, CRC32C(요청에 있으면 MD5도)를 계산합니다.
+// 본문 끝(EOF 또는 size만큼 읽은 시점)에서 기대값과 다르면 마지막 조각을 넘기지 않고
+// errChecksumMismatch를 반환하므로 백엔드 업로드가 완료되지 않습니다.
+type checksumReader struct {
+	r        io.Reader
+	size     int64
+	read     int64
+	expected expectedChecksums
+	sha256   hash.Hash
+	crc32c   hash.Hash32
+	md5      hash.Hash
+	verified bool
+}
+
+func newChecksumReader(r io.Reader, size int64, expected expectedChecksums) *checksumReader {
+	c := &checksumReader{
+		r:        r,
+		size:     size,
+		expected: expected,
+		sha256:   sha256.New(),
+		crc32c:   crc32.New(crc32cTable),
+	}
+	if expected.MD5 != nil {
+		c.md5 = md5.New()
+	}
+	return c
+}
+
+func (c *checksumReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	if n > 0 {
+		c.read += int64(n)
+		c.sha256.Write(p[:n])
+		c.crc32c.Write(p[:n])
+		if c.md5 != nil {
+			c.md5.Write(p[:n])
+		}
+	}
+	if !c.verified && (errors.Is(err, io.EOF) || (c.size >= 0 && c.read >= c.size)) {
+		if verr := c.verify(); verr != nil {
+			return 0, verr
+		}
+	}
+	return n, err
+}
+
+// verify는 지금까지 읽은 본문을 기대값과 비교합니다. 백엔드가 빈 본문을 읽지 않는 경우에도 쓸 수 있습니다.
+func (c *checksumReader) verify() error {
+	c.verified = true
+	if !c.matches() {
+		return errChecksumMismatch
+	}
+	return nil
+}
+
+func (c *checksumReader) matches() bool {
+	if c.expected.MD5 != nil && !bytes.Equal(c.md5.Sum(nil), c.expected.MD5) {
+		return false
+	}
+	if c.expected.SHA256 != nil && !bytes.Equal(c.sha256.Sum(nil), c.expected.SHA256) {
+		return false
+	}
+	if c.expected.CRC32C != nil && !bytes.Equal(c.crc32c.Sum(nil), c.expected.CRC32C) {
+		return false
+	}
+	return true
+}
+
+// SHA256, CRC32C는 지금까지 읽은 본문의 체크섬(base64)입니다.
+func (c *checksumReader) SHA256() string {
+	return base64.StdEncoding.EncodeToString(c.sha256.Sum(nil))
+}
+
+func (c *checksumReader) CRC32C() string {
+	return base64.StdEncoding.EncodeToString(c.crc32c.Sum(nil))
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"compress/gzip"
+	"io"
+	"mime"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+	"github.com/klauspost/compress/zstd"
+	"github.com/sphynx/config"
+)
+
+// 서버가 고르는 우선순위 순서입니다. q 값이 같으면 앞쪽을 씁니다.
+const (
+	encodingZstd   = "zstd"
+	encodingBrotli = "br"
+	encodingGzip   = "gzip"
+)
+
+var supportedEncodings = []string{encodingZstd, encodingBrotli, encodingGzip}
+
+// compressedContentTypes는 허용 목록에 걸리더라도 다시 압축하지 않는 이미 압축된 형식입니다.
+var compressedContentTypes = []string{
+	"image/*",
+	"video/*",
+	"audio/*",
+	"font/woff",
+	"font/woff2",
+	"application/pdf",
+	"application/zip",
+	"application/gzip",
+	"application/x-gzip",
+	"application/zstd",
+	"application/x-bzip2",
+	"application/x-xz",
+	"application/x-7z-compressed",
+	"application/vnd.rar",
+	"application/x-rar-compressed",
+}
+
+// compressible은 download_compression_types 허용 목록과 download_compression_min_size로 압축 대상인지 판단합니다.
+// image/svg+xml처럼 텍스트인 이미지는 허용 목록에 직접 적으면 압축합니다.
+func compressible(contentType string, size int64) bool {
+	if size < config.Get[int64]("download_compression_min_size") {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	allowed := false
+	for _, pattern := range strings.Split(config.Get[string]("download_compression_types"), ",") {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == "" {
+			continue
+		}
+		if pattern == mediaType {
+			return true
+		}
+		if ok, _ := path.Match(pattern, mediaType); ok {
+			allowed = true
+		}
+	}
+	if !allowed {
+		return false
+	}
+	for _, pattern := range compressedContentTypes {
+		if ok, _ := path.Match(pattern, mediaType); ok {
+			return false
+		}
+	}
+	return true
+}
+
+// negotiateEncoding은 Accept-Encoding에서 q 값이 가장 큰 지원 인코딩을 고릅니다. 없으면 빈 문자열입니다.
+// "*"는 명시하지 않은 인코딩 전체에 적용하고, q=0은 거부로 봅니다.
+func negotiateEncoding(acceptEncoding string) string {
+	if strings.TrimSpace(acceptEncoding) == "" {
+		return ""
+	}
+
+	quality := map[string]float64{}
+	wildcard := -1.0
+	for _, item := range strings.Split(acceptEncoding, ",") {
+		name, params, _ := strings.Cut(item, ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		q := 1.0
+		for _, param := range strings.Split(params, ";") {
+			key, value, ok := strings.Cut(strings.TrimSpace(param), "=")
+			if ok && strings.EqualFold(strings.TrimSpace(key), "q") {
+				if v, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if name == "*" {
+			wildcard = q
+			continue
+		}
+		// x-gzip은 gzip의 옛 이름입니다.
+		if name == "x-gzip" {
+			name = encodingGzip
+		}
+		quality[name] = q
+	}
+
+	best, bestQ := "", 0.0
+	for _, enc := range supportedEncodings {
+		q, ok := quality[enc]
+		if !ok {
+			q = wildcard
+		}
+		if q > bestQ {
+			best, bestQ = enc, q
+		}
+	}
+	return best
+}
+
+// compressStream은 r을 encoding으로 압축해 읽을 수 있는 스트림을 반환합니다.
+// 호출자가 반환값을 닫으면 압축 고루틴도 끝납니다.
+func compressStream(encoding string, r io.Reader) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		var w io.WriteCloser
+		switch encoding {
+		case encodingZstd:
+			enc, err := zstd.NewWriter(pw, zstd.WithEncoderLevel(zstd.SpeedFastest))
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			w = enc
+		case encodingBrotli:
+			// 응답마다 실시간으로 압축하므로 기본값(6)보다 빠른 레벨을 씁니다.
+			w = brotli.NewWriterLevel(pw, 4)
+		default:
+			w = gzip.NewWriter(pw)
+		}
+		_, err := io.Copy(w, r)
+		if cerr := w.Close(); err == nil {
+			err = cerr
+		}
+		pw.CloseWithError(err)
+	}()
+	return pr
+}
+
+// weakETag는 압축한 표현에 쓰는 약한 ETag입니다. 바이트가 원본과 다르므로 강한 ETag를 그대로 쓸 수 없습니다.
+func weakETag(etag string) string {
+	if etag == "" || strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+	return `W/"` + strings.Trim(etag, `"`) + `"`
+}
@@ -0,0 +1,291 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	httpctx "guiio/backend/internal/port/httpctx"
+	"guiio/backend/internal/repository"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/rs/zerolog"
+	"github.com/sphynx/config"
+)
+
+// content-addressable 버킷은 본문을 SHA-256으로 이름 붙인 blob 하나에 저장하고, 같은 본문의 객체들이 그 blob을 함께 가리킵니다.
+// blobs 테이블의 ref_count가 0이 된 blob은 마지막 참조를 지운 요청이나 RunBlobCollector가 지웁니다.
+// blob은 서버 전체에서 공유하는 blob_bucket 버킷의 .guiio/blobs/<sha256>에 두므로, 버킷이 달라도 같은 본문은 한 벌만 저장합니다.
+const (
+	blobKeyPrefix    = systemKeyPrefix + "blobs/"
+	stagingKeyPrefix = systemKeyPrefix + "staging/"
+)
+
+type BucketContentAddressingRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+type BucketContentAddressingResponse struct {
+	Bucket  string `json:"bucket"`
+	Enabled bool   `json:"enabled"`
+}
+
+func (s *StorageService) GetBucketContentAddressing(ctx httpctx.Context) {
+	bucketName, ok := s.bucketConfigTarget(ctx, "content addressing")
+	if !ok {
+		return
+	}
+
+	enabled, err := s.repo.GetBucketContentAddressable(ctx.Context(), bucketName)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("get content addressing failed: %v", err)})
+		return
+	}
+	ctx.JSON(http.StatusOK, BucketContentAddressingResponse{Bucket: bucketName, Enabled: enabled})
+}
+
+// PutBucketContentAddressing은 이후 업로드와 복사를 blob에 저장할지 정합니다.
+// 이미 저장한 객체는 옮기지 않으며, 끈 뒤에도 기존 blob은 참조가 남아 있는 동안 유지됩니다.
+func (s *StorageService) PutBucketContentAddressing(ctx httpctx.Context) {
+	bucketName, ok := s.bucketConfigTarget(ctx, "content addressing")
+	if !ok {
+		return
+	}
+
+	var req BucketContentAddressingRequest
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	if err := s.repo.SetBucketContentAddressable(ctx.Context(), bucketName, req.Enabled); err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("set content addressing failed: %v", err)})
+		return
+	}
+	ctx.JSON(http.StatusOK, BucketContentAddressingResponse{Bucket: bucketName, Enabled: req.Enabled})
+}
+
+// contentAddressable은 버킷이 blob 저장을 쓰는지 확인합니다. repository가 없으면 참조 수를 관리할 수 없으므로 false입니다.
+func (s *StorageService) contentAddressable(ctx context.Context, bucketName string) (bool, error) {
+	if s.repo == nil {
+		return false, nil
+	}
+	enabled, err := s.repo.GetBucketContentAddressable(ctx, bucketName)
+	if err != nil {
+		return false, fmt.Errorf("get content addressing failed: %w", err)
+	}
+	return enabled, nil
+}
+
+// blobKey는 base64 SHA-256 체크섬으로 blob 키와 16진수 다이제스트를 만듭니다. 체크섬이 없거나 잘못되면 false입니다.
+func blobKey(checksumSHA256 string) (string, string, bool) {
+	sum, err := base64.StdEncoding.DecodeString(checksumSHA256)
+	if err != nil || len(sum) != 32 {
+		return "", "", false
+	}
+	digest := hex.EncodeToString(sum)
+	return blobKeyPrefix + digest, digest, true
+}
+
+func isBlobKey(storageKey string) bool {
+	return strings.HasPrefix(storageKey, blobKeyPrefix)
+}
+
+// blobBucket은 모든 content-addressable 버킷이 함께 쓰는 blob 저장 버킷입니다. 사용자 버킷 이름으로는 쓸 수 없습니다.
+func blobBucket() string {
+	return config.Get[string]("blob_bucket")
+}
+
+// storageBucket은 storageKey 본문이 있는 백엔드 버킷을 반환합니다. blob은 blobBucket에, 나머지는 객체의 버킷에 있습니다.
+func storageBucket(bucketName, storageKey string) string {
+	if isBlobKey(storageKey) {
+		return blobBucket()
+	}
+	return bucketName
+}
+
+func stagingKey() string {
+	return stagingKeyPrefix + newRandomID()
+}
+
+func isStagingKey(storageKey string) bool {
+	return strings.HasPrefix(storageKey, stagingKeyPrefix)
+}
+
+// hashStagedUpload는 임시 키에 합친 멀티파트 업로드 본문을 읽어 체크섬을 구하고, in이 그 본문의 blob을 가리키도록
+// StoragePath, BlobSHA256, 체크섬을 바꿉니다. 호출자는 임시 키를 원본으로 commitObject를 호출한 뒤 임시 키를 지웁니다.
+func (s *StorageService) hashStagedUpload(ctx context.Context, in *repository.ObjectUpsertInput) error {
+	body, err := s.client.GetObject(ctx, in.BucketName, in.StoragePath, minio.GetObjectOptions{})
+	if err != nil {
+		return fmt.Errorf("read staged upload failed: %w", err)
+	}
+	sums := newChecksumReader(body, in.Size, expectedChecksums{})
+	_, err = io.Copy(io.Discard, sums)
+	body.Close()
+	if err != nil {
+		return fmt.Errorf("read staged upload failed: %w", err)
+	}
+
+	in.ChecksumSHA256 = sums.SHA256()
+	in.ChecksumCRC32C = sums.CRC32C()
+	in.StoragePath, in.BlobSHA256, _ = blobKey(in.ChecksumSHA256)
+	return nil
+}
+
+// ensureBlob은 in.StoragePath blob이 백엔드에 없으면 src를 복사해 만들고, in.ETag를 blob의 ETag로 바꿉니다.
+// 같은 본문의 객체는 같은 ETag를 갖습니다. 참조를 더하기 전에 부르면 정리 작업이 지울 수 있으므로,
+// commitObject는 잠그기 전에 한 번 만들어 두고 blob을 잠근 뒤 다시 확인합니다.
+func (s *StorageService) ensureBlob(ctx context.Context, in *repository.ObjectUpsertInput, src minio.CopySrcOptions) error {
+	bucket := blobBucket()
+	info, err := s.client.StatObject(ctx, bucket, in.StoragePath, minio.StatObjectOptions{})
+	if err == nil {
+		in.ETag = info.ETag
+		return nil
+	}
+	if !isNoSuchKey(err) {
+		return fmt.Errorf("stat blob failed: %w", err)
+	}
+	dst := minio.CopyDestOptions{Bucket: bucket, Object: in.StoragePath}
+	uinfo, err := s.copyStorage(ctx, dst, src, in.Size)
+	if minio.ToErrorResponse(err).Code == "NoSuchBucket" {
+		// blob 버킷은 처음 blob을 저장할 때 만듭니다.
+		if err := s.client.MakeBucket(ctx, bucket, minio.MakeBucketOptions{Region: s.defaultRegion}); err != nil && !isBucketAlreadyOwned(err) {
+			return fmt.Errorf("create blob bucket failed: %w", err)
+		}
+		uinfo, err = s.copyStorage(ctx, dst, src, in.Size)
+	}
+	if err != nil {
+		return fmt.Errorf("store blob failed: %w", err)
+	}
+	in.ETag = uinfo.ETag
+	return nil
+}
+
+func isBucketAlreadyOwned(err error) bool {
+	code := minio.ToErrorResponse(err).Code
+	return code == "BucketAlreadyOwnedByYou" || code == "BucketAlreadyExists"
+}
+
+// maxCopyObjectSize는 CopyObject 한 번으로 복사할 수 있는 최대 크기입니다.
+const maxCopyObjectSize = 5 << 30
+
+// copyStorage는 백엔드 안에서 src 본문을 dst로 복사합니다. size가 CopyObject 한도를 넘거나 알 수 없으면(음수)
+// ComposeObject로 파트를 나눠 복사합니다. dst.ReplaceMetadata가 없으면 두 방식 모두 원본의 사용자 메타데이터를 유지합니다.
+func (s *StorageService) copyStorage(ctx context.Context, dst minio.CopyDestOptions, src minio.CopySrcOptions, size int64) (minio.UploadInfo, error) {
+	if size >= 0 && size <= maxCopyObjectSize {
+		return s.client.CopyObject(ctx, dst, src)
+	}
+	return s.client.ComposeObject(ctx, dst, src)
+}
+
+// releaseStorage는 objects 행이 더는 가리키지 않는 백엔드 객체를 정리합니다.
+// blob은 다른 객체가 참조하고 있을 수 있으므로 참조 수를 확인한 뒤에만 지웁니다.
+func (s *StorageService) releaseStorage(ctx context.Context, bucketName, storageKey string) {
+	if isBlobKey(storageKey) {
+		_, _ = s.collectBlob(ctx, storageKey)
+		return
+	}
+	_ = s.client.RemoveObject(ctx, bucketName, storageKey, minio.RemoveObjectOptions{})
+}
+
+// collectBlob은 참조가 남지 않은 blob의 백엔드 객체와 blobs 행을 지우고 true를 반환합니다. 참조가 있으면 아무것도 하지 않습니다.
+// 행이 없는 blob(만들었지만 커밋하지 못한 쓰기의 것)도 지웁니다. 그 blob을 가리키려던 다른 쓰기는 blob을 잠근 뒤 다시 만듭니다.
+func (s *StorageService) collectBlob(ctx context.Context, storageKey string) (bool, error) {
+	btx, err := s.repo.BeginBlobTx(ctx, storageKey)
+	if err != nil {
+		return false, fmt.Errorf("lock blob failed: %w", err)
+	}
+	defer btx.Rollback()
+
+	current := btx.Current()
+	if current != nil && current.RefCount > 0 {
+		return false, nil
+	}
+	if err := s.client.RemoveObject(ctx, blobBucket(), storageKey, minio.RemoveObjectOptions{}); err != nil {
+		return false, fmt.Errorf("remove blob failed: %w", err)
+	}
+	if current == nil {
+		return false, nil
+	}
+	if err := btx.Delete(ctx); err != nil {
+		return false, fmt.Errorf("delete blob failed: %w", err)
+	}
+	if err := btx.Commit(); err != nil {
+		return false, fmt.Errorf("delete blob failed: %w", err)
+	}
+	return true, nil
+}
+
+// RunBlobCollector는 blob_gc_interval_minutes마다 참조가 없는 blob을 지웁니다.
+// 삭제 요청이 blob을 바로 지우지 못하고 끝난 경우(백엔드 오류, 프로세스 종료)를 정리합니다.
+func (s *StorageService) RunBlobCollector(ctx context.Context, log *zerolog.Logger) {
+	interval := time.Duration(config.Get[int]("blob_gc_interval_minutes")) * time.Minute
+	if s.repo == nil || interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		n, err := s.CollectUnreferencedBlobs(ctx)
+		if err != nil {
+			log.Error().Err(err).Msg("blob collection failed")
+			continue
+		}
+		if n > 0 {
+			log.Info().Msgf("removed %d unreferenced blobs", n)
+		}
+	}
+}
+
+// CollectUnreferencedBlobs는 ref_count가 0인 blob을 모두 지우고 그 개수를 반환합니다.
+func (s *StorageService) CollectUnreferencedBlobs(ctx context.Context) (int, error) {
+	removed := 0
+	for {
+		blobs, err := s.repo.ListUnreferencedBlobs(ctx, uploadCleanupBatch)
+		if err != nil {
+			return removed, err
+		}
+		if len(blobs) == 0 {
+			return removed, nil
+		}
+		for _, b := range blobs {
+			ok, err := s.collectBlob(ctx, b.StoragePath)
+			if err != nil {
+				return removed, err
+			}
+			if ok {
+				removed++
+			}
+		}
+	}
+}
+
+// shareBlob은 대상 버킷이 content-addressable이면 원본 버킷과 관계없이 in이 원본 체크섬의 blob을 가리키도록 StoragePath, BlobSHA256을 바꾸고 true를 반환합니다.
+// 호출자는 head.StorageKey를 원본으로 commitObject를 호출해, blob이 이미 있으면 본문을 복사하지 않고 참조만 더합니다.
+// 대상 버킷이 content-addressable이 아니거나 원본 체크섬이 없으면 false를 반환하고, 호출자는 백엔드 복사로 처리합니다.
+func (s *StorageService) shareBlob(ctx context.Context, head *objectHead, in *repository.ObjectUpsertInput) (bool, error) {
+	enabled, err := s.contentAddressable(ctx, in.BucketName)
+	if err != nil || !enabled {
+		return false, err
+	}
+	key, digest, ok := blobKey(head.ChecksumSHA256)
+	if !ok {
+		return false, nil
+	}
+	in.StoragePath = key
+	in.BlobSHA256 = digest
+	return true, nil
+}
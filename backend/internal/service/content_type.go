@@ -0,0 +1,168 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+
+	httpctx "guiio/backend/internal/port/httpctx"
+)
+
+const defaultContentType = "application/octet-stream"
+
+// 버킷별 업로드 Content-Type 정책입니다. 설정하지 않은 버킷은 sniff-if-missing으로 동작합니다.
+const (
+	// 클라이언트 값을 그대로 쓰고, 없을 때만 확장자로 정합니다. 본문은 미리 읽지 않습니다.
+	contentTypePolicyTrustClient = "trust-client"
+	// 클라이언트 값이 없거나 application/octet-stream이면 본문 앞부분과 확장자로 판별합니다.
+	contentTypePolicySniffIfMissing = "sniff-if-missing"
+	// 클라이언트 값과 상관없이 판별하고, 판별하지 못했을 때만 클라이언트 값을 씁니다.
+	contentTypePolicyAlwaysSniff = "always-sniff"
+)
+
+// objects.content_type_source 값입니다.
+const (
+	contentTypeSourceDeclared = "declared"
+	contentTypeSourceDetected = "detected"
+	contentTypeSourceDefault  = "default"
+)
+
+// sniffLen은 http.DetectContentType이 보는 최대 길이입니다.
+const sniffLen = 512
+
+type BucketContentTypePolicyRequest struct {
+	Policy string `json:"policy"`
+}
+
+type BucketContentTypePolicyResponse struct {
+	Bucket string `json:"bucket"`
+	Policy string `json:"policy"`
+}
+
+func (s *StorageService) GetBucketContentTypePolicy(ctx httpctx.Context) {
+	bucketName, ok := s.bucketConfigTarget(ctx, "content type policy")
+	if !ok {
+		return
+	}
+
+	policy, err := s.contentTypePolicy(ctx.Context(), bucketName)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+	ctx.JSON(http.StatusOK, BucketContentTypePolicyResponse{Bucket: bucketName, Policy: policy})
+}
+
+// PutBucketContentTypePolicy는 이후 업로드에 적용할 Content-Type 판별 정책을 바꿉니다. 이미 저장한 객체는 바꾸지 않습니다.
+func (s *StorageService) PutBucketContentTypePolicy(ctx httpctx.Context) {
+	bucketName, ok := s.bucketConfigTarget(ctx, "content type policy")
+	if !ok {
+		return
+	}
+
+	var req BucketContentTypePolicyRequest
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+	policy := strings.ToLower(strings.TrimSpace(req.Policy))
+	switch policy {
+	case contentTypePolicyTrustClient, contentTypePolicySniffIfMissing, contentTypePolicyAlwaysSniff:
+	default:
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "policy must be trust-client, sniff-if-missing or always-sniff"})
+		return
+	}
+
+	if err := s.repo.SetBucketContentTypePolicy(ctx.Context(), bucketName, policy); err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("set content type policy failed: %v", err)})
+		return
+	}
+	ctx.JSON(http.StatusOK, BucketContentTypePolicyResponse{Bucket: bucketName, Policy: policy})
+}
+
+// contentTypePolicy는 버킷에 적용할 정책입니다. repository가 없거나 설정하지 않은 버킷은 sniff-if-missing입니다.
+func (s *StorageService) contentTypePolicy(ctx context.Context, bucketName string) (string, error) {
+	if s.repo == nil {
+		return contentTypePolicySniffIfMissing, nil
+	}
+	policy, err := s.repo.GetBucketContentTypePolicy(ctx, bucketName)
+	if err != nil {
+		return "", fmt.Errorf("get content type policy failed: %w", err)
+	}
+	if policy == "" {
+		return contentTypePolicySniffIfMissing, nil
+	}
+	return policy, nil
+}
+
+// resolveContentType은 버킷 정책에 따라 저장할 Content-Type과 그 출처를 정합니다.
+// 본문을 판별해야 하면 앞부분을 미리 읽으므로, 이후에는 반환한 reader로 본문을 읽어야 합니다.
+func (s *StorageService) resolveContentType(ctx context.Context, in putObjectInput, body io.Reader) (string, string, io.Reader, error) {
+	declared := strings.TrimSpace(in.ContentType)
+	if mediaType, _, _ := mime.ParseMediaType(declared); mediaType == defaultContentType {
+		declared = ""
+	}
+
+	policy, err := s.contentTypePolicy(ctx, in.BucketName)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	switch {
+	case declared != "" && policy != contentTypePolicyAlwaysSniff:
+		return declared, contentTypeSourceDeclared, body, nil
+	case policy == contentTypePolicyTrustClient:
+		if byExt := mime.TypeByExtension(path.Ext(in.ObjectName)); byExt != "" {
+			return byExt, contentTypeSourceDetected, body, nil
+		}
+		return defaultContentType, contentTypeSourceDefault, body, nil
+	}
+
+	br := bufio.NewReaderSize(body, sniffLen)
+	head, err := br.Peek(sniffLen)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", "", nil, err
+	}
+	if detected := detectContentType(in.ObjectName, head); detected != "" {
+		return detected, contentTypeSourceDetected, br, nil
+	}
+	if declared != "" {
+		return declared, contentTypeSourceDeclared, br, nil
+	}
+	return defaultContentType, contentTypeSourceDefault, br, nil
+}
+
+// detectContentType은 본문 앞부분의 시그니처(http.DetectContentType)와 확장자로 타입을 고릅니다.
+// 시그니처로 얻은 값이 octet-stream, text/plain, zip처럼 여러 형식을 포괄하면 확장자 쪽이 더 구체적이므로 확장자를 씁니다.
+// 둘 다 알 수 없으면 빈 문자열을 반환합니다.
+func detectContentType(name string, head []byte) string {
+	byExt := mime.TypeByExtension(path.Ext(name))
+	if len(head) == 0 {
+		return byExt
+	}
+	sniffed := http.DetectContentType(head)
+	mediaType, _, _ := mime.ParseMediaType(sniffed)
+	switch mediaType {
+	case defaultContentType:
+		return byExt
+	case "text/plain", "application/zip":
+		if byExt != "" {
+			return byExt
+		}
+	}
+	return sniffed
+}
+
+// declaredContentTypeSource는 본문을 판별하지 않는 경로(멀티파트 업로드, 메타데이터 변경)에서 쓰는 출처입니다.
+func declaredContentTypeSource(contentType string) string {
+	if contentType == defaultContentType {
+		return contentTypeSourceDefault
+	}
+	return contentTypeSourceDeclared
+}
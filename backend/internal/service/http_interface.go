@@ -9,4 +9,49 @@ type BucketService interface {
 	GetBucket(ctx httpctx.Context)
 	UploadObject(ctx httpctx.Context)
 	DownloadObject(ctx httpctx.Context)
+	SignDownload(ctx httpctx.Context)
+	ListObjects(ctx httpctx.Context)
+	DeleteObject(ctx httpctx.Context)
+	DeleteObjects(ctx httpctx.Context)
+	HeadObject(ctx httpctx.Context)
+	PutObject(ctx httpctx.Context)
+	CopyObject(ctx httpctx.Context)
+	MoveObject(ctx httpctx.Context)
+	UpdateObjectMetadata(ctx httpctx.Context)
+	GetObjectTagging(ctx httpctx.Context)
+	PutObjectTagging(ctx httpctx.Context)
+	DeleteObjectTagging(ctx httpctx.Context)
+	SearchObjects(ctx httpctx.Context)
+	DownloadArchive(ctx httpctx.Context)
+	GetBucketVersioning(ctx httpctx.Context)
+	PutBucketVersioning(ctx httpctx.Context)
+	GetBucketContentTypePolicy(ctx httpctx.Context)
+	PutBucketContentTypePolicy(ctx httpctx.Context)
+	GetBucketContentAddressing(ctx httpctx.Context)
+	PutBucketContentAddressing(ctx httpctx.Context)
+	GetBucketObjectLock(ctx httpctx.Context)
+	PutBucketObjectLock(ctx httpctx.Context)
+	GetBucketLifecycle(ctx httpctx.Context)
+	PutBucketLifecycle(ctx httpctx.Context)
+	DeleteBucketLifecycle(ctx httpctx.Context)
+	ListLifecycleEvents(ctx httpctx.Context)
+	GetObjectRetention(ctx httpctx.Context)
+	PutObjectRetention(ctx httpctx.Context)
+	GetObjectLegalHold(ctx httpctx.Context)
+	PutObjectLegalHold(ctx httpctx.Context)
+	ListObjectVersions(ctx httpctx.Context)
+	RestoreObjectVersion(ctx httpctx.Context)
+	ListTrash(ctx httpctx.Context)
+	RestoreTrashedObject(ctx httpctx.Context)
+	EmptyTrash(ctx httpctx.Context)
+	InitiateMultipartUpload(ctx httpctx.Context)
+	UploadPart(ctx httpctx.Context)
+	ListParts(ctx httpctx.Context)
+	CompleteMultipartUpload(ctx httpctx.Context)
+	AbortMultipartUpload(ctx httpctx.Context)
+	TusOptions(ctx httpctx.Context)
+	CreateTusUpload(ctx httpctx.Context)
+	HeadTusUpload(ctx httpctx.Context)
+	PatchTusUpload(ctx httpctx.Context)
+	TerminateTusUpload(ctx httpctx.Context)
 }
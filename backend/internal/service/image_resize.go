@@ -0,0 +1,330 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	httpctx "guiio/backend/internal/port/httpctx"
+
+	"github.com/HugoSmits86/nativewebp"
+	"github.com/minio/minio-go/v7"
+	"github.com/sphynx/config"
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+)
+
+// 리사이즈 결과(파생 이미지)는 원본 이름의 해시와 ETag 아래 숨김 객체로 캐시합니다.
+// 원본이 바뀌면 ETag가 달라져 새로 만들고, 원본을 지우면 함께 지웁니다.
+const derivativeKeyPrefix = systemKeyPrefix + "derivatives/"
+
+const (
+	// contain은 비율을 유지한 채 w x h 안에 맞추고, cover는 w x h를 채운 뒤 가운데를 잘라내며, fill은 비율을 무시하고 늘립니다.
+	imageFitContain = "contain"
+	imageFitCover   = "cover"
+	imageFitFill    = "fill"
+
+	imageFormatJPEG = "jpeg"
+	imageFormatPNG  = "png"
+	imageFormatWebP = "webp"
+)
+
+var (
+	errUnsupportedImage = errors.New("object is not a supported image (jpeg, png, gif, webp)")
+	errImageTooLarge    = errors.New("image exceeds the allowed pixel count")
+)
+
+var imageFormatContentTypes = map[string]string{
+	imageFormatJPEG: "image/jpeg",
+	imageFormatPNG:  "image/png",
+	imageFormatWebP: "image/webp",
+}
+
+// imageResizeSpec은 ?w=&h=&fit=&format= 요청입니다. Width, Height가 0이면 그 방향은 비율에 맞춥니다.
+type imageResizeSpec struct {
+	Width  int
+	Height int
+	Fit    string
+	// Format이 비어 있으면 원본 형식을 따릅니다(GIF는 PNG로 씁니다).
+	Format string
+}
+
+func isImageResizeRequest(ctx httpctx.Context) bool {
+	for _, name := range []string{"w", "h", "fit", "format"} {
+		if ctx.Query(name) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// parseImageResizeSpec은 쿼리를 검증합니다. w, h는 image_resize_sizes 목록에 있는 값만 허용합니다.
+func parseImageResizeSpec(ctx httpctx.Context) (imageResizeSpec, error) {
+	allowed := map[int]bool{}
+	for _, v := range strings.Split(config.Get[string]("image_resize_sizes"), ",") {
+		if n, err := strconv.Atoi(strings.TrimSpace(v)); err == nil && n > 0 {
+			allowed[n] = true
+		}
+	}
+
+	var spec imageResizeSpec
+	for _, p := range []struct {
+		name string
+		dst  *int
+	}{{"w", &spec.Width}, {"h", &spec.Height}} {
+		raw := strings.TrimSpace(ctx.Query(p.name))
+		if raw == "" {
+			continue
+		}
+		n, err := strconv.Atoi(raw)
+		if err != nil || !allowed[n] {
+			return spec, fmt.Errorf("%s must be one of the allowed sizes (%s)", p.name, config.Get[string]("image_resize_sizes"))
+		}
+		*p.dst = n
+	}
+	// 크기 없이 fit, format만 주면 원본 해상도로 다시 인코딩하게 되어 허용 목록을 우회하므로 막습니다.
+	if spec.Width == 0 && spec.Height == 0 {
+		return spec, fmt.Errorf("w or h is required (%s)", config.Get[string]("image_resize_sizes"))
+	}
+
+	spec.Fit = strings.ToLower(strings.TrimSpace(ctx.Query("fit")))
+	switch spec.Fit {
+	case "":
+		spec.Fit = imageFitContain
+	case imageFitContain, imageFitCover, imageFitFill:
+	default:
+		return spec, errors.New("fit must be contain, cover or fill")
+	}
+
+	spec.Format = strings.ToLower(strings.TrimSpace(ctx.Query("format")))
+	switch spec.Format {
+	case "", imageFormatJPEG, imageFormatPNG, imageFormatWebP:
+	case "jpg":
+		spec.Format = imageFormatJPEG
+	default:
+		return spec, errors.New("format must be webp, jpeg or png")
+	}
+	return spec, nil
+}
+
+// serveImageDerivative는 캐시된 파생 이미지가 있으면 그대로 보내고, 없으면 만들어 저장한 뒤 보냅니다.
+// ETag는 파생 객체 자체의 ETag이고, X-Guiio-Derivative-Cache 헤더로 캐시 적중 여부(hit, miss)를 알려줍니다.
+func (s *StorageService) serveImageDerivative(ctx httpctx.Context, bucketName, objectName string, head *objectHead, spec imageResizeSpec) {
+	sourceFormat := imageFormatOf(head.ContentType)
+	if sourceFormat == "" {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: errUnsupportedImage.Error()})
+		return
+	}
+	if spec.Format == "" {
+		spec.Format = sourceFormat
+	}
+	contentType := imageFormatContentTypes[spec.Format]
+
+	reqCtx := ctx.Context()
+	key := derivativeKey(objectName, head.ETag, spec)
+	derived := &objectHead{
+		ContentType:        contentType,
+		LastModified:       head.LastModified,
+		CacheControl:       head.CacheControl,
+		ContentDisposition: head.ContentDisposition,
+		Expires:            head.Expires,
+	}
+
+	if info, err := s.client.StatObject(reqCtx, bucketName, key, minio.StatObjectOptions{}); err == nil {
+		derived.ETag = info.ETag
+		writeObjectHeaders(ctx, derived)
+		ctx.SetHeader("X-Guiio-Derivative-Cache", "hit")
+		if isNotModified(ctx.Request(), derived.ETag, derived.LastModified) {
+			_ = ctx.Stream(http.StatusNotModified, "", bytes.NewReader(nil))
+			return
+		}
+
+		r, err := s.client.GetObject(reqCtx, bucketName, key, minio.GetObjectOptions{})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("download failed: %v", err)})
+			return
+		}
+		defer r.Close()
+		ctx.SetHeader("Content-Length", strconv.FormatInt(info.Size, 10))
+		if err := ctx.Stream(http.StatusOK, contentType, r); err != nil {
+			ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("stream failed: %v", err)})
+		}
+		return
+	}
+
+	data, err := s.renderDerivative(reqCtx, bucketName, head, spec)
+	if err != nil {
+		switch {
+		case errors.Is(err, errImageTooLarge):
+			ctx.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Error: err.Error()})
+		case errors.Is(err, errUnsupportedImage):
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("resize image failed: %v", err)})
+		}
+		return
+	}
+
+	uinfo, err := s.client.PutObject(reqCtx, bucketName, key, bytes.NewReader(data), int64(len(data)), minio.PutObjectOptions{ContentType: contentType})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("save derivative failed: %v", err)})
+		return
+	}
+
+	derived.ETag = uinfo.ETag
+	writeObjectHeaders(ctx, derived)
+	ctx.SetHeader("X-Guiio-Derivative-Cache", "miss")
+	ctx.SetHeader("Content-Length", strconv.Itoa(len(data)))
+	if err := ctx.Stream(http.StatusOK, contentType, bytes.NewReader(data)); err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("stream failed: %v", err)})
+	}
+}
+
+// renderDerivative는 원본을 디코딩해 크기를 바꾸고 spec.Format으로 다시 인코딩합니다.
+// 디코딩 전에 헤더만 읽어 image_resize_max_pixels를 넘는 이미지는 메모리에 펼치지 않습니다.
+func (s *StorageService) renderDerivative(ctx context.Context, bucketName string, head *objectHead, spec imageResizeSpec) ([]byte, error) {
+	r, err := s.client.GetObject(ctx, storageBucket(bucketName, head.StorageKey), head.StorageKey, minio.GetObjectOptions{})
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var buf bytes.Buffer
+	cfg, _, err := image.DecodeConfig(io.TeeReader(r, &buf))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errUnsupportedImage, err)
+	}
+	if maxPixels := config.Get[int64]("image_resize_max_pixels"); maxPixels > 0 && int64(cfg.Width)*int64(cfg.Height) > maxPixels {
+		return nil, errImageTooLarge
+	}
+	src, _, err := image.Decode(io.MultiReader(&buf, r))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errUnsupportedImage, err)
+	}
+
+	dst := resizeImage(src, spec)
+	var out bytes.Buffer
+	switch spec.Format {
+	case imageFormatJPEG:
+		err = jpeg.Encode(&out, dst, &jpeg.Options{Quality: 85})
+	case imageFormatWebP:
+		err = nativewebp.Encode(&out, dst, nil)
+	default:
+		err = png.Encode(&out, dst)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("encode %s: %w", spec.Format, err)
+	}
+	return out.Bytes(), nil
+}
+
+// resizeImage는 spec에 맞춰 크기를 바꿉니다. contain이나 한쪽만 지정한 경우에는 원본보다 키우지 않습니다.
+// JPEG은 알파 채널이 없으므로 투명한 부분을 흰 배경 위에 그립니다.
+func resizeImage(src image.Image, spec imageResizeSpec) image.Image {
+	sr := src.Bounds()
+	sw, sh := sr.Dx(), sr.Dy()
+	w, h := spec.Width, spec.Height
+
+	switch {
+	case w == 0 && h == 0:
+		w, h = sw, sh
+	case w == 0 || h == 0 || spec.Fit == imageFitContain:
+		scale := 0.0
+		if w > 0 {
+			scale = float64(w) / float64(sw)
+		}
+		if hs := float64(h) / float64(sh); h > 0 && (scale == 0 || hs < scale) {
+			scale = hs
+		}
+		if scale > 1 {
+			scale = 1
+		}
+		w, h = max(1, int(float64(sw)*scale+0.5)), max(1, int(float64(sh)*scale+0.5))
+	case spec.Fit == imageFitCover:
+		// 목표 비율과 같은 가운데 영역만 잘라 씁니다.
+		if sw*h > sh*w {
+			cw := sh * w / h
+			sr.Min.X += (sw - cw) / 2
+			sr.Max.X = sr.Min.X + cw
+		} else {
+			ch := sw * h / w
+			sr.Min.Y += (sh - ch) / 2
+			sr.Max.Y = sr.Min.Y + ch
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, w, h))
+	op := draw.Src
+	if spec.Format == imageFormatJPEG {
+		draw.Draw(dst, dst.Bounds(), image.NewUniform(color.White), image.Point{}, draw.Src)
+		op = draw.Over
+	}
+	draw.CatmullRom.Scale(dst, dst.Bounds(), src, sr, op, nil)
+	return dst
+}
+
+// imageFormatOf는 원본 Content-Type으로 기본 출력 형식을 고릅니다. 디코딩할 수 없는 형식은 빈 문자열입니다.
+func imageFormatOf(contentType string) string {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	switch mediaType {
+	case "image/jpeg":
+		return imageFormatJPEG
+	case "image/png", "image/gif":
+		return imageFormatPNG
+	case "image/webp":
+		return imageFormatWebP
+	}
+	return ""
+}
+
+// derivativePrefix는 객체 하나의 파생 이미지가 모이는 접두사입니다.
+// 이름 대신 해시를 써서 "a"의 접두사가 "a/b"의 파생 이미지까지 가리키지 않게 합니다.
+func derivativePrefix(objectName string) string {
+	sum := sha256.Sum256([]byte(objectName))
+	return derivativeKeyPrefix + hex.EncodeToString(sum[:]) + "/"
+}
+
+func derivativeKey(objectName, etag string, spec imageResizeSpec) string {
+	return fmt.Sprintf("%s%s/%dx%d-%s.%s", derivativePrefix(objectName), url.PathEscape(strings.Trim(etag, `"`)), spec.Width, spec.Height, spec.Fit, spec.Format)
+}
+
+// removeDerivatives는 원본을 백엔드에서 지운 뒤 캐시된 파생 이미지를 정리합니다.
+// 실패해도 원본 삭제는 이미 끝났으므로 에러를 돌려주지 않고, 남은 객체는 숨김 경로에 그대로 둡니다.
+func (s *StorageService) removeDerivatives(ctx context.Context, bucketName, objectName string) {
+	s.pruneDerivatives(ctx, bucketName, objectName, "")
+}
+
+// pruneDerivatives는 keepETag가 아닌 ETag로 만든 파생 이미지를 지웁니다. keepETag가 비어 있으면 모두 지웁니다.
+// 덮어쓰기로 원본 ETag가 바뀌면 이전 ETag의 파생 이미지는 다시 참조되지 않으므로 이 함수로 정리합니다.
+func (s *StorageService) pruneDerivatives(ctx context.Context, bucketName, objectName, keepETag string) {
+	prefix := derivativePrefix(objectName)
+	keep := ""
+	if keepETag != "" {
+		keep = prefix + url.PathEscape(strings.Trim(keepETag, `"`)) + "/"
+	}
+	listCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	for info := range s.client.ListObjects(listCtx, bucketName, minio.ListObjectsOptions{Prefix: prefix, Recursive: true}) {
+		if info.Err != nil {
+			return
+		}
+		if keep != "" && strings.HasPrefix(info.Key, keep) {
+			continue
+		}
+		_ = s.client.RemoveObject(ctx, bucketName, info.Key, minio.RemoveObjectOptions{})
+	}
+}
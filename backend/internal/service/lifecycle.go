@@ -0,0 +1,511 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"guiio/backend/ent"
+	httpctx "guiio/backend/internal/port/httpctx"
+	"guiio/backend/internal/repository"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/rs/zerolog"
+	"github.com/sphynx/config"
+)
+
+const (
+	maxLifecycleRules      = 1000
+	maxLifecycleRuleIDSize = 255
+
+	lifecycleExpireObject  = "expire_object"
+	lifecycleExpireVersion = "expire_version"
+	lifecycleAbortUpload   = "abort_upload"
+)
+
+type LifecycleRuleConfig struct {
+	ID string `json:"id"`
+	// 생략하면 켜진 규칙입니다.
+	Enabled *bool             `json:"enabled,omitempty"`
+	Prefix  string            `json:"prefix,omitempty"`
+	Tags    map[string]string `json:"tags,omitempty"`
+	// ExpirationDays, ExpirationDate는 현재 객체 만료 조건이며 둘 중 하나만 쓸 수 있습니다.
+	ExpirationDays            int        `json:"expiration_days,omitempty"`
+	ExpirationDate            *time.Time `json:"expiration_date,omitempty"`
+	NoncurrentExpirationDays  int        `json:"noncurrent_expiration_days,omitempty"`
+	AbortIncompleteUploadDays int        `json:"abort_incomplete_upload_days,omitempty"`
+}
+
+type BucketLifecycleRequest struct {
+	Rules []LifecycleRuleConfig `json:"rules"`
+}
+
+type BucketLifecycleResponse struct {
+	Bucket string                `json:"bucket"`
+	Rules  []LifecycleRuleConfig `json:"rules"`
+}
+
+type LifecycleEventInfo struct {
+	RuleID    string    `json:"rule_id"`
+	Action    string    `json:"action"`
+	Key       string    `json:"key"`
+	VersionID string    `json:"version_id,omitempty"`
+	UploadID  string    `json:"upload_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ListLifecycleEventsResponse struct {
+	Bucket      string               `json:"bucket"`
+	Events      []LifecycleEventInfo `json:"events"`
+	IsTruncated bool                 `json:"is_truncated"`
+	NextCursor  string               `json:"next_cursor,omitempty"`
+}
+
+func (s *StorageService) GetBucketLifecycle(ctx httpctx.Context) {
+	bucketName, ok := s.bucketConfigTarget(ctx, "lifecycle")
+	if !ok {
+		return
+	}
+
+	rules, err := s.repo.GetLifecycleRules(ctx.Context(), bucketName)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("get lifecycle failed: %v", err)})
+		return
+	}
+	ctx.JSON(http.StatusOK, newBucketLifecycleResponse(bucketName, rules))
+}
+
+// PutBucketLifecycle은 버킷의 수명 주기 규칙을 요청 본문의 규칙으로 모두 대체합니다.
+// 규칙은 다음 평가부터 적용되며, 규칙을 모두 지우려면 DeleteBucketLifecycle을 씁니다.
+func (s *StorageService) PutBucketLifecycle(ctx httpctx.Context) {
+	bucketName, ok := s.bucketConfigTarget(ctx, "lifecycle")
+	if !ok {
+		return
+	}
+
+	var req BucketLifecycleRequest
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+	inputs, err := lifecycleRuleInputs(req.Rules)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	rules, err := s.repo.PutLifecycleRules(ctx.Context(), bucketName, inputs)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("set lifecycle failed: %v", err)})
+		return
+	}
+	ctx.JSON(http.StatusOK, newBucketLifecycleResponse(bucketName, rules))
+}
+
+func (s *StorageService) DeleteBucketLifecycle(ctx httpctx.Context) {
+	bucketName, ok := s.bucketConfigTarget(ctx, "lifecycle")
+	if !ok {
+		return
+	}
+
+	if err := s.repo.DeleteLifecycleRules(ctx.Context(), bucketName); err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("delete lifecycle failed: %v", err)})
+		return
+	}
+	ctx.JSON(http.StatusOK, newBucketLifecycleResponse(bucketName, nil))
+}
+
+// ListLifecycleEvents는 수명 주기 평가가 지운 항목을 최신 기록부터 보여 줍니다.
+func (s *StorageService) ListLifecycleEvents(ctx httpctx.Context) {
+	bucketName, ok := s.bucketConfigTarget(ctx, "lifecycle")
+	if !ok {
+		return
+	}
+
+	limit, err := parseListLimit(ctx.Query("limit"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	beforeID, err := decodeEventCursor(ctx.Query("cursor"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	events, err := s.repo.ListLifecycleEvents(ctx.Context(), bucketName, beforeID, limit+1)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("list lifecycle events failed: %v", err)})
+		return
+	}
+
+	resp := ListLifecycleEventsResponse{Bucket: bucketName}
+	if len(events) > limit {
+		events = events[:limit]
+		resp.IsTruncated = true
+		resp.NextCursor = encodeListCursor(strconv.Itoa(events[limit-1].ID))
+	}
+	resp.Events = make([]LifecycleEventInfo, 0, len(events))
+	for _, e := range events {
+		resp.Events = append(resp.Events, LifecycleEventInfo{
+			RuleID:    e.RuleID,
+			Action:    e.Action,
+			Key:       e.ObjectName,
+			VersionID: e.VersionID,
+			UploadID:  e.UploadID,
+			CreatedAt: e.CreatedAt,
+		})
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// RunLifecycleEvaluator는 lifecycle_interval_minutes마다 켜진 수명 주기 규칙을 모두 적용합니다. ctx가 끝나면 반환합니다.
+func (s *StorageService) RunLifecycleEvaluator(ctx context.Context, log *zerolog.Logger) {
+	interval := time.Duration(config.Get[int]("lifecycle_interval_minutes")) * time.Minute
+	if s.repo == nil || interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		n, err := s.ApplyLifecycle(ctx, time.Now())
+		if err != nil {
+			log.Error().Err(err).Msg("lifecycle evaluation failed")
+		}
+		if n > 0 {
+			log.Info().Msgf("lifecycle rules removed %d items", n)
+		}
+	}
+}
+
+// ApplyLifecycle은 now 기준으로 켜진 규칙을 모두 적용하고 지운 항목 수를 반환합니다.
+// 한 규칙이 실패해도 나머지 규칙은 계속 적용하고, 실패는 모아서 반환합니다.
+func (s *StorageService) ApplyLifecycle(ctx context.Context, now time.Time) (int, error) {
+	rules, err := s.repo.ListEnabledLifecycleRules(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	var errs []error
+	for _, rule := range rules {
+		n, err := s.applyLifecycleRule(ctx, rule, now)
+		removed += n
+		if err != nil {
+			errs = append(errs, fmt.Errorf("bucket %q rule %q: %w", rule.BucketName, rule.RuleID, err))
+		}
+	}
+	return removed, errors.Join(errs...)
+}
+
+func (s *StorageService) applyLifecycleRule(ctx context.Context, rule *ent.LifecycleRule, now time.Time) (int, error) {
+	removed := 0
+	if cutoff, ok := lifecycleExpirationCutoff(rule, now); ok {
+		n, err := s.expireObjects(ctx, rule, cutoff)
+		removed += n
+		if err != nil {
+			return removed, err
+		}
+	}
+	if rule.NoncurrentExpirationDays > 0 {
+		n, err := s.expireNoncurrentVersions(ctx, rule, now.AddDate(0, 0, -rule.NoncurrentExpirationDays))
+		removed += n
+		if err != nil {
+			return removed, err
+		}
+	}
+	if rule.AbortIncompleteUploadDays > 0 {
+		n, err := s.abortIncompleteUploads(ctx, rule, now.AddDate(0, 0, -rule.AbortIncompleteUploadDays))
+		removed += n
+		if err != nil {
+			return removed, err
+		}
+	}
+	return removed, nil
+}
+
+// expireObjects는 cutoff 이전에 마지막으로 바뀐 현재 객체를 일반 삭제 경로로 지웁니다.
+// 휴지통, 버전 관리 삭제 마커, Object Lock은 사용자 삭제와 똑같이 적용되고, 잠긴 객체는 건너뜁니다.
+func (s *StorageService) expireObjects(ctx context.Context, rule *ent.LifecycleRule, cutoff time.Time) (int, error) {
+	removed := 0
+	startAfter := ""
+	for {
+		objects, err := s.repo.ListLifecycleObjects(ctx, repository.LifecycleObjectInput{
+			BucketName:     rule.BucketName,
+			Prefix:         rule.Prefix,
+			Tags:           rule.Tags,
+			ModifiedBefore: cutoff,
+			StartAfter:     startAfter,
+			Limit:          uploadCleanupBatch,
+		})
+		if err != nil {
+			return removed, err
+		}
+		if len(objects) == 0 {
+			return removed, nil
+		}
+		for _, obj := range objects {
+			// 목록을 읽은 뒤 다시 업로드된 객체는 ETag가 바뀌므로 지우지 않습니다.
+			err := s.deleteObject(ctx, rule.BucketName, obj.ObjectName, writeCondition{IfMatch: obj.Etag}, false)
+			switch {
+			case err == nil:
+			case errors.Is(err, errObjectNotFound), errors.Is(err, errPreconditionFailed), errors.Is(err, errObjectLocked):
+				continue
+			default:
+				return removed, fmt.Errorf("expire %q: %w", obj.ObjectName, err)
+			}
+			if err := s.recordLifecycleEvent(ctx, rule, lifecycleExpireObject, obj.ObjectName, "", ""); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+		startAfter = objects[len(objects)-1].ObjectName
+	}
+}
+
+// expireNoncurrentVersions는 다음 버전이 cutoff 이전에 만들어진(그만큼 오래 전에 이전 버전이 된) 버전을 지웁니다.
+// 최신 버전은 현재 객체이거나 삭제 마커이므로 남깁니다.
+func (s *StorageService) expireNoncurrentVersions(ctx context.Context, rule *ent.LifecycleRule, cutoff time.Time) (int, error) {
+	removed := 0
+	afterID := 0
+	for {
+		versions, err := s.repo.ListLifecycleVersions(ctx, repository.LifecycleVersionInput{
+			BucketName:    rule.BucketName,
+			Prefix:        rule.Prefix,
+			CreatedBefore: cutoff,
+			AfterID:       afterID,
+			Limit:         uploadCleanupBatch,
+		})
+		if err != nil {
+			return removed, err
+		}
+		if len(versions) == 0 {
+			return removed, nil
+		}
+
+		// id 순으로 오래된 버전부터 지우므로, 한 배치 안에서는 처음 읽은 이력으로 다음 버전을 찾아도 됩니다.
+		history := make(map[string][]*ent.ObjectVersion)
+		for _, v := range versions {
+			all, ok := history[v.ObjectName]
+			if !ok {
+				all, err = s.repo.ListObjectVersions(ctx, rule.BucketName, v.ObjectName)
+				if err != nil {
+					return removed, err
+				}
+				history[v.ObjectName] = all
+			}
+			if !supersededBefore(all, v.ID, cutoff) {
+				continue
+			}
+
+			deleted, err := s.expireVersion(ctx, v)
+			if err != nil {
+				return removed, fmt.Errorf("expire version %q of %q: %w", v.VersionID, v.ObjectName, err)
+			}
+			if !deleted {
+				continue
+			}
+			if err := s.recordLifecycleEvent(ctx, rule, lifecycleExpireVersion, v.ObjectName, v.VersionID, ""); err != nil {
+				return removed, err
+			}
+			removed++
+		}
+		afterID = versions[len(versions)-1].ID
+	}
+}
+
+// expireVersion은 키를 잠근 트랜잭션에서 버전 행과 백엔드 복사본을 함께 지웁니다.
+// 잠그지 않으면 같은 버전을 복원하는 요청이 지워지는 복사본을 읽을 수 있습니다. 이미 지워진 버전이면 false입니다.
+func (s *StorageService) expireVersion(ctx context.Context, v *ent.ObjectVersion) (bool, error) {
+	otx, err := s.repo.BeginObjectTx(ctx, v.BucketName, v.ObjectName)
+	if err != nil {
+		return false, fmt.Errorf("lock object: %w", err)
+	}
+	defer otx.Rollback()
+
+	if err := otx.DeleteVersion(ctx, v.ID); err != nil {
+		if ent.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	if v.StoragePath != "" {
+		if err := s.client.RemoveObject(ctx, v.BucketName, v.StoragePath, minio.RemoveObjectOptions{}); err != nil {
+			return false, fmt.Errorf("remove version: %w", err)
+		}
+	}
+	if err := otx.Commit(); err != nil {
+		return false, fmt.Errorf("delete version: %w", err)
+	}
+	return true, nil
+}
+
+// supersededBefore는 최신순 이력에서 id 버전 바로 다음(더 새) 버전이 cutoff 이전에 만들어졌는지 확인합니다.
+func supersededBefore(history []*ent.ObjectVersion, id int, cutoff time.Time) bool {
+	for i, v := range history {
+		if v.ID == id {
+			return i > 0 && history[i-1].CreatedAt.Before(cutoff)
+		}
+	}
+	return false
+}
+
+// abortIncompleteUploads는 cutoff 이전에 시작한 prefix 아래 멀티파트 업로드를 중단합니다.
+func (s *StorageService) abortIncompleteUploads(ctx context.Context, rule *ent.LifecycleRule, cutoff time.Time) (int, error) {
+	aborted := 0
+	for {
+		sessions, err := s.repo.ListLifecycleUploadSessions(ctx, rule.BucketName, rule.Prefix, cutoff, uploadCleanupBatch)
+		if err != nil {
+			return aborted, err
+		}
+		if len(sessions) == 0 {
+			return aborted, nil
+		}
+		for _, session := range sessions {
+			if err := s.abortUploadSession(ctx, session); err != nil {
+				return aborted, fmt.Errorf("abort upload %q: %w", session.UploadID, err)
+			}
+			if err := s.recordLifecycleEvent(ctx, rule, lifecycleAbortUpload, session.ObjectName, "", session.UploadID); err != nil {
+				return aborted, err
+			}
+			aborted++
+		}
+	}
+}
+
+func (s *StorageService) recordLifecycleEvent(ctx context.Context, rule *ent.LifecycleRule, action, objectName, versionID, uploadID string) error {
+	if err := s.repo.CreateLifecycleEvent(ctx, repository.LifecycleEventInput{
+		BucketName: rule.BucketName,
+		RuleID:     rule.RuleID,
+		Action:     action,
+		ObjectName: objectName,
+		VersionID:  versionID,
+		UploadID:   uploadID,
+	}); err != nil {
+		return fmt.Errorf("record lifecycle event: %w", err)
+	}
+	return nil
+}
+
+// lifecycleExpirationCutoff는 현재 객체 만료 기준 시각을 반환합니다. 만료를 쓰지 않거나 만료 날짜 전이면 false입니다.
+func lifecycleExpirationCutoff(rule *ent.LifecycleRule, now time.Time) (time.Time, bool) {
+	if rule.ExpirationDate != nil {
+		if now.Before(*rule.ExpirationDate) {
+			return time.Time{}, false
+		}
+		return now, true
+	}
+	if rule.ExpirationDays > 0 {
+		return now.AddDate(0, 0, -rule.ExpirationDays), true
+	}
+	return time.Time{}, false
+}
+
+// lifecycleRuleInputs는 요청 규칙을 검증해 저장할 형태로 바꿉니다.
+func lifecycleRuleInputs(rules []LifecycleRuleConfig) ([]repository.LifecycleRuleInput, error) {
+	if len(rules) == 0 {
+		return nil, errors.New("rules are required")
+	}
+	if len(rules) > maxLifecycleRules {
+		return nil, fmt.Errorf("cannot set more than %d lifecycle rules", maxLifecycleRules)
+	}
+
+	inputs := make([]repository.LifecycleRuleInput, 0, len(rules))
+	seen := make(map[string]struct{}, len(rules))
+	for _, rule := range rules {
+		id := strings.TrimSpace(rule.ID)
+		if id == "" {
+			return nil, errors.New("rule id is required")
+		}
+		if len(id) > maxLifecycleRuleIDSize {
+			return nil, fmt.Errorf("rule id exceeds %d bytes", maxLifecycleRuleIDSize)
+		}
+		if _, ok := seen[id]; ok {
+			return nil, fmt.Errorf("duplicate rule id %q", id)
+		}
+		seen[id] = struct{}{}
+
+		if rule.ExpirationDays < 0 || rule.NoncurrentExpirationDays < 0 || rule.AbortIncompleteUploadDays < 0 {
+			return nil, fmt.Errorf("rule %q: days must not be negative", id)
+		}
+		if rule.ExpirationDays > 0 && rule.ExpirationDate != nil {
+			return nil, fmt.Errorf("rule %q: expiration_days and expiration_date cannot be used together", id)
+		}
+		if rule.ExpirationDays == 0 && rule.ExpirationDate == nil && rule.NoncurrentExpirationDays == 0 && rule.AbortIncompleteUploadDays == 0 {
+			return nil, fmt.Errorf("rule %q: at least one action is required", id)
+		}
+		if len(rule.Tags) > 0 {
+			// 버전 이력과 업로드 세션에는 태그가 없으므로 태그 필터는 현재 객체 만료에만 씁니다.
+			if rule.NoncurrentExpirationDays > 0 || rule.AbortIncompleteUploadDays > 0 {
+				return nil, fmt.Errorf("rule %q: tags filter applies only to object expiration", id)
+			}
+			if err := validateTags(rule.Tags); err != nil {
+				return nil, fmt.Errorf("rule %q: %w", id, err)
+			}
+		}
+
+		enabled := true
+		if rule.Enabled != nil {
+			enabled = *rule.Enabled
+		}
+		var date *time.Time
+		if rule.ExpirationDate != nil {
+			d := rule.ExpirationDate.UTC()
+			date = &d
+		}
+		inputs = append(inputs, repository.LifecycleRuleInput{
+			RuleID:                    id,
+			Enabled:                   enabled,
+			Prefix:                    rule.Prefix,
+			Tags:                      rule.Tags,
+			ExpirationDays:            rule.ExpirationDays,
+			ExpirationDate:            date,
+			NoncurrentExpirationDays:  rule.NoncurrentExpirationDays,
+			AbortIncompleteUploadDays: rule.AbortIncompleteUploadDays,
+		})
+	}
+	return inputs, nil
+}
+
+func newBucketLifecycleResponse(bucketName string, rules []*ent.LifecycleRule) BucketLifecycleResponse {
+	resp := BucketLifecycleResponse{Bucket: bucketName, Rules: make([]LifecycleRuleConfig, 0, len(rules))}
+	for _, rule := range rules {
+		enabled := rule.Enabled
+		resp.Rules = append(resp.Rules, LifecycleRuleConfig{
+			ID:                        rule.RuleID,
+			Enabled:                   &enabled,
+			Prefix:                    rule.Prefix,
+			Tags:                      rule.Tags,
+			ExpirationDays:            rule.ExpirationDays,
+			ExpirationDate:            rule.ExpirationDate,
+			NoncurrentExpirationDays:  rule.NoncurrentExpirationDays,
+			AbortIncompleteUploadDays: rule.AbortIncompleteUploadDays,
+		})
+	}
+	return resp
+}
+
+// decodeEventCursor는 이벤트 목록 커서를 이전 페이지 마지막 기록의 id로 바꿉니다. 비어 있으면 0입니다.
+func decodeEventCursor(cursor string) (int, error) {
+	marker, err := decodeListCursor(cursor)
+	if err != nil || marker == "" {
+		return 0, err
+	}
+	id, err := strconv.Atoi(marker)
+	if err != nil || id <= 0 {
+		return 0, errors.New("invalid cursor")
+	}
+	return id, nil
+}
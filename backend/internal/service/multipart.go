@@ -0,0 +1,523 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"guiio/backend/ent"
+	httpctx "guiio/backend/internal/port/httpctx"
+	"guiio/backend/internal/repository"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/rs/zerolog"
+	"github.com/sphynx/config"
+)
+
+const (
+	maxPartNumber       = 10000
+	maxPartSize         = 5 << 30
+	uploadCleanupBatch  = 100
+	errCodeNoSuchUpload = "NoSuchUpload"
+)
+
+type InitiateMultipartUploadRequest struct {
+	Object      string            `json:"object"`
+	ContentType string            `json:"content_type,omitempty"`
+	Metadata    map[string]string `json:"metadata,omitempty"`
+}
+
+type MultipartUploadResponse struct {
+	UploadID  string    `json:"upload_id"`
+	Bucket    string    `json:"bucket"`
+	Object    string    `json:"object"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type UploadPartResponse struct {
+	PartNumber int    `json:"part_number"`
+	ETag       string `json:"etag"`
+	Size       int64  `json:"size"`
+}
+
+type PartInfo struct {
+	PartNumber   int       `json:"part_number"`
+	ETag         string    `json:"etag"`
+	Size         int64     `json:"size"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+type ListPartsResponse struct {
+	UploadID string     `json:"upload_id"`
+	Bucket   string     `json:"bucket"`
+	Object   string     `json:"object"`
+	Parts    []PartInfo `json:"parts"`
+}
+
+type CompletePartRequest struct {
+	PartNumber int    `json:"part_number"`
+	ETag       string `json:"etag"`
+}
+
+// CompleteMultipartUploadRequest의 Parts가 비어 있으면 기록된 모든 파트를 번호 순으로 합칩니다.
+type CompleteMultipartUploadRequest struct {
+	Parts []CompletePartRequest `json:"parts"`
+}
+
+type AbortMultipartUploadResponse struct {
+	Aborted string `json:"aborted"`
+}
+
+func (s *StorageService) InitiateMultipartUpload(ctx httpctx.Context) {
+	bucketName := strings.TrimSpace(ctx.Param("bucketName"))
+	if err := validateBucketName(bucketName); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if s.repo == nil {
+		ctx.JSON(http.StatusNotImplemented, ErrorResponse{Error: "multipart upload requires object repository"})
+		return
+	}
+
+	var req InitiateMultipartUploadRequest
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+	objectName := strings.TrimSpace(req.Object)
+	if err := validateObjectName(objectName); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	contentType := strings.TrimSpace(req.ContentType)
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	reqCtx := ctx.Context()
+
+	exists, err := s.client.BucketExists(reqCtx, bucketName)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("check bucket failed: %v", err)})
+		return
+	}
+	if !exists {
+		ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "bucket not found"})
+		return
+	}
+
+	// content-addressable 버킷은 완료해야 본문 해시를 알 수 있으므로 임시 키에 합친 뒤 blob으로 옮깁니다.
+	storageKey := s.newDataKey(objectName)
+	cas, err := s.contentAddressable(reqCtx, bucketName)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if cas {
+		storageKey = stagingKey()
+	}
+	backendID, err := s.client.NewMultipartUpload(reqCtx, bucketName, storageKey, minio.PutObjectOptions{ContentType: contentType})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("initiate upload failed: %v", err)})
+		return
+	}
+
+	session, err := s.repo.CreateUploadSession(reqCtx, repository.UploadSessionInput{
+		UploadID:        newRandomID(),
+		BucketName:      bucketName,
+		ObjectName:      objectName,
+		StoragePath:     storageKey,
+		BackendUploadID: backendID,
+		ContentType:     contentType,
+		Metadata:        req.Metadata,
+	})
+	if err != nil {
+		_ = s.client.AbortMultipartUpload(reqCtx, bucketName, storageKey, backendID)
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("save upload session failed: %v", err)})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, MultipartUploadResponse{
+		UploadID:  session.UploadID,
+		Bucket:    bucketName,
+		Object:    objectName,
+		CreatedAt: session.CreatedAt,
+	})
+}
+
+// UploadPart는 파트 하나를 저장합니다. 같은 번호로 다시 올리면 이전 파트를 대체하므로
+// 실패한 파트만 재시도할 수 있고, 서로 다른 파트는 병렬로 올려도 됩니다.
+func (s *StorageService) UploadPart(ctx httpctx.Context) {
+	session, ok := s.lookupUploadSession(ctx)
+	if !ok {
+		return
+	}
+
+	partNumber, err := strconv.Atoi(ctx.Param("partNumber"))
+	if err != nil || partNumber < 1 || partNumber > maxPartNumber {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("part number must be between 1 and %d", maxPartNumber)})
+		return
+	}
+
+	r := ctx.Request()
+	if r == nil || r.Body == nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "request body is required"})
+		return
+	}
+	defer r.Body.Close()
+
+	if r.ContentLength < 0 {
+		ctx.JSON(http.StatusLengthRequired, ErrorResponse{Error: "Content-Length is required for parts"})
+		return
+	}
+	if r.ContentLength > maxPartSize {
+		ctx.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Error: "part exceeds maximum part size"})
+		return
+	}
+
+	reqCtx := ctx.Context()
+
+	// 파트마다 크기 제한 안이어도 합치면 object_max_upload_size를 넘을 수 있으므로 지금까지 올린 파트와 합쳐 확인합니다.
+	// 같은 번호를 다시 올리면 이전 파트를 대체하므로 그 크기는 빼고 셉니다. 동시에 올린 파트는 완료할 때 다시 확인합니다.
+	if maxSize := config.Get[int64]("object_max_upload_size"); maxSize > 0 {
+		recorded, err := s.repo.ListUploadParts(reqCtx, session.ID)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("list parts failed: %v", err)})
+			return
+		}
+		total := r.ContentLength
+		for _, p := range recorded {
+			if p.PartNumber != partNumber {
+				total += p.Size
+			}
+		}
+		if total > maxSize {
+			ctx.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Error: errObjectTooLarge.Error()})
+			return
+		}
+	}
+
+	// 체크섬 헤더는 이 파트 본문에 대한 값으로 보고 확인만 합니다. 전체 체크섬은 content-addressable 버킷에서 완료할 때만 계산합니다.
+	checksums, err := checksumsFromHeader(r.Header, true)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	body := newChecksumReader(r.Body, r.ContentLength, checksums)
+	if r.ContentLength == 0 {
+		if err := body.verify(); err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+	}
+	part, err := s.client.PutObjectPart(reqCtx, session.BucketName, session.StoragePath, session.BackendUploadID, partNumber, body, r.ContentLength)
+	if err != nil {
+		if errors.Is(err, errChecksumMismatch) {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+		if minio.ToErrorResponse(err).Code == errCodeNoSuchUpload {
+			ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "upload not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("upload part failed: %v", err)})
+		return
+	}
+
+	if _, err := s.repo.UpsertUploadPart(reqCtx, repository.UploadPartInput{
+		SessionID:  session.ID,
+		PartNumber: partNumber,
+		ETag:       part.ETag,
+		Size:       part.Size,
+	}); err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("save upload part failed: %v", err)})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, UploadPartResponse{
+		PartNumber: partNumber,
+		ETag:       part.ETag,
+		Size:       part.Size,
+	})
+}
+
+func (s *StorageService) ListParts(ctx httpctx.Context) {
+	session, ok := s.lookupUploadSession(ctx)
+	if !ok {
+		return
+	}
+
+	parts, err := s.repo.ListUploadParts(ctx.Context(), session.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("list parts failed: %v", err)})
+		return
+	}
+
+	resp := ListPartsResponse{
+		UploadID: session.UploadID,
+		Bucket:   session.BucketName,
+		Object:   session.ObjectName,
+		Parts:    make([]PartInfo, 0, len(parts)),
+	}
+	for _, p := range parts {
+		resp.Parts = append(resp.Parts, PartInfo{
+			PartNumber:   p.PartNumber,
+			ETag:         p.Etag,
+			Size:         p.Size,
+			LastModified: p.UpdatedAt,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+func (s *StorageService) CompleteMultipartUpload(ctx httpctx.Context) {
+	session, ok := s.lookupUploadSession(ctx)
+	if !ok {
+		return
+	}
+
+	var req CompleteMultipartUploadRequest
+	if err := ctx.Bind(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	reqCtx := ctx.Context()
+
+	recorded, err := s.repo.ListUploadParts(reqCtx, session.ID)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("list parts failed: %v", err)})
+		return
+	}
+
+	parts, err := selectCompleteParts(recorded, req.Parts)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	completeParts := make([]minio.CompletePart, 0, len(parts))
+	var size int64
+	for _, p := range parts {
+		completeParts = append(completeParts, minio.CompletePart{PartNumber: p.PartNumber, ETag: p.Etag})
+		size += p.Size
+	}
+	if maxSize := config.Get[int64]("object_max_upload_size"); maxSize > 0 && size > maxSize {
+		ctx.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Error: errObjectTooLarge.Error()})
+		return
+	}
+
+	uinfo, err := s.client.CompleteMultipartUpload(reqCtx, session.BucketName, session.StoragePath, session.BackendUploadID, completeParts, minio.PutObjectOptions{ContentType: session.ContentType})
+	if err != nil {
+		switch minio.ToErrorResponse(err).Code {
+		case errCodeNoSuchUpload:
+			ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "upload not found"})
+		case "EntityTooSmall", "InvalidPart", "InvalidPartOrder":
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("complete upload failed: %v", err)})
+		default:
+			ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("complete upload failed: %v", err)})
+		}
+		return
+	}
+
+	record := repository.ObjectUpsertInput{
+		BucketName:        session.BucketName,
+		ObjectName:        session.ObjectName,
+		StoragePath:       session.StoragePath,
+		ContentType:       session.ContentType,
+		ContentTypeSource: declaredContentTypeSource(session.ContentType),
+		Size:              size,
+		ETag:              uinfo.ETag,
+		Metadata:          session.Metadata,
+	}
+	// 합친 본문은 세션마다 고유한 키에 있으므로, 키는 잠긴 객체인지 확인하고 행을 바꿀 때만 잠급니다.
+	bypass := governanceBypass(ctx.Request())
+	check := func(otx repository.ObjectTx) error {
+		return checkOverwrite(otx, bypass)
+	}
+	var src minio.CopySrcOptions
+	if isStagingKey(session.StoragePath) {
+		defer s.client.RemoveObject(context.WithoutCancel(reqCtx), session.BucketName, session.StoragePath, minio.RemoveObjectOptions{})
+		if err := s.hashStagedUpload(reqCtx, &record); err != nil {
+			ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+			return
+		}
+		src = minio.CopySrcOptions{Bucket: session.BucketName, Object: session.StoragePath}
+	}
+	versionID, err := s.commitObject(reqCtx, &record, src, check)
+	if err != nil {
+		if errors.Is(err, errObjectLocked) {
+			ctx.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	// 세션 행 정리는 실패해도 객체는 이미 완성되었으므로, 남은 행은 정리 작업이 지웁니다.
+	_ = s.repo.DeleteUploadSession(reqCtx, session.ID)
+
+	ctx.JSON(http.StatusOK, UploadObjectResponse{
+		Bucket:         session.BucketName,
+		Object:         session.ObjectName,
+		ContentType:    session.ContentType,
+		Size:           size,
+		ETag:           record.ETag,
+		StoragePath:    record.StoragePath,
+		VersionID:      versionID,
+		ChecksumSHA256: record.ChecksumSHA256,
+		ChecksumCRC32C: record.ChecksumCRC32C,
+	})
+}
+
+func (s *StorageService) AbortMultipartUpload(ctx httpctx.Context) {
+	session, ok := s.lookupUploadSession(ctx)
+	if !ok {
+		return
+	}
+
+	if err := s.abortUploadSession(ctx.Context(), session); err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("abort upload failed: %v", err)})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, AbortMultipartUploadResponse{Aborted: session.UploadID})
+}
+
+// RunUploadCleaner는 multipart_cleanup_interval_minutes마다 multipart_max_age_hours 동안
+// 파트가 올라오지 않은 업로드 세션을 중단합니다. ctx가 끝나면 반환합니다.
+func (s *StorageService) RunUploadCleaner(ctx context.Context, log *zerolog.Logger) {
+	interval := time.Duration(config.Get[int]("multipart_cleanup_interval_minutes")) * time.Minute
+	maxAge := time.Duration(config.Get[int]("multipart_max_age_hours")) * time.Hour
+	if s.repo == nil || interval <= 0 || maxAge <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		n, err := s.CleanupExpiredUploads(ctx, time.Now().Add(-maxAge))
+		if err != nil {
+			log.Error().Err(err).Msg("multipart upload cleanup failed")
+			continue
+		}
+		if n > 0 {
+			log.Info().Msgf("aborted %d expired multipart uploads", n)
+		}
+	}
+}
+
+// CleanupExpiredUploads는 idleSince 이후 활동이 없는 세션을 모두 중단하고 중단한 개수를 반환합니다.
+func (s *StorageService) CleanupExpiredUploads(ctx context.Context, idleSince time.Time) (int, error) {
+	aborted := 0
+	for {
+		sessions, err := s.repo.ListExpiredUploadSessions(ctx, idleSince, uploadCleanupBatch)
+		if err != nil {
+			return aborted, err
+		}
+		if len(sessions) == 0 {
+			return aborted, nil
+		}
+		for _, session := range sessions {
+			if err := s.abortUploadSession(ctx, session); err != nil {
+				return aborted, err
+			}
+			aborted++
+		}
+	}
+}
+
+// abortUploadSession은 백엔드 업로드를 중단하고 세션/파트 행을 지웁니다.
+// 백엔드에 이미 없는 업로드(완료 후 행만 남은 경우 포함)는 성공으로 봅니다.
+func (s *StorageService) abortUploadSession(ctx context.Context, session *ent.UploadSession) error {
+	if err := s.client.AbortMultipartUpload(ctx, session.BucketName, session.StoragePath, session.BackendUploadID); err != nil {
+		if minio.ToErrorResponse(err).Code != errCodeNoSuchUpload {
+			return err
+		}
+	}
+	return s.repo.DeleteUploadSession(ctx, session.ID)
+}
+
+// lookupUploadSession은 bucketName/uploadId 경로 파라미터로 세션을 찾고 실패 시 에러 응답을 작성합니다.
+func (s *StorageService) lookupUploadSession(ctx httpctx.Context) (*ent.UploadSession, bool) {
+	bucketName := strings.TrimSpace(ctx.Param("bucketName"))
+	if err := validateBucketName(bucketName); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return nil, false
+	}
+	if s.repo == nil {
+		ctx.JSON(http.StatusNotImplemented, ErrorResponse{Error: "multipart upload requires object repository"})
+		return nil, false
+	}
+
+	uploadID := strings.TrimSpace(ctx.Param("uploadId"))
+	session, err := s.repo.GetUploadSession(ctx.Context(), bucketName, uploadID)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "upload not found"})
+			return nil, false
+		}
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("get upload session failed: %v", err)})
+		return nil, false
+	}
+	return session, true
+}
+
+// selectCompleteParts는 완료 요청에 지정된 파트를 기록된 파트와 대조합니다.
+// 요청이 비어 있으면 기록된 파트 전체를 사용합니다.
+func selectCompleteParts(recorded []*ent.UploadPart, requested []CompletePartRequest) ([]*ent.UploadPart, error) {
+	if len(recorded) == 0 {
+		return nil, errors.New("no parts uploaded")
+	}
+
+	byNumber := make(map[int]*ent.UploadPart, len(recorded))
+	for _, p := range recorded {
+		byNumber[p.PartNumber] = p
+	}
+
+	if len(requested) == 0 {
+		parts := append([]*ent.UploadPart(nil), recorded...)
+		sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+		return parts, nil
+	}
+
+	parts := make([]*ent.UploadPart, 0, len(requested))
+	for i, rp := range requested {
+		if i > 0 && rp.PartNumber <= requested[i-1].PartNumber {
+			return nil, errors.New("parts must be in ascending order without duplicates")
+		}
+		p, ok := byNumber[rp.PartNumber]
+		if !ok {
+			return nil, fmt.Errorf("part %d was not uploaded", rp.PartNumber)
+		}
+		if rp.ETag != "" && strings.Trim(rp.ETag, "\"") != strings.Trim(p.Etag, "\"") {
+			return nil, fmt.Errorf("part %d etag does not match", rp.PartNumber)
+		}
+		parts = append(parts, p)
+	}
+	return parts, nil
+}
+
+// newRandomID는 업로드/버전 ID로 쓰는 128비트 hex 문자열을 만듭니다.
+func newRandomID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,350 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"guiio/backend/ent"
+	httpctx "guiio/backend/internal/port/httpctx"
+	"guiio/backend/internal/repository"
+
+	"github.com/minio/minio-go/v7"
+)
+
+const (
+	metadataDirectiveCopy    = "COPY"
+	metadataDirectiveReplace = "REPLACE"
+)
+
+var (
+	errSameCopyTarget    = errors.New("source and destination are the same object")
+	errMoveSourceChanged = errors.New("source object changed during move")
+)
+
+// CopyObjectRequest는 복사/이동 대상입니다. DestinationBucket이 비어 있으면 원본 버킷을 사용합니다.
+// MetadataDirective가 REPLACE이면 원본 메타데이터 대신 Metadata와 ContentType을 저장합니다.
+type CopyObjectRequest struct {
+	DestinationBucket string            `json:"destination_bucket,omitempty"`
+	DestinationKey    string            `json:"destination_key"`
+	MetadataDirective string            `json:"metadata_directive,omitempty"`
+	ContentType       string            `json:"content_type,omitempty"`
+	Metadata          map[string]string `json:"metadata,omitempty"`
+}
+
+type CopyObjectResponse struct {
+	SourceBucket string            `json:"source_bucket"`
+	SourceKey    string            `json:"source_key"`
+	Bucket       string            `json:"bucket"`
+	Key          string            `json:"key"`
+	ContentType  string            `json:"content_type"`
+	Size         int64             `json:"size"`
+	ETag         string            `json:"etag"`
+	Metadata     map[string]string `json:"metadata"`
+	VersionID    string            `json:"version_id,omitempty"`
+	Moved        bool              `json:"moved"`
+}
+
+// CopyObject는 객체를 내려받지 않고 스토리지 백엔드 안에서 복사합니다.
+func (s *StorageService) CopyObject(ctx httpctx.Context) {
+	s.copyObjectHandler(ctx, false)
+}
+
+// MoveObject는 복사 후 원본을 삭제합니다.
+func (s *StorageService) MoveObject(ctx httpctx.Context) {
+	s.copyObjectHandler(ctx, true)
+}
+
+func (s *StorageService) copyObjectHandler(ctx httpctx.Context, move bool) {
+	bucketName := strings.TrimSpace(ctx.Param("bucketName"))
+	objectName := strings.TrimSpace(ctx.Param("objectName"))
+	if err := validateBucketName(bucketName); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err := validateObjectName(objectName); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var req CopyObjectRequest
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+	dstBucket := strings.TrimSpace(req.DestinationBucket)
+	if dstBucket == "" {
+		dstBucket = bucketName
+	}
+	if err := validateBucketName(dstBucket); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	dstKey := strings.TrimSpace(req.DestinationKey)
+	if err := validateObjectName(dstKey); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	directive := strings.ToUpper(strings.TrimSpace(req.MetadataDirective))
+	if directive == "" {
+		directive = metadataDirectiveCopy
+	}
+	if directive != metadataDirectiveCopy && directive != metadataDirectiveReplace {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "metadata_directive must be COPY or REPLACE"})
+		return
+	}
+
+	bypass := governanceBypass(ctx.Request())
+	reqCtx := ctx.Context()
+	var source *moveSource
+	if move && s.repo != nil {
+		source = &moveSource{bucketName: bucketName, objectName: objectName, bypass: bypass}
+		defer source.release()
+		// 반대 방향 이동끼리 교착되지 않도록 원본과 대상 키는 항상 같은 순서로 잠급니다.
+		// 원본이 먼저이면 여기서 잠그고, 아니면 대상 키를 잠근 copyObject 안에서 잠급니다.
+		if lockSourceFirst(bucketName, objectName, dstBucket, dstKey) {
+			if err := source.lock(reqCtx, s.repo); err != nil {
+				ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+				return
+			}
+		}
+	}
+
+	resp, err := s.copyObject(reqCtx, bucketName, objectName, dstBucket, dstKey, directive, req, bypass, source)
+	if err != nil {
+		switch {
+		case errors.Is(err, errObjectLocked):
+			ctx.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+		case errors.Is(err, errObjectNotFound), errors.Is(err, errBucketNotFound):
+			ctx.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+		case errors.Is(err, errSameCopyTarget):
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		case errors.Is(err, errMoveSourceChanged):
+			ctx.JSON(http.StatusConflict, ErrorResponse{Error: err.Error()})
+		default:
+			ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		}
+		return
+	}
+
+	if move {
+		if source != nil {
+			err = s.removeMoveSource(reqCtx, source)
+		} else {
+			err = s.deleteObject(reqCtx, bucketName, objectName, writeCondition{BypassGovernance: bypass}, true)
+		}
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("object copied but delete source failed: %v", err)})
+			return
+		}
+		resp.Moved = true
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// moveSource는 이동하는 동안 잡아 두는 원본 키 잠금입니다. 대상 행을 커밋하기 전에 원본을 지울 수 있는지 잠근 채 확인하고,
+// 커밋한 뒤에도 잠금을 놓지 않고 원본을 지우므로 그 사이 다른 요청이 원본을 바꾸거나 잠그지 못합니다.
+type moveSource struct {
+	bucketName, objectName string
+	bypass                 bool
+	otx                    repository.ObjectTx
+}
+
+func (m *moveSource) lock(ctx context.Context, repo repository.ObjectRepository) error {
+	if m.otx != nil {
+		return nil
+	}
+	otx, err := repo.BeginObjectTx(ctx, m.bucketName, m.objectName)
+	if err != nil {
+		return fmt.Errorf("lock object failed: %w", err)
+	}
+	m.otx = otx
+	return nil
+}
+
+// verify는 잠근 원본이 복사한 본문(storageKey) 그대로이고 지울 수 있는지 확인합니다.
+func (m *moveSource) verify(storageKey string) error {
+	current := m.otx.Current()
+	if current == nil {
+		// objects 행이 없는 객체는 백엔드 객체 키에서 복사했습니다.
+		return nil
+	}
+	if current.DeletedAt != nil {
+		return errObjectNotFound
+	}
+	if normalizeStoragePath(m.bucketName, current.StoragePath, encodeObjectKey(m.objectName)) != storageKey {
+		return errMoveSourceChanged
+	}
+	return checkObjectLock(current, m.bypass)
+}
+
+func (m *moveSource) release() {
+	if m.otx != nil {
+		m.otx.Rollback()
+	}
+}
+
+// lockSourceFirst는 이동할 때 원본 키를 대상 키보다 먼저 잠가야 하는지 반환합니다. 키는 (버킷, 객체 이름) 순서로 잠급니다.
+func lockSourceFirst(srcBucket, srcName, dstBucket, dstName string) bool {
+	if srcBucket != dstBucket {
+		return srcBucket < dstBucket
+	}
+	return srcName < dstName
+}
+
+// removeMoveSource는 copyObject가 확인한 원본을 잠금을 놓지 않은 채 지웁니다.
+// 행을 먼저 지워 커밋하고 본문은 그 뒤에 정리하므로, 백엔드 삭제가 실패해도 원본이 남지 않습니다.
+func (s *StorageService) removeMoveSource(ctx context.Context, m *moveSource) error {
+	current := m.otx.Current()
+	if current != nil {
+		if err := m.otx.Delete(ctx); err != nil {
+			return fmt.Errorf("delete object metadata: %w", err)
+		}
+	}
+	if err := s.recordDeleteMarker(ctx, m.otx, m.bucketName, m.objectName); err != nil {
+		return err
+	}
+	if err := m.otx.Commit(); err != nil {
+		return fmt.Errorf("delete object metadata: %w", err)
+	}
+
+	storageKey := encodeObjectKey(m.objectName)
+	if current != nil {
+		storageKey = normalizeStoragePath(m.bucketName, current.StoragePath, storageKey)
+	}
+	s.releaseStorage(ctx, m.bucketName, storageKey)
+	// 같은 키의 휴지통 항목은 남으므로 그 파생 이미지는 지우지 않습니다.
+	if trashed := m.otx.Trashed(); trashed != nil {
+		s.pruneDerivatives(ctx, m.bucketName, m.objectName, trashed.Etag)
+	} else {
+		s.removeDerivatives(ctx, m.bucketName, m.objectName)
+	}
+	return nil
+}
+
+// copyObject는 백엔드 복사 후 대상 objects/object_metadata 행을 만들고 태그도 옮깁니다.
+// COPY는 원본 메타데이터 행을 그대로 복제하고, REPLACE는 요청 값으로 대체합니다.
+// move가 있으면 대상 키를 잠근 채 원본 키도 잠가(아직 잠그지 않았으면) 원본을 지울 수 있는지 확인한 뒤에만 대상 행을 커밋합니다.
+func (s *StorageService) copyObject(ctx context.Context, srcBucket, srcName, dstBucket, dstName, directive string, req CopyObjectRequest, bypass bool, move *moveSource) (*CopyObjectResponse, error) {
+	if srcBucket == dstBucket && srcName == dstName {
+		return nil, errSameCopyTarget
+	}
+
+	head, err := s.statObject(ctx, srcBucket, srcName)
+	if err != nil {
+		return nil, err
+	}
+	exists, err := s.client.BucketExists(ctx, dstBucket)
+	if err != nil {
+		return nil, fmt.Errorf("check bucket failed: %w", err)
+	}
+	if !exists {
+		return nil, errBucketNotFound
+	}
+
+	contentType := head.ContentType
+	contentTypeSource := head.ContentTypeSource
+	metadata := head.Metadata
+	dst := minio.CopyDestOptions{Bucket: dstBucket, Object: s.newDataKey(dstName)}
+	if directive == metadataDirectiveReplace {
+		if ct := strings.TrimSpace(req.ContentType); ct != "" {
+			contentType = ct
+			contentTypeSource = declaredContentTypeSource(ct)
+		}
+		metadata = make(map[string]string, len(req.Metadata))
+		for k, v := range req.Metadata {
+			metadata[strings.ToLower(k)] = v
+		}
+		dst.ReplaceMetadata = true
+		dst.UserMetadata = map[string]string{"Content-Type": contentType}
+		for k, v := range metadata {
+			dst.UserMetadata[k] = v
+		}
+	}
+
+	record := repository.ObjectUpsertInput{
+		BucketName:        dstBucket,
+		ObjectName:        dstName,
+		StoragePath:       dst.Object,
+		ContentType:       contentType,
+		ContentTypeSource: contentTypeSource,
+		Size:              head.Size,
+		Metadata:          metadata,
+		// 백엔드 복사는 본문을 그대로 옮기므로 원본 체크섬을 이어받습니다.
+		ChecksumSHA256: head.ChecksumSHA256,
+		ChecksumCRC32C: head.ChecksumCRC32C,
+	}
+	// 본문은 대상 키를 잠그지 않고 고유 키로 복사하고, 잠근 동안에는 Object Lock 확인과 행 교체만 합니다.
+	check := func(otx repository.ObjectTx) error {
+		if err := checkOverwrite(otx, bypass); err != nil {
+			return err
+		}
+		if move == nil {
+			return nil
+		}
+		if err := move.lock(ctx, s.repo); err != nil {
+			return err
+		}
+		return move.verify(head.StorageKey)
+	}
+	if s.repo == nil {
+		if err := check(nil); err != nil {
+			return nil, err
+		}
+	}
+
+	// content-addressable 대상은 본문을 복사하지 않고 원본과 같은 blob을 가리킵니다.
+	src := minio.CopySrcOptions{Bucket: storageBucket(srcBucket, head.StorageKey), Object: head.StorageKey}
+	shared, err := s.shareBlob(ctx, head, &record)
+	if err != nil {
+		return nil, err
+	}
+	if !shared {
+		uinfo, cerr := s.copyStorage(ctx, dst, src, head.Size)
+		if cerr != nil {
+			return nil, fmt.Errorf("copy failed: %w", cerr)
+		}
+		if uinfo.Size != 0 {
+			record.Size = uinfo.Size
+		}
+		record.ETag = uinfo.ETag
+		src = minio.CopySrcOptions{}
+	}
+	versionID, err := s.commitObject(ctx, &record, src, check)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.copyObjectTags(ctx, srcBucket, srcName, dstBucket, dstName); err != nil {
+		return nil, err
+	}
+
+	return &CopyObjectResponse{
+		SourceBucket: srcBucket,
+		SourceKey:    srcName,
+		Bucket:       dstBucket,
+		Key:          dstName,
+		ContentType:  contentType,
+		Size:         record.Size,
+		ETag:         record.ETag,
+		Metadata:     metadata,
+		VersionID:    versionID,
+	}, nil
+}
+
+// copyObjectTags는 원본 태그로 대상 태그를 대체합니다. 원본이 repository에 없으면 대상 태그를 지웁니다.
+func (s *StorageService) copyObjectTags(ctx context.Context, srcBucket, srcName, dstBucket, dstName string) error {
+	if s.repo == nil {
+		return nil
+	}
+	tags, err := s.repo.GetObjectTags(ctx, srcBucket, srcName)
+	if err != nil && !ent.IsNotFound(err) {
+		return fmt.Errorf("get tags failed: %w", err)
+	}
+	if err := s.repo.PutObjectTags(ctx, dstBucket, dstName, tags); err != nil {
+		return fmt.Errorf("copy tags failed: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,252 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	"guiio/backend/ent"
+	httpctx "guiio/backend/internal/port/httpctx"
+	"guiio/backend/internal/repository"
+
+	"github.com/minio/minio-go/v7"
+)
+
+const (
+	maxDeleteObjects   = 1000
+	deleteObjectWorker = 8
+)
+
+var errObjectNotFound = errors.New("object not found")
+
+type DeleteObjectResponse struct {
+	Bucket  string `json:"bucket"`
+	Deleted string `json:"deleted"`
+}
+
+type DeleteObjectsRequest struct {
+	Keys []string `json:"keys"`
+}
+
+type DeleteObjectResult struct {
+	Key     string `json:"key"`
+	Deleted bool   `json:"deleted"`
+	Error   string `json:"error,omitempty"`
+}
+
+type DeleteObjectsResponse struct {
+	Bucket  string               `json:"bucket"`
+	Results []DeleteObjectResult `json:"results"`
+}
+
+func (s *StorageService) DeleteObject(ctx httpctx.Context) {
+	bucketName := strings.TrimSpace(ctx.Param("bucketName"))
+	objectName := strings.TrimSpace(ctx.Param("objectName"))
+	if err := validateBucketName(bucketName); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err := validateObjectName(objectName); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err := s.deleteObject(ctx.Context(), bucketName, objectName, writeConditionFromRequest(ctx.Request()), false); err != nil {
+		if errors.Is(err, errObjectNotFound) {
+			ctx.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+			return
+		}
+		if errors.Is(err, errPreconditionFailed) {
+			ctx.JSON(http.StatusPreconditionFailed, ErrorResponse{Error: err.Error()})
+			return
+		}
+		if errors.Is(err, errObjectLocked) {
+			ctx.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("delete object failed: %v", err)})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, DeleteObjectResponse{Bucket: bucketName, Deleted: objectName})
+}
+
+func (s *StorageService) DeleteObjects(ctx httpctx.Context) {
+	bucketName := strings.TrimSpace(ctx.Param("bucketName"))
+	if err := validateBucketName(bucketName); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var req DeleteObjectsRequest
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	keys := make([]string, 0, len(req.Keys))
+	seen := make(map[string]struct{}, len(req.Keys))
+	for _, k := range req.Keys {
+		k = strings.TrimSpace(k)
+		if _, ok := seen[k]; ok {
+			continue
+		}
+		seen[k] = struct{}{}
+		keys = append(keys, k)
+	}
+
+	if len(keys) == 0 {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "keys are required"})
+		return
+	}
+	if len(keys) > maxDeleteObjects {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("cannot delete more than %d objects at once", maxDeleteObjects)})
+		return
+	}
+
+	reqCtx := ctx.Context()
+	cond := writeCondition{BypassGovernance: governanceBypass(ctx.Request())}
+	results := make([]DeleteObjectResult, len(keys))
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < deleteObjectWorker && w < len(keys); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				results[i] = DeleteObjectResult{Key: keys[i]}
+				if err := validateObjectName(keys[i]); err != nil {
+					results[i].Error = err.Error()
+					continue
+				}
+				if err := s.deleteObject(reqCtx, bucketName, keys[i], cond, false); err != nil {
+					results[i].Error = err.Error()
+					continue
+				}
+				results[i].Deleted = true
+			}
+		}()
+	}
+	for i := range keys {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	ctx.JSON(http.StatusOK, DeleteObjectsResponse{Bucket: bucketName, Results: results})
+}
+
+// deleteObject는 objects 행이 있는 객체를 휴지통으로 옮기고(trash_retention_hours > 0),
+// 그렇지 않으면 백엔드 객체를 지운 뒤 행을 삭제합니다. permanent이면 휴지통을 거치지 않습니다.
+// repository가 있으면 키를 잠근 트랜잭션 안에서 조건 확인과 행 변경을 함께 처리하고,
+// 버전 관리 중인 버킷이면 이전 버전은 남긴 채 삭제 마커를 쌓습니다.
+func (s *StorageService) deleteObject(ctx context.Context, bucketName, objectName string, cond writeCondition, permanent bool) error {
+	storageKey := encodeObjectKey(objectName)
+
+	var otx repository.ObjectTx
+	if s.repo != nil {
+		var err error
+		otx, err = s.repo.BeginObjectTx(ctx, bucketName, objectName)
+		if err != nil {
+			return fmt.Errorf("get object metadata: %w", err)
+		}
+		defer otx.Rollback()
+	}
+
+	var current *ent.Object
+	if otx != nil {
+		current = otx.Current()
+	}
+
+	etag := ""
+	if current != nil {
+		// 휴지통에 있는 객체는 백엔드에 남아 있어도 없는 것으로 봅니다.
+		if current.DeletedAt != nil {
+			return errObjectNotFound
+		}
+		// 휴지통도 보존 기간이 지나면 비워지므로, 잠긴 객체는 휴지통으로도 옮기지 않습니다.
+		if err := checkObjectLock(current, cond.BypassGovernance); err != nil {
+			return err
+		}
+		storageKey = normalizeStoragePath(bucketName, current.StoragePath, storageKey)
+		etag = current.Etag
+	} else {
+		info, err := s.client.StatObject(ctx, bucketName, storageKey, minio.StatObjectOptions{})
+		if err != nil {
+			if isNoSuchKey(err) {
+				return errObjectNotFound
+			}
+			return fmt.Errorf("stat object: %w", err)
+		}
+		etag = info.ETag
+	}
+	if err := cond.check(true, etag); err != nil {
+		return err
+	}
+
+	if current != nil && !permanent && trashRetention() > 0 {
+		// 키마다 휴지통 항목은 하나이므로, 전에 휴지통으로 옮긴 같은 키의 객체는 이번 항목으로 대체하고 영구 삭제합니다.
+		replaced := otx.Trashed()
+		if replaced != nil {
+			if err := otx.DeleteTrashed(ctx); err != nil {
+				return fmt.Errorf("replace trashed object: %w", err)
+			}
+		}
+		if err := otx.Trash(ctx); err != nil {
+			return fmt.Errorf("move object to trash: %w", err)
+		}
+		if err := s.recordDeleteMarker(ctx, otx, bucketName, objectName); err != nil {
+			return err
+		}
+		if err := otx.Commit(); err != nil {
+			return fmt.Errorf("move object to trash: %w", err)
+		}
+		if replaced != nil {
+			s.releaseStorage(ctx, bucketName, normalizeStoragePath(bucketName, replaced.StoragePath, encodeObjectKey(objectName)))
+			s.pruneDerivatives(ctx, bucketName, objectName, current.Etag)
+		}
+		return nil
+	}
+
+	// blob은 다른 객체도 가리킬 수 있으므로 행을 지워 참조를 뺀 뒤 남은 참조가 없을 때만 지웁니다.
+	shared := isBlobKey(storageKey)
+	if !shared {
+		if err := s.client.RemoveObject(ctx, bucketName, storageKey, minio.RemoveObjectOptions{}); err != nil {
+			return fmt.Errorf("remove object: %w", err)
+		}
+	}
+	// 같은 키의 휴지통 항목은 남으므로 그 파생 이미지는 지우지 않습니다.
+	if trashed := trashedObject(otx); trashed != nil {
+		s.pruneDerivatives(ctx, bucketName, objectName, trashed.Etag)
+	} else {
+		s.removeDerivatives(ctx, bucketName, objectName)
+	}
+
+	if current != nil {
+		if err := otx.Delete(ctx); err != nil {
+			return fmt.Errorf("delete object metadata: %w", err)
+		}
+	}
+	if err := s.recordDeleteMarker(ctx, otx, bucketName, objectName); err != nil {
+		return err
+	}
+	if otx != nil {
+		if err := otx.Commit(); err != nil {
+			return fmt.Errorf("delete object metadata: %w", err)
+		}
+	}
+	if current != nil && shared {
+		s.releaseStorage(ctx, bucketName, storageKey)
+	}
+	return nil
+}
+
+// isNoSuchKey는 백엔드 에러가 키가 없다는 뜻인지 확인합니다. HEAD 요청은 본문이 없어 Code 대신 404 상태만 올 수 있습니다.
+func isNoSuchKey(err error) bool {
+	resp := minio.ToErrorResponse(err)
+	return resp.Code == "NoSuchKey" || resp.Code == "NotFound" || resp.StatusCode == http.StatusNotFound
+}
@@ -0,0 +1,297 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"guiio/backend/ent"
+	httpctx "guiio/backend/internal/port/httpctx"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/sphynx/config"
+)
+
+const metaHeaderPrefix = "X-Guiio-Meta-"
+
+// objectHead는 repository 또는 스토리지 백엔드에서 조회한 객체 속성입니다.
+type objectHead struct {
+	StorageKey  string
+	ContentType string
+	// ContentTypeSource는 repository에 기록된 객체만 채워집니다.
+	ContentTypeSource string
+	Size              int64
+	ETag              string
+	LastModified      time.Time
+	Metadata          map[string]string
+	// CacheControl이 비어 있으면 object_cache_control 설정값을 사용합니다.
+	CacheControl     string
+	MetadataRevision int
+	// VersionID는 ?versionId=로 특정 버전을 조회했을 때만 채워집니다.
+	VersionID      string
+	ChecksumSHA256 string
+	ChecksumCRC32C string
+	// ContentDisposition, Expires는 response-* 쿼리로 요청했을 때만 채워집니다.
+	ContentDisposition string
+	Expires            string
+	// Object Lock 설정은 repository에 기록된 객체만 채워집니다.
+	RetentionMode string
+	RetainUntil   *time.Time
+	LegalHold     bool
+}
+
+type ObjectStatResponse struct {
+	Bucket            string            `json:"bucket"`
+	Key               string            `json:"key"`
+	ContentType       string            `json:"content_type"`
+	ContentTypeSource string            `json:"content_type_source,omitempty"`
+	Size              int64             `json:"size"`
+	ETag              string            `json:"etag"`
+	LastModified      time.Time         `json:"last_modified"`
+	Metadata          map[string]string `json:"metadata"`
+	CacheControl      string            `json:"cache_control,omitempty"`
+	MetadataRevision  int               `json:"metadata_revision"`
+	VersionID         string            `json:"version_id,omitempty"`
+	ChecksumSHA256    string            `json:"checksum_sha256,omitempty"`
+	ChecksumCRC32C    string            `json:"checksum_crc32c,omitempty"`
+	RetentionMode     string            `json:"retention_mode,omitempty"`
+	RetainUntil       *time.Time        `json:"retain_until,omitempty"`
+	LegalHold         bool              `json:"legal_hold,omitempty"`
+}
+
+func (s *StorageService) HeadObject(ctx httpctx.Context) {
+	bucketName := strings.TrimSpace(ctx.Param("bucketName"))
+	objectName := strings.TrimSpace(ctx.Param("objectName"))
+	if err := validateBucketName(bucketName); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err := validateObjectName(objectName); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	overrides, err := responseOverridesFromQuery(ctx, bucketName, objectName)
+	if err != nil {
+		writeResponseOverrideError(ctx, err)
+		return
+	}
+
+	head, ok := s.lookupObject(ctx, bucketName, objectName)
+	if !ok {
+		return
+	}
+	head = overrides.apply(head)
+
+	writeObjectHeaders(ctx, head)
+	if isNotModified(ctx.Request(), head.ETag, head.LastModified) {
+		_ = ctx.Stream(http.StatusNotModified, "", bytes.NewReader(nil))
+		return
+	}
+
+	ctx.SetHeader("Accept-Ranges", "bytes")
+	if head.ContentType != "" {
+		ctx.SetHeader("Content-Type", head.ContentType)
+	}
+	ctx.SetHeader("Content-Length", fmt.Sprintf("%d", head.Size))
+	_ = ctx.Stream(http.StatusOK, head.ContentType, bytes.NewReader(nil))
+}
+
+// statObjectJSON은 GET .../objects/{objectName}?stat 요청에 객체 속성을 JSON으로 반환합니다.
+func (s *StorageService) statObjectJSON(ctx httpctx.Context, bucketName, objectName string) {
+	head, ok := s.lookupObject(ctx, bucketName, objectName)
+	if !ok {
+		return
+	}
+
+	writeObjectHeaders(ctx, head)
+	if isNotModified(ctx.Request(), head.ETag, head.LastModified) {
+		_ = ctx.Stream(http.StatusNotModified, "", bytes.NewReader(nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newObjectStatResponse(bucketName, objectName, head))
+}
+
+func newObjectStatResponse(bucketName, objectName string, head *objectHead) ObjectStatResponse {
+	return ObjectStatResponse{
+		Bucket:            bucketName,
+		Key:               objectName,
+		ContentType:       head.ContentType,
+		ContentTypeSource: head.ContentTypeSource,
+		Size:              head.Size,
+		ETag:              head.ETag,
+		LastModified:      head.LastModified,
+		Metadata:          head.Metadata,
+		CacheControl:      head.CacheControl,
+		MetadataRevision:  head.MetadataRevision,
+		VersionID:         head.VersionID,
+		ChecksumSHA256:    head.ChecksumSHA256,
+		ChecksumCRC32C:    head.ChecksumCRC32C,
+		RetentionMode:     head.RetentionMode,
+		RetainUntil:       head.RetainUntil,
+		LegalHold:         head.LegalHold,
+	}
+}
+
+// lookupObject는 statObject 결과를 조회하고 실패 시 에러 응답까지 작성합니다.
+// ?versionId=가 있으면 현재 객체 대신 해당 버전을 조회합니다.
+func (s *StorageService) lookupObject(ctx httpctx.Context, bucketName, objectName string) (*objectHead, bool) {
+	if versionID := strings.TrimSpace(ctx.Query("versionId")); versionID != "" {
+		return s.lookupObjectVersion(ctx, bucketName, objectName, versionID)
+	}
+
+	head, err := s.statObject(ctx.Context(), bucketName, objectName)
+	if err != nil {
+		if errors.Is(err, errObjectNotFound) {
+			ctx.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+			return nil, false
+		}
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("get object metadata failed: %v", err)})
+		return nil, false
+	}
+	return head, true
+}
+
+// statObject는 repository를 먼저 보고, 기록이 없으면 스토리지 백엔드에서 조회합니다.
+// 휴지통에 있는 객체는 백엔드에 남아 있어도 찾을 수 없는 것으로 봅니다.
+func (s *StorageService) statObject(ctx context.Context, bucketName, objectName string) (*objectHead, error) {
+	encodedName := encodeObjectKey(objectName)
+
+	if s.repo != nil {
+		obj, err := s.repo.GetObject(ctx, bucketName, objectName)
+		if err == nil {
+			if obj.DeletedAt != nil {
+				return nil, errObjectNotFound
+			}
+			return objectHeadFromEnt(bucketName, objectName, obj), nil
+		}
+		if !ent.IsNotFound(err) {
+			return nil, err
+		}
+	}
+
+	info, err := s.client.StatObject(ctx, bucketName, encodedName, minio.StatObjectOptions{})
+	if err != nil {
+		return nil, errObjectNotFound
+	}
+
+	meta := make(map[string]string, len(info.UserMetadata))
+	for k, v := range info.UserMetadata {
+		meta[k] = v
+	}
+	return &objectHead{
+		StorageKey:   encodedName,
+		ContentType:  info.ContentType,
+		Size:         info.Size,
+		ETag:         info.ETag,
+		LastModified: info.LastModified,
+		Metadata:     meta,
+	}, nil
+}
+
+func objectHeadFromEnt(bucketName, objectName string, obj *ent.Object) *objectHead {
+	meta := make(map[string]string, len(obj.Edges.Metadata))
+	for _, m := range obj.Edges.Metadata {
+		meta[m.Key] = m.Value
+	}
+	return &objectHead{
+		StorageKey:        normalizeStoragePath(bucketName, obj.StoragePath, encodeObjectKey(objectName)),
+		ContentType:       obj.ContentType,
+		ContentTypeSource: obj.ContentTypeSource,
+		Size:              obj.Size,
+		ETag:              obj.Etag,
+		LastModified:      obj.UpdatedAt,
+		Metadata:          meta,
+		CacheControl:      obj.CacheControl,
+		MetadataRevision:  obj.MetadataRevision,
+		ChecksumSHA256:    obj.ChecksumSha256,
+		ChecksumCRC32C:    obj.ChecksumCrc32c,
+		RetentionMode:     obj.RetentionMode,
+		RetainUntil:       obj.RetainUntil,
+		LegalHold:         obj.LegalHold,
+	}
+}
+
+// writeObjectHeaders는 다운로드/HEAD 응답에 공통으로 쓰는 캐시 검증 헤더, 체크섬 헤더와 메타데이터 헤더를 설정합니다.
+func writeObjectHeaders(ctx httpctx.Context, head *objectHead) {
+	cacheControl := head.CacheControl
+	if cacheControl == "" {
+		cacheControl = config.Get[string]("object_cache_control")
+	}
+	if cacheControl != "" {
+		ctx.SetHeader("Cache-Control", cacheControl)
+	}
+	ctx.SetHeader("ETag", head.ETag)
+	ctx.SetHeader("Last-Modified", head.LastModified.UTC().Format(http.TimeFormat))
+	if head.VersionID != "" {
+		ctx.SetHeader("X-Guiio-Version-Id", head.VersionID)
+	}
+	if head.ChecksumSHA256 != "" {
+		ctx.SetHeader(checksumSHA256Header, head.ChecksumSHA256)
+	}
+	if head.ChecksumCRC32C != "" {
+		ctx.SetHeader(checksumCRC32CHeader, head.ChecksumCRC32C)
+	}
+	if head.ContentDisposition != "" {
+		ctx.SetHeader("Content-Disposition", head.ContentDisposition)
+	}
+	if head.Expires != "" {
+		ctx.SetHeader("Expires", head.Expires)
+	}
+	if head.RetentionMode != "" && head.RetainUntil != nil {
+		ctx.SetHeader("X-Guiio-Object-Lock-Mode", head.RetentionMode)
+		ctx.SetHeader("X-Guiio-Object-Lock-Retain-Until-Date", head.RetainUntil.UTC().Format(time.RFC3339))
+	}
+	if head.LegalHold {
+		ctx.SetHeader("X-Guiio-Object-Lock-Legal-Hold", "ON")
+	}
+
+	for k, v := range head.Metadata {
+		if !isHeaderToken(k) {
+			continue
+		}
+		ctx.SetHeader(metaHeaderPrefix+k, v)
+	}
+}
+
+// isNotModified는 If-None-Match, If-Modified-Since 조건으로 304 응답 여부를 판단합니다.
+func isNotModified(req *http.Request, etag string, lastModified time.Time) bool {
+	if req == nil {
+		return false
+	}
+
+	// If-None-Match는 약한 비교이므로 압축 응답의 W/ 접두사는 떼고 비교합니다.
+	clientETag := strings.Trim(strings.TrimPrefix(req.Header.Get("If-None-Match"), "W/"), "\"")
+	serverETag := strings.Trim(strings.TrimPrefix(etag, "W/"), "\"")
+	if clientETag != "" && clientETag == serverETag {
+		return true
+	}
+	if ims := req.Header.Get("If-Modified-Since"); ims != "" {
+		// HTTP 날짜는 초 단위이므로 비교 전에 잘라냅니다.
+		if t, err := http.ParseTime(ims); err == nil && !lastModified.Truncate(time.Second).After(t) {
+			return true
+		}
+	}
+	return false
+}
+
+func isHeaderToken(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0:
+		default:
+			return false
+		}
+	}
+	return true
+}
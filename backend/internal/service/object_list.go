@@ -0,0 +1,231 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"guiio/backend/ent"
+	httpctx "guiio/backend/internal/port/httpctx"
+	"guiio/backend/internal/repository"
+
+	"github.com/minio/minio-go/v7"
+)
+
+const (
+	defaultListLimit = 100
+	maxListLimit     = 1000
+)
+
+type ObjectInfo struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	ContentType  string    `json:"content_type,omitempty"`
+	ETag         string    `json:"etag"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+type ListObjectsResponse struct {
+	Bucket         string       `json:"bucket"`
+	Prefix         string       `json:"prefix,omitempty"`
+	Delimiter      string       `json:"delimiter,omitempty"`
+	Objects        []ObjectInfo `json:"objects"`
+	CommonPrefixes []string     `json:"common_prefixes"`
+	IsTruncated    bool         `json:"is_truncated"`
+	NextCursor     string       `json:"next_cursor,omitempty"`
+}
+
+func (s *StorageService) ListObjects(ctx httpctx.Context) {
+	bucketName := strings.TrimSpace(ctx.Param("bucketName"))
+	if err := validateBucketName(bucketName); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	prefix := ctx.Query("prefix")
+	delimiter := ctx.Query("delimiter")
+
+	limit, err := parseListLimit(ctx.Query("limit"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	startAfter, err := decodeListCursor(ctx.Query("cursor"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	tags, err := parseTagFilter(ctx.Query("tag"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if tags != nil && s.repo == nil {
+		ctx.JSON(http.StatusNotImplemented, ErrorResponse{Error: "tag filter requires object repository"})
+		return
+	}
+
+	reqCtx := ctx.Context()
+
+	exists, err := s.client.BucketExists(reqCtx, bucketName)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("check bucket failed: %v", err)})
+		return
+	}
+	if !exists {
+		ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "bucket not found"})
+		return
+	}
+
+	in := repository.ObjectListInput{
+		BucketName: bucketName,
+		Prefix:     prefix,
+		Delimiter:  delimiter,
+		StartAfter: startAfter,
+		Limit:      limit,
+		Tags:       tags,
+	}
+
+	var result *repository.ObjectListResult
+	if s.repo != nil {
+		result, err = s.repo.ListObjects(reqCtx, in)
+	} else {
+		result, err = s.listStorageObjects(reqCtx, in)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("list objects failed: %v", err)})
+		return
+	}
+
+	resp := ListObjectsResponse{
+		Bucket:         bucketName,
+		Prefix:         prefix,
+		Delimiter:      delimiter,
+		Objects:        make([]ObjectInfo, 0, len(result.Objects)),
+		CommonPrefixes: make([]string, 0, len(result.CommonPrefixes)),
+		IsTruncated:    result.IsTruncated,
+	}
+	for _, obj := range result.Objects {
+		resp.Objects = append(resp.Objects, ObjectInfo{
+			Key:          obj.ObjectName,
+			Size:         obj.Size,
+			ContentType:  obj.ContentType,
+			ETag:         obj.Etag,
+			LastModified: obj.UpdatedAt,
+		})
+	}
+	resp.CommonPrefixes = append(resp.CommonPrefixes, result.CommonPrefixes...)
+	if result.IsTruncated {
+		resp.NextCursor = encodeListCursor(result.NextMarker)
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// listStorageObjects는 repository가 없을 때 스토리지 백엔드에서 직접 목록을 만듭니다.
+// 백엔드는 encodeObjectKey로 인코딩한 키 순서로 나열하므로 커서도 인코딩한 키로 비교합니다.
+// 디코딩한 이름으로 비교하면 한글처럼 %XX로 인코딩되는 이름과 ASCII 이름의 순서가 달라 페이지 사이에서 객체가 빠집니다.
+func (s *StorageService) listStorageObjects(ctx context.Context, in repository.ObjectListInput) (*repository.ObjectListResult, error) {
+	listCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	opts := minio.ListObjectsOptions{
+		Prefix:    encodeObjectKey(in.Prefix),
+		Recursive: in.Delimiter != "/",
+	}
+	if in.StartAfter != "" {
+		opts.StartAfter = encodeObjectKey(in.StartAfter)
+	}
+
+	res := &repository.ObjectListResult{}
+	skip := repository.CommonPrefix(in.StartAfter, in.Prefix, in.Delimiter)
+	count := 0
+
+	for info := range s.client.ListObjects(listCtx, in.BucketName, opts) {
+		if info.Err != nil {
+			return nil, info.Err
+		}
+
+		name := decodeObjectKey(info.Key)
+		if !strings.HasPrefix(name, in.Prefix) || (opts.StartAfter != "" && info.Key <= opts.StartAfter) {
+			continue
+		}
+		// 버전 복사본, 파생 이미지 같은 내부 객체는 목록에 보이지 않습니다.
+		if strings.HasPrefix(name, systemKeyPrefix) {
+			continue
+		}
+
+		cp := repository.CommonPrefix(name, in.Prefix, in.Delimiter)
+		if cp != "" && cp == skip {
+			continue
+		}
+		if count == in.Limit {
+			res.IsTruncated = true
+			return res, nil
+		}
+		count++
+		if cp != "" {
+			res.CommonPrefixes = append(res.CommonPrefixes, cp)
+			res.NextMarker = cp
+			skip = cp
+			continue
+		}
+		res.Objects = append(res.Objects, &ent.Object{
+			BucketName:  in.BucketName,
+			ObjectName:  name,
+			StoragePath: info.Key,
+			ContentType: info.ContentType,
+			Size:        info.Size,
+			Etag:        info.ETag,
+			UpdatedAt:   info.LastModified,
+		})
+		res.NextMarker = name
+	}
+
+	return res, nil
+}
+
+func parseListLimit(raw string) (int, error) {
+	if raw == "" {
+		return defaultListLimit, nil
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit <= 0 {
+		return 0, errors.New("limit must be a positive integer")
+	}
+	if limit > maxListLimit {
+		limit = maxListLimit
+	}
+	return limit, nil
+}
+
+func encodeListCursor(marker string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(marker))
+}
+
+func decodeListCursor(cursor string) (string, error) {
+	if cursor == "" {
+		return "", nil
+	}
+	marker, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", errors.New("invalid cursor")
+	}
+	return string(marker), nil
+}
+
+func decodeObjectKey(key string) string {
+	name, err := url.PathUnescape(key)
+	if err != nil {
+		return key
+	}
+	return name
+}
@@ -0,0 +1,365 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"guiio/backend/ent"
+	httpctx "guiio/backend/internal/port/httpctx"
+	"guiio/backend/internal/repository"
+
+	"github.com/sphynx/config"
+)
+
+// Object Lock 보존 모드입니다. S3와 같이 GOVERNANCE는 우회 권한이 있으면 줄이거나 풀 수 있고, COMPLIANCE는 기한 전에는 누구도 줄일 수 없습니다.
+const (
+	retentionModeGovernance = "GOVERNANCE"
+	retentionModeCompliance = "COMPLIANCE"
+)
+
+// bypassGovernanceHeader 값이 object_lock_bypass_token과 같을 때만 GOVERNANCE 보존을 우회합니다.
+const bypassGovernanceHeader = "X-Guiio-Bypass-Governance-Retention"
+
+var (
+	errObjectLocked        = errors.New("object is locked by retention or legal hold")
+	errObjectLockDisabled  = errors.New("object lock is not enabled for bucket")
+	errRetentionNotAllowed = errors.New("retention can only be shortened or removed in GOVERNANCE mode with bypass")
+)
+
+type BucketObjectLockRequest struct {
+	Enabled              bool   `json:"enabled"`
+	DefaultRetentionMode string `json:"default_retention_mode,omitempty"`
+	DefaultRetentionDays int    `json:"default_retention_days,omitempty"`
+}
+
+type BucketObjectLockResponse struct {
+	Bucket               string `json:"bucket"`
+	Enabled              bool   `json:"enabled"`
+	DefaultRetentionMode string `json:"default_retention_mode,omitempty"`
+	DefaultRetentionDays int    `json:"default_retention_days,omitempty"`
+}
+
+// ObjectRetentionRequest의 Mode와 RetainUntil을 모두 비우면 보존을 해제합니다.
+type ObjectRetentionRequest struct {
+	Mode        string     `json:"mode"`
+	RetainUntil *time.Time `json:"retain_until,omitempty"`
+}
+
+type ObjectLegalHoldRequest struct {
+	Enabled bool `json:"enabled"`
+}
+
+type ObjectLockResponse struct {
+	Bucket      string     `json:"bucket"`
+	Key         string     `json:"key"`
+	Mode        string     `json:"mode,omitempty"`
+	RetainUntil *time.Time `json:"retain_until,omitempty"`
+	LegalHold   bool       `json:"legal_hold"`
+}
+
+func (s *StorageService) GetBucketObjectLock(ctx httpctx.Context) {
+	bucketName, ok := s.bucketConfigTarget(ctx, "object lock")
+	if !ok {
+		return
+	}
+
+	lock, err := s.repo.GetBucketObjectLock(ctx.Context(), bucketName)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("get object lock failed: %v", err)})
+		return
+	}
+	ctx.JSON(http.StatusOK, newBucketObjectLockResponse(bucketName, lock))
+}
+
+// PutBucketObjectLock은 Object Lock을 켜고 기본 보존을 정합니다. 한 번 켠 버킷은 다시 끌 수 없습니다.
+// 기본 보존은 이후 새로 쓴 객체에만 붙고, 이미 있는 객체의 보존 기한은 바꾸지 않습니다.
+func (s *StorageService) PutBucketObjectLock(ctx httpctx.Context) {
+	bucketName, ok := s.bucketConfigTarget(ctx, "object lock")
+	if !ok {
+		return
+	}
+
+	var req BucketObjectLockRequest
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+	lock := repository.BucketObjectLock{
+		Enabled:              req.Enabled,
+		DefaultRetentionMode: strings.ToUpper(strings.TrimSpace(req.DefaultRetentionMode)),
+		DefaultRetentionDays: req.DefaultRetentionDays,
+	}
+	switch {
+	case lock.DefaultRetentionMode == "" && lock.DefaultRetentionDays == 0:
+	case !validRetentionMode(lock.DefaultRetentionMode):
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "default_retention_mode must be GOVERNANCE or COMPLIANCE"})
+		return
+	case lock.DefaultRetentionDays <= 0:
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "default_retention_days must be positive"})
+		return
+	case !lock.Enabled:
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "default retention requires object lock to be enabled"})
+		return
+	}
+
+	reqCtx := ctx.Context()
+	current, err := s.repo.GetBucketObjectLock(reqCtx, bucketName)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("get object lock failed: %v", err)})
+		return
+	}
+	if current.Enabled && !lock.Enabled {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "object lock cannot be disabled once enabled"})
+		return
+	}
+
+	if err := s.repo.SetBucketObjectLock(reqCtx, bucketName, lock); err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("set object lock failed: %v", err)})
+		return
+	}
+	ctx.JSON(http.StatusOK, newBucketObjectLockResponse(bucketName, lock))
+}
+
+func newBucketObjectLockResponse(bucketName string, lock repository.BucketObjectLock) BucketObjectLockResponse {
+	return BucketObjectLockResponse{
+		Bucket:               bucketName,
+		Enabled:              lock.Enabled,
+		DefaultRetentionMode: lock.DefaultRetentionMode,
+		DefaultRetentionDays: lock.DefaultRetentionDays,
+	}
+}
+
+func (s *StorageService) GetObjectRetention(ctx httpctx.Context) {
+	s.getObjectLock(ctx)
+}
+
+func (s *StorageService) GetObjectLegalHold(ctx httpctx.Context) {
+	s.getObjectLock(ctx)
+}
+
+func (s *StorageService) getObjectLock(ctx httpctx.Context) {
+	bucketName, objectName, ok := s.objectLockTarget(ctx)
+	if !ok {
+		return
+	}
+
+	obj, err := s.repo.GetObject(ctx.Context(), bucketName, objectName)
+	if err != nil || obj.DeletedAt != nil {
+		if err == nil || ent.IsNotFound(err) {
+			ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "object not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("get object failed: %v", err)})
+		return
+	}
+	ctx.JSON(http.StatusOK, newObjectLockResponse(bucketName, objectName, obj))
+}
+
+// PutObjectRetention은 객체의 보존 모드와 기한을 바꿉니다.
+// 기한을 늘리거나 GOVERNANCE를 COMPLIANCE로 강화하는 것은 언제나 허용하고,
+// 줄이거나 푸는 것은 GOVERNANCE이면서 우회 권한이 있을 때만 허용합니다. COMPLIANCE는 기한이 지나야 바꿀 수 있습니다.
+func (s *StorageService) PutObjectRetention(ctx httpctx.Context) {
+	bucketName, objectName, ok := s.objectLockTarget(ctx)
+	if !ok {
+		return
+	}
+
+	var req ObjectRetentionRequest
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+	mode := strings.ToUpper(strings.TrimSpace(req.Mode))
+	retainUntil := req.RetainUntil
+	if mode != "" || retainUntil != nil {
+		if !validRetentionMode(mode) {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "mode must be GOVERNANCE or COMPLIANCE"})
+			return
+		}
+		if retainUntil == nil || !retainUntil.After(time.Now()) {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "retain_until must be in the future"})
+			return
+		}
+		until := retainUntil.UTC()
+		retainUntil = &until
+	}
+
+	s.updateObjectLock(ctx, bucketName, objectName, func(otx repository.ObjectTx) (*ent.Object, error) {
+		if err := checkRetentionChange(otx.Current(), mode, retainUntil, governanceBypass(ctx.Request())); err != nil {
+			return nil, err
+		}
+		return otx.SetRetention(ctx.Context(), mode, retainUntil)
+	})
+}
+
+// PutObjectLegalHold는 legal hold를 걸거나 풉니다. 보존 기한과 별개이며 우회 권한이 필요하지 않습니다.
+func (s *StorageService) PutObjectLegalHold(ctx httpctx.Context) {
+	bucketName, objectName, ok := s.objectLockTarget(ctx)
+	if !ok {
+		return
+	}
+
+	var req ObjectLegalHoldRequest
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	s.updateObjectLock(ctx, bucketName, objectName, func(otx repository.ObjectTx) (*ent.Object, error) {
+		return otx.SetLegalHold(ctx.Context(), req.Enabled)
+	})
+}
+
+// updateObjectLock은 객체 키를 잠근 채 update로 Object Lock 설정을 바꾸고 결과를 응답합니다.
+func (s *StorageService) updateObjectLock(ctx httpctx.Context, bucketName, objectName string, update func(repository.ObjectTx) (*ent.Object, error)) {
+	reqCtx := ctx.Context()
+	lock, err := s.bucketObjectLock(reqCtx, bucketName)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if !lock.Enabled {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: errObjectLockDisabled.Error()})
+		return
+	}
+
+	otx, err := s.repo.BeginObjectTx(reqCtx, bucketName, objectName)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("lock object failed: %v", err)})
+		return
+	}
+	defer otx.Rollback()
+	if current := otx.Current(); current == nil || current.DeletedAt != nil {
+		ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "object not found"})
+		return
+	}
+
+	obj, err := update(otx)
+	if err == nil {
+		err = otx.Commit()
+	}
+	if err != nil {
+		if errors.Is(err, errRetentionNotAllowed) {
+			ctx.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("update object lock failed: %v", err)})
+		return
+	}
+	ctx.JSON(http.StatusOK, newObjectLockResponse(bucketName, objectName, obj))
+}
+
+// objectLockTarget은 객체 Object Lock API의 공통 검증(이름, repository, 버킷 존재)을 처리합니다.
+func (s *StorageService) objectLockTarget(ctx httpctx.Context) (string, string, bool) {
+	bucketName, ok := s.bucketConfigTarget(ctx, "object lock")
+	if !ok {
+		return "", "", false
+	}
+	objectName := strings.TrimSpace(ctx.Param("objectName"))
+	if err := validateObjectName(objectName); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return "", "", false
+	}
+	return bucketName, objectName, true
+}
+
+func newObjectLockResponse(bucketName, objectName string, obj *ent.Object) ObjectLockResponse {
+	return ObjectLockResponse{
+		Bucket:      bucketName,
+		Key:         objectName,
+		Mode:        obj.RetentionMode,
+		RetainUntil: obj.RetainUntil,
+		LegalHold:   obj.LegalHold,
+	}
+}
+
+func validRetentionMode(mode string) bool {
+	return mode == retentionModeGovernance || mode == retentionModeCompliance
+}
+
+// checkRetentionChange는 현재 보존 설정을 mode, retainUntil로 바꿔도 되는지 확인합니다. 기한이 지난 보존은 없는 것으로 봅니다.
+func checkRetentionChange(obj *ent.Object, mode string, retainUntil *time.Time, bypass bool) error {
+	if obj.RetainUntil == nil || !obj.RetainUntil.After(time.Now()) {
+		return nil
+	}
+	extends := retainUntil != nil && !retainUntil.Before(*obj.RetainUntil)
+	switch obj.RetentionMode {
+	case retentionModeCompliance:
+		if extends && mode == retentionModeCompliance {
+			return nil
+		}
+	default:
+		if extends || bypass {
+			return nil
+		}
+	}
+	return errRetentionNotAllowed
+}
+
+// checkObjectLock은 obj를 지우거나 덮어써도 되는지 확인합니다.
+// legal hold와 COMPLIANCE 보존은 우회할 수 없고, GOVERNANCE 보존은 bypass일 때만 통과합니다.
+func checkObjectLock(obj *ent.Object, bypass bool) error {
+	if obj == nil {
+		return nil
+	}
+	if obj.LegalHold {
+		return errObjectLocked
+	}
+	if obj.RetainUntil != nil && obj.RetainUntil.After(time.Now()) {
+		if obj.RetentionMode == retentionModeGovernance && bypass {
+			return nil
+		}
+		return errObjectLocked
+	}
+	return nil
+}
+
+// checkOverwrite는 otx가 잠근 현재 객체를 덮어써도 되는지 확인합니다. 휴지통에 있는 객체는 없는 키로 봅니다.
+// 확인부터 백엔드 쓰기, 커밋까지 키를 잠그고 있어야 그 사이 보존 설정이 바뀌어도 잠긴 객체를 덮어쓰지 않습니다.
+func checkOverwrite(otx repository.ObjectTx, bypass bool) error {
+	if otx == nil {
+		return nil
+	}
+	current := otx.Current()
+	if current == nil || current.DeletedAt != nil {
+		return nil
+	}
+	return checkObjectLock(current, bypass)
+}
+
+// governanceBypass는 요청이 GOVERNANCE 보존을 우회할 권한이 있는지 확인합니다. object_lock_bypass_token이 비어 있으면 아무도 우회할 수 없습니다.
+func governanceBypass(r *http.Request) bool {
+	token := config.Get[string]("object_lock_bypass_token")
+	if r == nil || token == "" {
+		return false
+	}
+	given := r.Header.Get(bypassGovernanceHeader)
+	return subtle.ConstantTimeCompare([]byte(given), []byte(token)) == 1
+}
+
+// bucketObjectLock은 버킷의 Object Lock 설정입니다. repository가 없으면 꺼진 설정입니다.
+func (s *StorageService) bucketObjectLock(ctx context.Context, bucketName string) (repository.BucketObjectLock, error) {
+	if s.repo == nil {
+		return repository.BucketObjectLock{}, nil
+	}
+	lock, err := s.repo.GetBucketObjectLock(ctx, bucketName)
+	if err != nil {
+		return lock, fmt.Errorf("get object lock failed: %w", err)
+	}
+	return lock, nil
+}
+
+// applyDefaultRetention은 버킷 기본 보존이 있으면 새로 쓰는 객체 in에 보존 모드와 기한을 붙입니다.
+func applyDefaultRetention(lock repository.BucketObjectLock, in *repository.ObjectUpsertInput) {
+	if !lock.Enabled || lock.DefaultRetentionMode == "" || lock.DefaultRetentionDays <= 0 {
+		return
+	}
+	until := time.Now().UTC().AddDate(0, 0, lock.DefaultRetentionDays)
+	in.RetentionMode = lock.DefaultRetentionMode
+	in.RetainUntil = &until
+}
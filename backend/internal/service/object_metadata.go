@@ -0,0 +1,122 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"guiio/backend/ent"
+	httpctx "guiio/backend/internal/port/httpctx"
+	"guiio/backend/internal/repository"
+)
+
+const (
+	metadataModeMerge   = "merge"
+	metadataModeReplace = "replace"
+)
+
+// UpdateObjectMetadataRequest는 메타데이터 변경 요청입니다.
+// merge(기본값)에서 값이 null인 키는 삭제하고, replace는 Metadata로 전체를 대체합니다.
+// ContentType, CacheControl은 필드가 있을 때만 바꾸며 빈 문자열은 기본값으로 되돌립니다.
+type UpdateObjectMetadataRequest struct {
+	Mode         string             `json:"mode,omitempty"`
+	Metadata     map[string]*string `json:"metadata,omitempty"`
+	ContentType  *string            `json:"content_type,omitempty"`
+	CacheControl *string            `json:"cache_control,omitempty"`
+}
+
+// UpdateObjectMetadata는 콘텐츠를 다시 올리지 않고 메타데이터와 Content-Type, Cache-Control을 바꿉니다.
+// 보존 중이거나 legal hold가 걸린 객체는 덮어쓰기와 같이 403입니다.
+func (s *StorageService) UpdateObjectMetadata(ctx httpctx.Context) {
+	bucketName := strings.TrimSpace(ctx.Param("bucketName"))
+	objectName := strings.TrimSpace(ctx.Param("objectName"))
+	if err := validateBucketName(bucketName); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err := validateObjectName(objectName); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if s.repo == nil {
+		ctx.JSON(http.StatusNotImplemented, ErrorResponse{Error: "metadata update requires object repository"})
+		return
+	}
+
+	var req UpdateObjectMetadataRequest
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+
+	mode := strings.ToLower(strings.TrimSpace(req.Mode))
+	if mode == "" {
+		mode = metadataModeMerge
+	}
+	if mode != metadataModeMerge && mode != metadataModeReplace {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "mode must be merge or replace"})
+		return
+	}
+
+	in := repository.ObjectMetadataUpdateInput{
+		BucketName:   bucketName,
+		ObjectName:   objectName,
+		Replace:      mode == metadataModeReplace,
+		Set:          map[string]string{},
+		CacheControl: req.CacheControl,
+	}
+	for k, v := range req.Metadata {
+		key := strings.ToLower(strings.TrimSpace(k))
+		if !isHeaderToken(key) {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("invalid metadata key %q", k)})
+			return
+		}
+		if v == nil {
+			if in.Replace {
+				ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: fmt.Sprintf("metadata %q: null is only allowed in merge mode", k)})
+				return
+			}
+			in.Remove = append(in.Remove, key)
+			continue
+		}
+		in.Set[key] = *v
+	}
+	if req.ContentType != nil {
+		contentType := strings.TrimSpace(*req.ContentType)
+		if contentType == "" {
+			contentType = defaultContentType
+		}
+		in.ContentType = &contentType
+		in.ContentTypeSource = declaredContentTypeSource(contentType)
+	}
+
+	// 같은 키의 쓰기와 보존 설정 변경이 끼어들지 않도록 키를 잠근 채 Object Lock을 확인하고 바꿉니다.
+	otx, err := s.beginObjectWrite(ctx.Context(), bucketName, objectName)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+	defer otx.Rollback()
+
+	if err := checkOverwrite(otx, governanceBypass(ctx.Request())); err != nil {
+		ctx.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+		return
+	}
+	obj, err := otx.UpdateMetadata(ctx.Context(), in)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			ctx.JSON(http.StatusNotFound, ErrorResponse{Error: errObjectNotFound.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("update metadata failed: %v", err)})
+		return
+	}
+	if err := otx.Commit(); err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("update metadata failed: %v", err)})
+		return
+	}
+
+	head := objectHeadFromEnt(bucketName, objectName, obj)
+	writeObjectHeaders(ctx, head)
+	ctx.JSON(http.StatusOK, newObjectStatResponse(bucketName, objectName, head))
+}
@@ -0,0 +1,400 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"guiio/backend/ent"
+	httpctx "guiio/backend/internal/port/httpctx"
+	"guiio/backend/internal/repository"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/sphynx/config"
+)
+
+// dataKeyPrefix 아래 키는 repository가 관리하는 객체의 본문입니다. objects.storage_path가 가리키며 객체 이름과 무관합니다.
+const dataKeyPrefix = systemKeyPrefix + "data/"
+
+// streamPartSize는 길이를 모르는 본문을 올릴 때 백엔드 파트 크기입니다.
+// minio-go는 파트 하나를 메모리에 버퍼링하므로, 지정하지 않으면 기본값(약 537MiB)만큼 요청마다 할당합니다.
+const streamPartSize = 16 << 20
+
+var (
+	errObjectTooLarge = errors.New("object exceeds maximum upload size")
+	errBucketNotFound = errors.New("bucket not found")
+)
+
+// putObjectInput은 업로드 경로(멀티파트 폼, raw PUT)가 공통으로 넘기는 저장 요청입니다.
+// Size가 -1이면 길이를 모르는 스트림으로 보고 백엔드가 파트 단위로 나눠 올립니다.
+type putObjectInput struct {
+	BucketName string
+	ObjectName string
+	// ContentType이 비었거나 application/octet-stream이면 버킷 정책에 따라 본문과 확장자로 판별합니다.
+	ContentType string
+	Size        int64
+	Metadata    map[string]string
+	Body        io.Reader
+	Condition   writeCondition
+	// Checksums가 있으면 본문 끝에서 비교해 다르면 errChecksumMismatch로 업로드를 중단합니다.
+	Checksums expectedChecksums
+}
+
+// PutObject는 요청 본문을 버퍼링하지 않고 그대로 스토리지로 흘려보냅니다.
+func (s *StorageService) PutObject(ctx httpctx.Context) {
+	bucketName := strings.TrimSpace(ctx.Param("bucketName"))
+	objectName := strings.TrimSpace(ctx.Param("objectName"))
+	if err := validateBucketName(bucketName); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err := validateObjectName(objectName); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	r := ctx.Request()
+	if r == nil || r.Body == nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "request body is required"})
+		return
+	}
+	defer r.Body.Close()
+
+	checksums, err := checksumsFromHeader(r.Header, true)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	size := r.ContentLength
+	if size < 0 {
+		size = -1
+	}
+
+	resp, err := s.storeObject(ctx.Context(), putObjectInput{
+		BucketName:  bucketName,
+		ObjectName:  objectName,
+		ContentType: r.Header.Get("Content-Type"),
+		Size:        size,
+		Metadata:    metadataFromHeader(r.Header),
+		Body:        r.Body,
+		Condition:   writeConditionFromRequest(r),
+		Checksums:   checksums,
+	})
+	if err != nil {
+		writeStoreError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, resp)
+}
+
+// storeObject는 백엔드에 객체를 올리고 objects/object_metadata 행을 갱신합니다.
+func (s *StorageService) storeObject(ctx context.Context, in putObjectInput) (*UploadObjectResponse, error) {
+	maxSize := config.Get[int64]("object_max_upload_size")
+	body := in.Body
+	if maxSize > 0 {
+		if in.Size > maxSize {
+			return nil, errObjectTooLarge
+		}
+		if in.Size < 0 {
+			body = &maxSizeReader{r: body, remaining: maxSize}
+		}
+	}
+
+	cas, err := s.contentAddressable(ctx, in.BucketName)
+	if err != nil {
+		return nil, err
+	}
+
+	// 본문은 키를 잠그지 않고 고유 키에 올리고, 키를 잠근 동안에는 조건과 Object Lock 확인, 행 교체만 합니다.
+	// 조건 없는 쓰기도 잠가야 조건부 쓰기가 확인한 상태를 그 사이에 덮어쓰지 못합니다.
+	check := func(otx repository.ObjectTx) error {
+		if in.Condition.isSet() {
+			if err := s.checkWriteCondition(ctx, in.BucketName, in.ObjectName, otx, in.Condition); err != nil {
+				return err
+			}
+		}
+		return checkOverwrite(otx, in.Condition.BypassGovernance)
+	}
+	if s.repo == nil {
+		// repository가 없으면 객체 이름 키에 바로 쓰므로 올리기 전에 확인합니다.
+		if err := check(nil); err != nil {
+			return nil, err
+		}
+	}
+
+	contentType, contentTypeSource, body, err := s.resolveContentType(ctx, in, body)
+	if err != nil {
+		return nil, err
+	}
+
+	sums := newChecksumReader(body, in.Size, in.Checksums)
+	if in.Size == 0 {
+		// 백엔드가 빈 본문은 읽지 않을 수 있으므로 업로드 전에 확인합니다.
+		if err := sums.verify(); err != nil {
+			return nil, err
+		}
+	}
+
+	// blob 키는 본문을 다 읽어야 정해지므로 content-addressable 버킷은 임시 키에 올린 뒤 blob으로 옮깁니다.
+	uploadKey := s.newDataKey(in.ObjectName)
+	if cas {
+		uploadKey = stagingKey()
+		defer s.client.RemoveObject(context.WithoutCancel(ctx), in.BucketName, uploadKey, minio.RemoveObjectOptions{})
+	}
+	opts := minio.PutObjectOptions{ContentType: contentType}
+	if in.Size < 0 {
+		opts.PartSize = streamPartSizeFor(maxSize)
+	}
+	uinfo, err := s.client.PutObject(ctx, in.BucketName, uploadKey, sums, in.Size, opts)
+	if err != nil {
+		if errors.Is(err, errObjectTooLarge) {
+			return nil, errObjectTooLarge
+		}
+		if errors.Is(err, errChecksumMismatch) {
+			return nil, errChecksumMismatch
+		}
+		if minio.ToErrorResponse(err).Code == "NoSuchBucket" {
+			return nil, errBucketNotFound
+		}
+		return nil, fmt.Errorf("upload failed: %w", err)
+	}
+
+	record := repository.ObjectUpsertInput{
+		BucketName:        in.BucketName,
+		ObjectName:        in.ObjectName,
+		StoragePath:       uploadKey,
+		ContentType:       contentType,
+		ContentTypeSource: contentTypeSource,
+		Size:              uinfo.Size,
+		ETag:              uinfo.ETag,
+		Metadata:          in.Metadata,
+		ChecksumSHA256:    sums.SHA256(),
+		ChecksumCRC32C:    sums.CRC32C(),
+	}
+	var src minio.CopySrcOptions
+	if cas {
+		src = minio.CopySrcOptions{Bucket: in.BucketName, Object: uploadKey}
+		record.StoragePath, record.BlobSHA256, _ = blobKey(record.ChecksumSHA256)
+	}
+	versionID, err := s.commitObject(ctx, &record, src, check)
+	if err != nil {
+		return nil, err
+	}
+
+	return &UploadObjectResponse{
+		Bucket:            in.BucketName,
+		Object:            in.ObjectName,
+		ContentType:       contentType,
+		ContentTypeSource: contentTypeSource,
+		Size:              record.Size,
+		ETag:              record.ETag,
+		StoragePath:       record.StoragePath,
+		VersionID:         versionID,
+		ChecksumSHA256:    record.ChecksumSHA256,
+		ChecksumCRC32C:    record.ChecksumCRC32C,
+	}, nil
+}
+
+// beginObjectWrite는 키를 잠근 ObjectTx를 시작합니다. repository가 없으면 nil을 반환하고, 호출자는 잠금 없이 백엔드에만 씁니다.
+func (s *StorageService) beginObjectWrite(ctx context.Context, bucketName, objectName string) (repository.ObjectTx, error) {
+	if s.repo == nil {
+		return nil, nil
+	}
+	otx, err := s.repo.BeginObjectTx(ctx, bucketName, objectName)
+	if err != nil {
+		return nil, fmt.Errorf("lock object failed: %w", err)
+	}
+	return otx, nil
+}
+
+// newDataKey는 객체 본문을 올릴 백엔드 키를 만듭니다. repository가 있으면 쓰기마다 고유한 키라서
+// 같은 객체에 동시에 쓰는 요청이 서로의 본문을 덮어쓰지 않으므로 키를 잠그기 전에 올릴 수 있습니다.
+// repository가 없으면 가리킬 행이 없으므로 객체 이름 키에 바로 씁니다.
+func (s *StorageService) newDataKey(objectName string) string {
+	if s.repo == nil {
+		return encodeObjectKey(objectName)
+	}
+	return dataKeyPrefix + newRandomID()
+}
+
+func isDataKey(storageKey string) bool {
+	return strings.HasPrefix(storageKey, dataKeyPrefix)
+}
+
+// commitObject는 백엔드에 이미 올린 in.StoragePath를 키를 잠근 트랜잭션에서 현재 객체로 바꿔 끼우고 버전 ID를 반환합니다.
+// blob 생성, 버전 복사본처럼 본문을 옮기는 작업은 잠그기 전에 끝내고, 잠근 동안에는 check(조건, Object Lock) 확인과
+// 행 갱신만 합니다. 덮어쓴 이전 본문은 커밋한 뒤 지우고, 실패하면 이 쓰기를 위해 만든 백엔드 객체를 지웁니다.
+// in.BlobSHA256이 있으면 in.StoragePath는 blob 키이고, blob이 없으면 src를 복사해 만듭니다. src는 버전 복사본의 원본이기도 합니다.
+// repository가 없으면 아무것도 하지 않습니다.
+func (s *StorageService) commitObject(ctx context.Context, in *repository.ObjectUpsertInput, src minio.CopySrcOptions, check func(repository.ObjectTx) error) (string, error) {
+	if s.repo == nil {
+		return "", nil
+	}
+	var version *repository.ObjectVersionInput
+	committed := false
+	defer func() {
+		if !committed {
+			s.discardWrite(context.WithoutCancel(ctx), *in, version)
+		}
+	}()
+
+	lock, err := s.bucketObjectLock(ctx, in.BucketName)
+	if err != nil {
+		return "", err
+	}
+	applyDefaultRetention(lock, in)
+	blob := in.BlobSHA256 != ""
+	if blob {
+		if err := s.ensureBlob(ctx, in, src); err != nil {
+			return "", err
+		}
+	} else {
+		src = minio.CopySrcOptions{Bucket: in.BucketName, Object: in.StoragePath}
+	}
+	if version, err = s.prepareVersion(ctx, *in, src); err != nil {
+		return "", err
+	}
+
+	otx, err := s.repo.BeginObjectTx(ctx, in.BucketName, in.ObjectName)
+	if err != nil {
+		return "", fmt.Errorf("lock object failed: %w", err)
+	}
+	defer otx.Rollback()
+	if err := check(otx); err != nil {
+		return "", err
+	}
+	if blob {
+		// 잠그기 전에 만든 blob을 그 사이 정리 작업이 지웠을 수 있으므로 blob을 잠근 뒤 다시 확인합니다.
+		if err := otx.LockBlob(ctx, in.StoragePath); err != nil {
+			return "", fmt.Errorf("lock blob failed: %w", err)
+		}
+		if err := s.ensureBlob(ctx, in, src); err != nil {
+			return "", err
+		}
+	}
+
+	// 휴지통 행은 Upsert가 건드리지 않고 새 행을 만들므로, 그 본문은 덮어쓴 것으로 보고 지우면 안 됩니다.
+	previous := otx.Current()
+	if previous != nil && previous.DeletedAt != nil {
+		previous = nil
+	}
+	if _, err := otx.Upsert(ctx, *in); err != nil {
+		return "", fmt.Errorf("save object metadata failed: %w", err)
+	}
+	var replaced string
+	if version != nil {
+		if replaced, err = s.saveVersion(ctx, otx, *version); err != nil {
+			return "", err
+		}
+	}
+	if err := otx.Commit(); err != nil {
+		return "", fmt.Errorf("save object metadata failed: %w", err)
+	}
+	committed = true
+
+	s.pruneStaleDerivatives(ctx, previous, *in)
+	if previous != nil {
+		previousKey := normalizeStoragePath(in.BucketName, previous.StoragePath, encodeObjectKey(in.ObjectName))
+		if previousKey != in.StoragePath {
+			s.releaseStorage(ctx, in.BucketName, previousKey)
+		}
+	}
+	if version == nil {
+		return "", nil
+	}
+	if replaced != "" && replaced != version.StoragePath {
+		_ = s.client.RemoveObject(ctx, in.BucketName, replaced, minio.RemoveObjectOptions{})
+	}
+	return version.VersionID, nil
+}
+
+// discardWrite는 커밋하지 못한 쓰기가 만든 본문과 버전 복사본을 지웁니다.
+// blob은 다른 쓰기가 이미 가리키고 있을 수 있으므로 참조가 없을 때만 지웁니다.
+func (s *StorageService) discardWrite(ctx context.Context, in repository.ObjectUpsertInput, version *repository.ObjectVersionInput) {
+	switch {
+	case in.BlobSHA256 != "":
+		_, _ = s.collectBlob(ctx, in.StoragePath)
+	case isDataKey(in.StoragePath):
+		_ = s.client.RemoveObject(ctx, in.BucketName, in.StoragePath, minio.RemoveObjectOptions{})
+	}
+	if version != nil {
+		_ = s.client.RemoveObject(ctx, in.BucketName, version.StoragePath, minio.RemoveObjectOptions{})
+	}
+}
+
+// pruneStaleDerivatives는 덮어쓰기로 ETag가 바뀐 객체의 이전 파생 이미지를 지웁니다.
+func (s *StorageService) pruneStaleDerivatives(ctx context.Context, previous *ent.Object, in repository.ObjectUpsertInput) {
+	if previous == nil || previous.Etag == in.ETag {
+		return
+	}
+	s.pruneDerivatives(ctx, in.BucketName, in.ObjectName, in.ETag)
+}
+
+// writeStoreError는 storeObject 에러를 HTTP 상태 코드로 변환합니다.
+func writeStoreError(ctx httpctx.Context, err error) {
+	switch {
+	case errors.Is(err, errObjectTooLarge):
+		ctx.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, errBucketNotFound):
+		ctx.JSON(http.StatusNotFound, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, errChecksumMismatch):
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, errPreconditionFailed):
+		ctx.JSON(http.StatusPreconditionFailed, ErrorResponse{Error: err.Error()})
+	case errors.Is(err, errObjectLocked):
+		ctx.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+	}
+}
+
+// streamPartSizeFor는 최대 업로드 크기가 파트 수 한도(maxPartNumber) 안에 들어가는 가장 작은 파트 크기를 반환합니다.
+// streamPartSize보다 작게는 나누지 않습니다.
+func streamPartSizeFor(maxSize int64) uint64 {
+	size := int64(streamPartSize)
+	if maxSize > 0 {
+		if need := (maxSize + maxPartNumber - 1) / maxPartNumber; need > size {
+			size = need
+		}
+	}
+	return uint64(size)
+}
+
+// metadataFromHeader는 X-Guiio-Meta-* 요청 헤더를 소문자 키의 메타데이터로 변환합니다.
+func metadataFromHeader(h http.Header) map[string]string {
+	metadata := map[string]string{}
+	for k, vals := range h {
+		if len(vals) == 0 || len(k) <= len(metaHeaderPrefix) || !strings.EqualFold(k[:len(metaHeaderPrefix)], metaHeaderPrefix) {
+			continue
+		}
+		metadata[strings.ToLower(k[len(metaHeaderPrefix):])] = vals[0]
+	}
+	return metadata
+}
+
+// maxSizeReader는 길이를 모르는(chunked) 본문이 최대 크기를 넘으면 errObjectTooLarge를 반환합니다.
+type maxSizeReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (m *maxSizeReader) Read(p []byte) (int, error) {
+	if m.remaining < 0 {
+		return 0, errObjectTooLarge
+	}
+	// 한 바이트를 더 읽어 정확히 최대 크기인 본문과 초과한 본문을 구분합니다.
+	if int64(len(p)) > m.remaining+1 {
+		p = p[:m.remaining+1]
+	}
+	n, err := m.r.Read(p)
+	m.remaining -= int64(n)
+	if m.remaining < 0 {
+		return n, errObjectTooLarge
+	}
+	return n, err
+}
@@ -0,0 +1,256 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	httpctx "guiio/backend/internal/port/httpctx"
+
+	"github.com/minio/minio-go/v7"
+)
+
+// maxRanges는 한 요청에서 나눠 보낼 구간 수의 상한입니다. 넘으면 multipart 응답 대신 전체를 200으로 보냅니다.
+const maxRanges = 100
+
+var (
+	errInvalidRange   = errors.New("invalid range")
+	errRangeNoOverlap = errors.New("range not satisfiable")
+)
+
+// httpRange는 Range 헤더의 한 구간을 절대 오프셋으로 표현합니다.
+type httpRange struct {
+	start, length int64
+}
+
+func (r httpRange) contentRange(size int64) string {
+	return fmt.Sprintf("bytes %d-%d/%d", r.start, r.start+r.length-1, size)
+}
+
+func (r httpRange) mimeHeader(contentType string, size int64) textproto.MIMEHeader {
+	return textproto.MIMEHeader{
+		"Content-Range": {r.contentRange(size)},
+		"Content-Type":  {contentType},
+	}
+}
+
+// serveRanges는 Range 요청을 206/416으로 처리합니다.
+// 문법이 틀린 Range는 없는 것으로 보고(RFC 9110 14.2), 구간이 maxRanges보다 많으면 전체 전송이 낫기 때문에
+// false를 반환하고 호출자가 200으로 보냅니다. 416은 객체와 겹치는 구간이 하나도 없을 때만 보냅니다.
+func (s *StorageService) serveRanges(ctx httpctx.Context, bucketName string, head *objectHead, rangeHeader string) bool {
+	ranges, err := parseRange(rangeHeader, head.Size)
+	if errors.Is(err, errInvalidRange) {
+		return false
+	}
+	if err != nil {
+		ctx.SetHeader("Content-Range", fmt.Sprintf("bytes */%d", head.Size))
+		ctx.JSON(http.StatusRequestedRangeNotSatisfiable, ErrorResponse{Error: err.Error()})
+		return true
+	}
+	if len(ranges) > maxRanges {
+		return false
+	}
+
+	contentType := head.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	if len(ranges) == 1 {
+		ra := ranges[0]
+		objReader, err := s.getObjectRange(ctx, bucketName, head.StorageKey, ra)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("download failed: %v", err)})
+			return true
+		}
+		defer objReader.Close()
+
+		ctx.SetHeader("Content-Range", ra.contentRange(head.Size))
+		ctx.SetHeader("Content-Length", strconv.FormatInt(ra.length, 10))
+		if err := ctx.Stream(http.StatusPartialContent, contentType, io.LimitReader(objReader, ra.length)); err != nil {
+			ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("stream failed: %v", err)})
+		}
+		return true
+	}
+
+	pr, pw := io.Pipe()
+	defer pr.Close()
+	mw := multipart.NewWriter(pw)
+
+	go func() {
+		for _, ra := range ranges {
+			part, err := mw.CreatePart(ra.mimeHeader(contentType, head.Size))
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			objReader, err := s.getObjectRange(ctx, bucketName, head.StorageKey, ra)
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+			_, err = io.CopyN(part, objReader, ra.length)
+			objReader.Close()
+			if err != nil {
+				pw.CloseWithError(err)
+				return
+			}
+		}
+		mw.Close()
+		pw.Close()
+	}()
+
+	ctx.SetHeader("Content-Length", strconv.FormatInt(rangesMIMESize(ranges, contentType, head.Size), 10))
+	if err := ctx.Stream(http.StatusPartialContent, "multipart/byteranges; boundary="+mw.Boundary(), pr); err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("stream failed: %v", err)})
+	}
+	return true
+}
+
+// getObjectRange는 필요한 구간만 백엔드에서 읽도록 SetRange를 지정합니다.
+func (s *StorageService) getObjectRange(ctx httpctx.Context, bucketName, storageKey string, ra httpRange) (io.ReadCloser, error) {
+	opts := minio.GetObjectOptions{}
+	if err := opts.SetRange(ra.start, ra.start+ra.length-1); err != nil {
+		return nil, err
+	}
+	return s.client.GetObject(ctx.Context(), storageBucket(bucketName, storageKey), storageKey, opts)
+}
+
+// ifRangeMatches는 If-Range가 없거나 현재 표현과 일치할 때 true를 반환합니다.
+// ETag는 strong 비교만 허용하고, 날짜는 Last-Modified와 정확히 같아야 합니다.
+func ifRangeMatches(ifRange, etag string, lastModified time.Time) bool {
+	ifRange = strings.TrimSpace(ifRange)
+	if ifRange == "" {
+		return true
+	}
+	if strings.HasPrefix(ifRange, "W/") {
+		return false
+	}
+	if strings.HasPrefix(ifRange, "\"") {
+		return strings.Trim(ifRange, "\"") == strings.Trim(etag, "\"") && !strings.HasPrefix(etag, "W/")
+	}
+	t, err := http.ParseTime(ifRange)
+	if err != nil {
+		return false
+	}
+	return lastModified.Truncate(time.Second).Equal(t)
+}
+
+// parseRange는 "bytes=0-99,200-,-50" 형식의 Range 헤더를 절대 구간 목록으로 변환합니다.
+// 객체와 겹치지 않는 구간은 버리고, 하나도 남지 않으면 errRangeNoOverlap을 반환합니다.
+// 겹치거나 맞닿은 구간은 합쳐서 시작 오프셋 순으로 반환합니다.
+func parseRange(header string, size int64) ([]httpRange, error) {
+	const prefix = "bytes="
+	if !strings.HasPrefix(header, prefix) {
+		return nil, errInvalidRange
+	}
+
+	var ranges []httpRange
+	noOverlap := false
+	for _, spec := range strings.Split(header[len(prefix):], ",") {
+		spec = strings.TrimSpace(spec)
+		if spec == "" {
+			continue
+		}
+		startStr, endStr, ok := strings.Cut(spec, "-")
+		if !ok {
+			return nil, errInvalidRange
+		}
+		startStr, endStr = strings.TrimSpace(startStr), strings.TrimSpace(endStr)
+
+		var ra httpRange
+		if startStr == "" {
+			// suffix 구간: 마지막 N바이트
+			if endStr == "" || endStr[0] == '-' {
+				return nil, errInvalidRange
+			}
+			n, err := strconv.ParseInt(endStr, 10, 64)
+			if err != nil || n < 0 {
+				return nil, errInvalidRange
+			}
+			if n == 0 || size == 0 {
+				noOverlap = true
+				continue
+			}
+			if n > size {
+				n = size
+			}
+			ra = httpRange{start: size - n, length: n}
+		} else {
+			start, err := strconv.ParseInt(startStr, 10, 64)
+			if err != nil || start < 0 {
+				return nil, errInvalidRange
+			}
+			if start >= size {
+				noOverlap = true
+				continue
+			}
+			end := size - 1
+			if endStr != "" {
+				end, err = strconv.ParseInt(endStr, 10, 64)
+				if err != nil || end < start {
+					return nil, errInvalidRange
+				}
+				if end >= size {
+					end = size - 1
+				}
+			}
+			ra = httpRange{start: start, length: end - start + 1}
+		}
+		ranges = append(ranges, ra)
+	}
+
+	if len(ranges) == 0 {
+		if noOverlap {
+			return nil, errRangeNoOverlap
+		}
+		return nil, errInvalidRange
+	}
+	return mergeRanges(ranges), nil
+}
+
+// mergeRanges는 겹치거나 맞닿은 구간을 하나로 합칩니다.
+// 같은 바이트를 여러 번 요청해 응답을 부풀리는 것을 막고, 합친 뒤의 길이 합은 객체 크기를 넘지 않습니다.
+func mergeRanges(ranges []httpRange) []httpRange {
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].start < ranges[j].start })
+	merged := ranges[:1]
+	for _, ra := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if ra.start > last.start+last.length {
+			merged = append(merged, ra)
+			continue
+		}
+		if end := ra.start + ra.length; end > last.start+last.length {
+			last.length = end - last.start
+		}
+	}
+	return merged
+}
+
+type countingWriter int64
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	*w += countingWriter(len(p))
+	return len(p), nil
+}
+
+// rangesMIMESize는 multipart/byteranges 응답 본문의 전체 길이를 미리 계산합니다.
+// multipart 경계 문자열은 길이가 고정이므로 다른 writer로 세어도 결과가 같습니다.
+func rangesMIMESize(ranges []httpRange, contentType string, size int64) int64 {
+	var w countingWriter
+	mw := multipart.NewWriter(&w)
+	var encSize int64
+	for _, ra := range ranges {
+		_, _ = mw.CreatePart(ra.mimeHeader(contentType, size))
+		encSize += ra.length
+	}
+	_ = mw.Close()
+	return encSize + int64(w)
+}
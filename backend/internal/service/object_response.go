@@ -0,0 +1,348 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	httpctx "guiio/backend/internal/port/httpctx"
+
+	"github.com/sphynx/config"
+)
+
+// response-* 쿼리 파라미터는 S3 GetObject와 같이 이번 응답의 헤더만 바꾸고, 저장된 객체 속성은 그대로 둡니다.
+const (
+	responseContentTypeParam        = "response-content-type"
+	responseContentDispositionParam = "response-content-disposition"
+	responseCacheControlParam       = "response-cache-control"
+	responseExpiresParam            = "response-expires"
+)
+
+// response-* 쿼리는 서버 비밀 키(response_override_secret)로 서명한 링크에서만 받습니다.
+// 서명이 없으면 누구나 ?response-content-type=text/html 링크를 만들어 저장된 객체를 API 출처의 HTML로 보낼 수 있습니다.
+const (
+	responseSignatureParam   = "signature"
+	responseSignExpiresParam = "expires"
+	// responseSignMaxExpiry는 서명한 링크의 최대 유효 기간입니다.
+	responseSignMaxExpiry     = 7 * 24 * time.Hour
+	responseSignDefaultExpiry = time.Hour
+)
+
+// responseOverrideParams는 서명 대상 쿼리입니다. 빈 값도 순서대로 서명에 넣어, 서명 뒤에 파라미터를 더하면 검증에 실패합니다.
+var responseOverrideParams = []string{
+	responseContentTypeParam,
+	responseContentDispositionParam,
+	responseCacheControlParam,
+	responseExpiresParam,
+}
+
+var (
+	errResponseSignature    = errors.New("response-* overrides require a valid signature")
+	errResponseSignExpired  = errors.New("signed link has expired")
+	errResponseSignDisabled = errors.New("response-* overrides are disabled: response_override_secret is not set")
+)
+
+const (
+	dispositionInline     = "inline"
+	dispositionAttachment = "attachment"
+)
+
+// responseOverrides는 검증을 마친 response-* 값입니다. 빈 필드는 바꾸지 않습니다.
+type responseOverrides struct {
+	ContentType        string
+	ContentDisposition string
+	CacheControl       string
+	Expires            string
+}
+
+// SignDownloadRequest는 서명할 response-* 값입니다. 빈 값은 이번 응답에서 바꾸지 않습니다.
+type SignDownloadRequest struct {
+	ResponseContentType        string `json:"response_content_type,omitempty"`
+	ResponseContentDisposition string `json:"response_content_disposition,omitempty"`
+	ResponseCacheControl       string `json:"response_cache_control,omitempty"`
+	ResponseExpires            string `json:"response_expires,omitempty"`
+	// ExpiresIn은 링크 유효 기간(초)입니다. 0이면 1시간이고 최대 7일입니다.
+	ExpiresIn int `json:"expires_in,omitempty"`
+}
+
+type SignDownloadResponse struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// SignDownload는 response-* 값을 검증한 뒤 서명한 다운로드 경로를 반환합니다.
+func (s *StorageService) SignDownload(ctx httpctx.Context) {
+	bucketName := strings.TrimSpace(ctx.Param("bucketName"))
+	objectName := strings.TrimSpace(ctx.Param("objectName"))
+	if err := validateBucketName(bucketName); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err := validateObjectName(objectName); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	var req SignDownloadRequest
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+	expiry := responseSignDefaultExpiry
+	if req.ExpiresIn != 0 {
+		expiry = time.Duration(req.ExpiresIn) * time.Second
+	}
+	if expiry <= 0 || expiry > responseSignMaxExpiry {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "expires_in must be between 1 and 604800 seconds"})
+		return
+	}
+
+	query := url.Values{}
+	for name, v := range map[string]string{
+		responseContentTypeParam:        req.ResponseContentType,
+		responseContentDispositionParam: req.ResponseContentDisposition,
+		responseCacheControlParam:       req.ResponseCacheControl,
+		responseExpiresParam:            req.ResponseExpires,
+	} {
+		if v = strings.TrimSpace(v); v != "" {
+			query.Set(name, v)
+		}
+	}
+	if _, err := parseResponseOverrides(query.Get, objectName); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	secret := config.Get[string]("response_override_secret")
+	if secret == "" {
+		ctx.JSON(http.StatusForbidden, ErrorResponse{Error: errResponseSignDisabled.Error()})
+		return
+	}
+	expiresAt := time.Now().Add(expiry).Truncate(time.Second)
+	query.Set(responseSignExpiresParam, strconv.FormatInt(expiresAt.Unix(), 10))
+	query.Set(responseSignatureParam, signResponseOverrides(secret, bucketName, objectName, query.Get, expiresAt.Unix()))
+
+	ctx.JSON(http.StatusOK, SignDownloadResponse{
+		URL:       fmt.Sprintf("/api/v1/buckets/%s/objects/%s?%s", url.PathEscape(bucketName), url.PathEscape(objectName), query.Encode()),
+		ExpiresAt: expiresAt.UTC(),
+	})
+}
+
+// signResponseOverrides는 버킷, 객체 이름, 만료 시각, response-* 값에 대한 HMAC-SHA256을 hex로 반환합니다.
+func signResponseOverrides(secret, bucketName, objectName string, get func(string) string, expires int64) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%s\n%s\n%d\n", bucketName, objectName, expires)
+	for _, name := range responseOverrideParams {
+		fmt.Fprintf(mac, "%s=%s\n", name, get(name))
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verifyResponseSignature는 response-* 쿼리가 있을 때 서명과 만료 시각을 확인합니다. 없으면 아무것도 하지 않습니다.
+func verifyResponseSignature(ctx httpctx.Context, bucketName, objectName string) error {
+	requested := false
+	for _, name := range responseOverrideParams {
+		if ctx.Query(name) != "" {
+			requested = true
+			break
+		}
+	}
+	if !requested {
+		return nil
+	}
+
+	secret := config.Get[string]("response_override_secret")
+	if secret == "" {
+		return errResponseSignDisabled
+	}
+	expires, err := strconv.ParseInt(ctx.Query(responseSignExpiresParam), 10, 64)
+	if err != nil {
+		return errResponseSignature
+	}
+	got, err := hex.DecodeString(ctx.Query(responseSignatureParam))
+	if err != nil {
+		return errResponseSignature
+	}
+	want, _ := hex.DecodeString(signResponseOverrides(secret, bucketName, objectName, ctx.Query, expires))
+	if !hmac.Equal(got, want) {
+		return errResponseSignature
+	}
+	if time.Now().Unix() > expires {
+		return errResponseSignExpired
+	}
+	return nil
+}
+
+// responseOverridesFromQuery는 서명을 확인한 뒤 response-* 쿼리를 읽어 헤더 값으로 정리합니다.
+func responseOverridesFromQuery(ctx httpctx.Context, bucketName, objectName string) (responseOverrides, error) {
+	if err := verifyResponseSignature(ctx, bucketName, objectName); err != nil {
+		return responseOverrides{}, err
+	}
+	return parseResponseOverrides(ctx.Query, objectName)
+}
+
+// writeResponseOverrideError는 서명 에러는 403, 값 에러는 400으로 변환합니다.
+func writeResponseOverrideError(ctx httpctx.Context, err error) {
+	switch {
+	case errors.Is(err, errResponseSignature), errors.Is(err, errResponseSignExpired), errors.Is(err, errResponseSignDisabled):
+		ctx.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+	default:
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+	}
+}
+
+// parseResponseOverrides는 response-* 값을 검증해 헤더 값으로 정리합니다.
+// Content-Disposition의 파일 이름이 없고 attachment이면 객체 이름의 마지막 조각을 씁니다.
+func parseResponseOverrides(get func(string) string, objectName string) (responseOverrides, error) {
+	var o responseOverrides
+
+	if v := strings.TrimSpace(get(responseContentTypeParam)); v != "" {
+		mediaType, params, err := mime.ParseMediaType(v)
+		if err != nil {
+			return o, fmt.Errorf("invalid %s", responseContentTypeParam)
+		}
+		o.ContentType = mime.FormatMediaType(mediaType, params)
+	}
+
+	if v := strings.TrimSpace(get(responseContentDispositionParam)); v != "" {
+		dispType, filename, err := parseContentDisposition(v)
+		if err != nil {
+			return o, fmt.Errorf("invalid %s: %w", responseContentDispositionParam, err)
+		}
+		if filename == "" && dispType == dispositionAttachment {
+			filename = path.Base(objectName)
+		}
+		o.ContentDisposition = formatContentDisposition(dispType, filename)
+	}
+
+	if v := strings.TrimSpace(get(responseCacheControlParam)); v != "" {
+		if hasControlChar(v) {
+			return o, fmt.Errorf("invalid %s", responseCacheControlParam)
+		}
+		o.CacheControl = v
+	}
+
+	if v := strings.TrimSpace(get(responseExpiresParam)); v != "" {
+		t, err := http.ParseTime(v)
+		if err != nil {
+			return o, fmt.Errorf("invalid %s: must be an HTTP date", responseExpiresParam)
+		}
+		o.Expires = t.UTC().Format(http.TimeFormat)
+	}
+	return o, nil
+}
+
+// apply는 head의 복사본에 덮어쓴 값을 반환합니다.
+func (o responseOverrides) apply(head *objectHead) *objectHead {
+	h := *head
+	if o.ContentType != "" {
+		h.ContentType = o.ContentType
+	}
+	if o.CacheControl != "" {
+		h.CacheControl = o.CacheControl
+	}
+	h.ContentDisposition = o.ContentDisposition
+	h.Expires = o.Expires
+	return &h
+}
+
+// parseContentDisposition은 "attachment; filename=..." 값을 읽습니다.
+// 브라우저 링크에서는 filename에 한글을 따옴표 없이 그대로 넣는 경우가 많아,
+// mime.ParseMediaType(RFC 2231 filename* 해석 포함)이 실패하면 ';'와 '='로만 나눠 다시 읽습니다.
+func parseContentDisposition(v string) (string, string, error) {
+	dispType, params, err := mime.ParseMediaType(v)
+	if err != nil {
+		segments := strings.Split(v, ";")
+		dispType = strings.ToLower(strings.TrimSpace(segments[0]))
+		params = map[string]string{}
+		for _, seg := range segments[1:] {
+			key, value, ok := strings.Cut(seg, "=")
+			if !ok {
+				continue
+			}
+			params[strings.ToLower(strings.TrimSpace(key))] = strings.Trim(strings.TrimSpace(value), `"`)
+		}
+	}
+	if dispType != dispositionInline && dispType != dispositionAttachment {
+		return "", "", errors.New("type must be inline or attachment")
+	}
+
+	filename := params["filename"]
+	if !utf8.ValidString(filename) || hasControlChar(filename) {
+		return "", "", errors.New("filename contains invalid characters")
+	}
+	return dispType, filename, nil
+}
+
+// formatContentDisposition은 RFC 6266 형식으로 씁니다.
+// ASCII 이름은 filename만, 그 밖의 이름(한글 등)은 구형 클라이언트용 ASCII filename과 UTF-8 filename*을 함께 씁니다.
+func formatContentDisposition(dispType, filename string) string {
+	if filename == "" {
+		return dispType
+	}
+	if isPrintableASCII(filename) {
+		if v := mime.FormatMediaType(dispType, map[string]string{"filename": filename}); v != "" {
+			return v
+		}
+	}
+	return fmt.Sprintf(`%s; filename="%s"; filename*=UTF-8''%s`, dispType, asciiFilename(filename), encodeRFC5987(filename))
+}
+
+// asciiFilename은 ASCII가 아니거나 따옴표 안에서 문제가 되는 문자를 '_'로 바꿉니다. 확장자는 대개 그대로 남습니다.
+func asciiFilename(name string) string {
+	var b strings.Builder
+	for _, r := range name {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			b.WriteByte('_')
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// encodeRFC5987은 RFC 5987 attr-char가 아닌 바이트를 %XX로 인코딩합니다.
+func encodeRFC5987(s string) string {
+	const hex = "0123456789ABCDEF"
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9') || strings.IndexByte("!#$&+-.^_`|~", c) >= 0 {
+			b.WriteByte(c)
+			continue
+		}
+		b.WriteByte('%')
+		b.WriteByte(hex[c>>4])
+		b.WriteByte(hex[c&0x0f])
+	}
+	return b.String()
+}
+
+func isPrintableASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+// hasControlChar는 헤더 값에 넣으면 응답을 깨뜨릴 수 있는 제어 문자(CR, LF 등)가 있는지 확인합니다.
+func hasControlChar(s string) bool {
+	for _, r := range s {
+		if r < 0x20 || r == 0x7f {
+			return true
+		}
+	}
+	return false
+}
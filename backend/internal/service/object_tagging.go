@@ -0,0 +1,158 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"guiio/backend/ent"
+	httpctx "guiio/backend/internal/port/httpctx"
+)
+
+// 태그 제한은 S3 객체 태그와 같습니다.
+const (
+	maxObjectTags     = 10
+	maxTagKeyLength   = 128
+	maxTagValueLength = 256
+)
+
+type ObjectTaggingRequest struct {
+	Tags map[string]string `json:"tags"`
+}
+
+type ObjectTaggingResponse struct {
+	Bucket string            `json:"bucket"`
+	Key    string            `json:"key"`
+	Tags   map[string]string `json:"tags"`
+}
+
+func (s *StorageService) GetObjectTagging(ctx httpctx.Context) {
+	bucketName, objectName, ok := s.taggingObject(ctx)
+	if !ok {
+		return
+	}
+
+	tags, err := s.repo.GetObjectTags(ctx.Context(), bucketName, objectName)
+	if err != nil {
+		writeTaggingError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, ObjectTaggingResponse{Bucket: bucketName, Key: objectName, Tags: tags})
+}
+
+// PutObjectTagging은 객체의 태그 전체를 요청 값으로 대체합니다.
+func (s *StorageService) PutObjectTagging(ctx httpctx.Context) {
+	bucketName, objectName, ok := s.taggingObject(ctx)
+	if !ok {
+		return
+	}
+
+	var req ObjectTaggingRequest
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+	if req.Tags == nil {
+		req.Tags = map[string]string{}
+	}
+	if err := validateTags(req.Tags); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	if err := s.repo.PutObjectTags(ctx.Context(), bucketName, objectName, req.Tags); err != nil {
+		writeTaggingError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, ObjectTaggingResponse{Bucket: bucketName, Key: objectName, Tags: req.Tags})
+}
+
+func (s *StorageService) DeleteObjectTagging(ctx httpctx.Context) {
+	bucketName, objectName, ok := s.taggingObject(ctx)
+	if !ok {
+		return
+	}
+
+	if err := s.repo.PutObjectTags(ctx.Context(), bucketName, objectName, nil); err != nil {
+		writeTaggingError(ctx, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, ObjectTaggingResponse{Bucket: bucketName, Key: objectName, Tags: map[string]string{}})
+}
+
+// taggingObject는 태그 API의 공통 검증(버킷/객체 이름, repository)을 처리합니다.
+func (s *StorageService) taggingObject(ctx httpctx.Context) (string, string, bool) {
+	bucketName := strings.TrimSpace(ctx.Param("bucketName"))
+	objectName := strings.TrimSpace(ctx.Param("objectName"))
+	if err := validateBucketName(bucketName); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return "", "", false
+	}
+	if err := validateObjectName(objectName); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return "", "", false
+	}
+	if s.repo == nil {
+		ctx.JSON(http.StatusNotImplemented, ErrorResponse{Error: "tagging requires object repository"})
+		return "", "", false
+	}
+	return bucketName, objectName, true
+}
+
+func writeTaggingError(ctx httpctx.Context, err error) {
+	if ent.IsNotFound(err) {
+		ctx.JSON(http.StatusNotFound, ErrorResponse{Error: errObjectNotFound.Error()})
+		return
+	}
+	ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("tagging failed: %v", err)})
+}
+
+// validateTags는 태그 개수와 키/값 길이, 허용 문자(문자, 숫자, 공백, + - = . _ : / @)를 확인합니다.
+func validateTags(tags map[string]string) error {
+	if len(tags) > maxObjectTags {
+		return fmt.Errorf("cannot set more than %d tags", maxObjectTags)
+	}
+	for k, v := range tags {
+		if k == "" {
+			return errors.New("tag key is required")
+		}
+		if utf8.RuneCountInString(k) > maxTagKeyLength {
+			return fmt.Errorf("tag key %q exceeds %d characters", k, maxTagKeyLength)
+		}
+		if utf8.RuneCountInString(v) > maxTagValueLength {
+			return fmt.Errorf("tag %q value exceeds %d characters", k, maxTagValueLength)
+		}
+		if !isTagText(k) || !isTagText(v) {
+			return fmt.Errorf("tag %q contains invalid characters", k)
+		}
+	}
+	return nil
+}
+
+func isTagText(s string) bool {
+	if !utf8.ValidString(s) {
+		return false
+	}
+	for _, r := range s {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) || r == ' ' || strings.ContainsRune("+-=._:/@", r) {
+			continue
+		}
+		return false
+	}
+	return true
+}
+
+// parseTagFilter는 목록 조회의 tag=k:v 쿼리를 첫 번째 ':' 기준으로 나눕니다.
+func parseTagFilter(raw string) (map[string]string, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	k, v, ok := strings.Cut(raw, ":")
+	if !ok || k == "" {
+		return nil, errors.New("tag filter must be key:value")
+	}
+	return map[string]string{k: v}, nil
+}
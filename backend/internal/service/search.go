@@ -0,0 +1,322 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	httpctx "guiio/backend/internal/port/httpctx"
+	"guiio/backend/internal/repository"
+)
+
+type SearchObjectResult struct {
+	Bucket       string            `json:"bucket"`
+	Key          string            `json:"key"`
+	Size         int64             `json:"size"`
+	ContentType  string            `json:"content_type,omitempty"`
+	ETag         string            `json:"etag"`
+	CreatedAt    time.Time         `json:"created_at"`
+	LastModified time.Time         `json:"last_modified"`
+	Metadata     map[string]string `json:"metadata,omitempty"`
+	// Highlights는 q로 검색했을 때 일치한 필드("name", "metadata.<key>")에 <mark>를 씌운 값입니다.
+	Highlights map[string]string `json:"highlights,omitempty"`
+	// Rank는 관련도 순 결과의 순위(1부터)입니다.
+	Rank int `json:"rank,omitempty"`
+}
+
+type SearchObjectsResponse struct {
+	Bucket      string               `json:"bucket,omitempty"`
+	Objects     []SearchObjectResult `json:"objects"`
+	IsTruncated bool                 `json:"is_truncated"`
+	NextCursor  string               `json:"next_cursor,omitempty"`
+}
+
+// SearchObjects는 메타데이터, Content-Type, 크기, 생성/수정 시각, 이름 패턴으로 객체를 찾습니다.
+// q가 있으면 이름 조각과 메타데이터 값을 전문 검색해 관련도 순으로 반환하고 일치한 필드를 강조합니다.
+// /buckets/{bucketName}/search는 해당 버킷만, /search는 bucket 쿼리가 없으면 모든 버킷을 검색합니다.
+func (s *StorageService) SearchObjects(ctx httpctx.Context) {
+	bucketName := strings.TrimSpace(ctx.Param("bucketName"))
+	if bucketName == "" {
+		bucketName = strings.TrimSpace(ctx.Query("bucket"))
+	}
+	if bucketName != "" {
+		if err := validateBucketName(bucketName); err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+			return
+		}
+	}
+	if s.repo == nil {
+		ctx.JSON(http.StatusNotImplemented, ErrorResponse{Error: "search requires object repository"})
+		return
+	}
+
+	in, err := parseSearchInput(ctx)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	in.BucketName = bucketName
+
+	reqCtx := ctx.Context()
+	if bucketName != "" {
+		exists, err := s.client.BucketExists(reqCtx, bucketName)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("check bucket failed: %v", err)})
+			return
+		}
+		if !exists {
+			ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "bucket not found"})
+			return
+		}
+	}
+
+	result, err := s.repo.SearchObjects(reqCtx, in)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("search failed: %v", err)})
+		return
+	}
+
+	resp := SearchObjectsResponse{
+		Bucket:  bucketName,
+		Objects: make([]SearchObjectResult, 0, len(result.Objects)),
+	}
+	terms := highlightTerms(in.Query)
+	offset := 0
+	if in.After != nil {
+		offset = in.After.Offset
+	}
+	for i, obj := range result.Objects {
+		meta := make(map[string]string, len(obj.Edges.Metadata))
+		for _, m := range obj.Edges.Metadata {
+			meta[m.Key] = m.Value
+		}
+		item := SearchObjectResult{
+			Bucket:       obj.BucketName,
+			Key:          obj.ObjectName,
+			Size:         obj.Size,
+			ContentType:  obj.ContentType,
+			ETag:         obj.Etag,
+			CreatedAt:    obj.CreatedAt,
+			LastModified: obj.UpdatedAt,
+			Metadata:     meta,
+		}
+		if len(terms) > 0 {
+			item.Highlights = map[string]string{}
+			if h, ok := highlightText(obj.ObjectName, terms); ok {
+				item.Highlights["name"] = h
+			}
+			for k, v := range meta {
+				if h, ok := highlightText(v, terms); ok {
+					item.Highlights["metadata."+k] = h
+				}
+			}
+		}
+		if in.Sort == repository.SearchSortRelevance {
+			item.Rank = offset + i + 1
+		}
+		resp.Objects = append(resp.Objects, item)
+	}
+	if result.Next != nil {
+		resp.IsTruncated = true
+		resp.NextCursor = encodeSearchCursor(result.Next)
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// parseSearchInput은 검색 쿼리 파라미터를 repository 조건으로 바꿉니다.
+// meta, meta_prefix는 key:value 형식이며 여러 번 줄 수 있습니다.
+func parseSearchInput(ctx httpctx.Context) (repository.ObjectSearchInput, error) {
+	in := repository.ObjectSearchInput{
+		Query:       strings.TrimSpace(ctx.Query("q")),
+		NameGlob:    ctx.Query("name"),
+		ContentType: strings.TrimSpace(ctx.Query("content_type")),
+	}
+
+	var err error
+	if in.Limit, err = parseListLimit(ctx.Query("limit")); err != nil {
+		return in, err
+	}
+	if in.Metadata, err = parseMetadataFilters(queryValues(ctx, "meta")); err != nil {
+		return in, err
+	}
+	if in.MetadataPrefix, err = parseMetadataFilters(queryValues(ctx, "meta_prefix")); err != nil {
+		return in, err
+	}
+	if in.MinSize, err = parseSizeFilter("min_size", ctx.Query("min_size")); err != nil {
+		return in, err
+	}
+	if in.MaxSize, err = parseSizeFilter("max_size", ctx.Query("max_size")); err != nil {
+		return in, err
+	}
+	for _, f := range []struct {
+		name string
+		dst  **time.Time
+	}{
+		{"created_after", &in.CreatedAfter},
+		{"created_before", &in.CreatedBefore},
+		{"updated_after", &in.UpdatedAfter},
+		{"updated_before", &in.UpdatedBefore},
+	} {
+		if *f.dst, err = parseTimeFilter(f.name, ctx.Query(f.name)); err != nil {
+			return in, err
+		}
+	}
+
+	switch in.Sort = strings.ToLower(strings.TrimSpace(ctx.Query("sort"))); in.Sort {
+	case "":
+		in.Sort = repository.SearchSortName
+		if in.Query != "" {
+			in.Sort = repository.SearchSortRelevance
+		}
+	case repository.SearchSortRelevance:
+		if in.Query == "" {
+			return in, errors.New("sort=relevance requires q")
+		}
+	case repository.SearchSortName, repository.SearchSortSize, repository.SearchSortCreated, repository.SearchSortUpdated:
+	default:
+		return in, errors.New("sort must be one of relevance, name, size, created, updated")
+	}
+	switch strings.ToLower(strings.TrimSpace(ctx.Query("order"))) {
+	case "", "asc":
+	case "desc":
+		in.Desc = true
+	default:
+		return in, errors.New("order must be asc or desc")
+	}
+
+	if in.After, err = decodeSearchCursor(ctx.Query("cursor")); err != nil {
+		return in, err
+	}
+	return in, nil
+}
+
+// parseMetadataFilters는 key:value 목록을 맵으로 바꿉니다. 메타데이터 키는 소문자로 저장되므로 키를 소문자로 맞춥니다.
+func parseMetadataFilters(values []string) (map[string]string, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	filters := make(map[string]string, len(values))
+	for _, raw := range values {
+		k, v, ok := strings.Cut(raw, ":")
+		k = strings.ToLower(strings.TrimSpace(k))
+		if !ok || k == "" {
+			return nil, fmt.Errorf("metadata filter %q must be key:value", raw)
+		}
+		filters[k] = v
+	}
+	return filters, nil
+}
+
+func parseSizeFilter(name, raw string) (*int64, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	n, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("%s must be a non-negative integer", name)
+	}
+	return &n, nil
+}
+
+func parseTimeFilter(name, raw string) (*time.Time, error) {
+	if raw == "" {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return nil, fmt.Errorf("%s must be an RFC 3339 timestamp", name)
+	}
+	return &t, nil
+}
+
+// highlightTerms는 q에서 강조할 단어를 고릅니다. OR 연산자와 -로 제외한 단어는 빼고
+// 인덱스와 같은 SearchTokens 규칙으로 나눕니다.
+func highlightTerms(q string) map[string]struct{} {
+	terms := map[string]struct{}{}
+	for _, field := range strings.Fields(q) {
+		if strings.HasPrefix(field, "-") || field == "OR" {
+			continue
+		}
+		for _, t := range repository.SearchTokens(field) {
+			terms[t] = struct{}{}
+		}
+	}
+	return terms
+}
+
+// highlightText는 text에서 terms에 있는 단어를 <mark>로 감쌉니다. 일치한 단어가 없으면 false를 반환합니다.
+// 결과는 화면에 그대로 렌더링하는 HTML 조각이므로 <mark> 밖의 문자와 일치한 단어를 모두 이스케이프합니다.
+func highlightText(text string, terms map[string]struct{}) (string, bool) {
+	var b strings.Builder
+	matched := false
+	start := -1
+	flush := func(end int) {
+		word := text[start:end]
+		if _, ok := terms[strings.ToLower(word)]; ok {
+			b.WriteString("<mark>" + html.EscapeString(word) + "</mark>")
+			matched = true
+		} else {
+			b.WriteString(html.EscapeString(word))
+		}
+		start = -1
+	}
+	for i, r := range text {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			flush(i)
+		}
+		b.WriteString(html.EscapeString(string(r)))
+	}
+	if start >= 0 {
+		flush(len(text))
+	}
+	return b.String(), matched
+}
+
+// queryValues는 같은 이름으로 여러 번 온 쿼리 파라미터를 모두 반환합니다.
+func queryValues(ctx httpctx.Context, name string) []string {
+	if r := ctx.Request(); r != nil && r.URL != nil {
+		return r.URL.Query()[name]
+	}
+	if v := ctx.Query(name); v != "" {
+		return []string{v}
+	}
+	return nil
+}
+
+func encodeSearchCursor(c *repository.ObjectSearchCursor) string {
+	raw, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeSearchCursor(cursor string) (*repository.ObjectSearchCursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	var c repository.ObjectSearchCursor
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+	// 조작한 커서의 음수 offset은 DB 에러(500)가 되므로 여기서 거부합니다.
+	if c.Offset < 0 {
+		return nil, errors.New("invalid cursor")
+	}
+	return &c, nil
+}
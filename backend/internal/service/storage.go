@@ -9,10 +9,11 @@ import (
 	"net/http"
 	"net/url"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
-	"guiio/backend/ent"
 	httpctx "guiio/backend/internal/port/httpctx"
 	"guiio/backend/internal/repository"
 
@@ -25,6 +26,8 @@ type StorageService struct {
 	client        StorageClient
 	defaultRegion string
 	repo          repository.ObjectRepository
+	// tusLocks는 PATCH/DELETE 중인 tus 업로드 키(bucket/uploadId)를 담습니다.
+	tusLocks sync.Map
 }
 
 type minioWrapper struct {
@@ -59,6 +62,38 @@ func (m *minioWrapper) StatObject(ctx context.Context, bucketName, objectName st
 	return m.c.StatObject(ctx, bucketName, objectName, opts)
 }
 
+func (m *minioWrapper) ListObjects(ctx context.Context, bucketName string, opts minio.ListObjectsOptions) <-chan minio.ObjectInfo {
+	return m.c.ListObjects(ctx, bucketName, opts)
+}
+
+func (m *minioWrapper) RemoveObject(ctx context.Context, bucketName, objectName string, opts minio.RemoveObjectOptions) error {
+	return m.c.RemoveObject(ctx, bucketName, objectName, opts)
+}
+
+func (m *minioWrapper) CopyObject(ctx context.Context, dst minio.CopyDestOptions, src minio.CopySrcOptions) (minio.UploadInfo, error) {
+	return m.c.CopyObject(ctx, dst, src)
+}
+
+func (m *minioWrapper) ComposeObject(ctx context.Context, dst minio.CopyDestOptions, srcs ...minio.CopySrcOptions) (minio.UploadInfo, error) {
+	return m.c.ComposeObject(ctx, dst, srcs...)
+}
+
+func (m *minioWrapper) NewMultipartUpload(ctx context.Context, bucketName, objectName string, opts minio.PutObjectOptions) (string, error) {
+	return minio.Core{Client: m.c}.NewMultipartUpload(ctx, bucketName, objectName, opts)
+}
+
+func (m *minioWrapper) PutObjectPart(ctx context.Context, bucketName, objectName, uploadID string, partNumber int, reader io.Reader, size int64) (minio.ObjectPart, error) {
+	return minio.Core{Client: m.c}.PutObjectPart(ctx, bucketName, objectName, uploadID, partNumber, reader, size, minio.PutObjectPartOptions{})
+}
+
+func (m *minioWrapper) CompleteMultipartUpload(ctx context.Context, bucketName, objectName, uploadID string, parts []minio.CompletePart, opts minio.PutObjectOptions) (minio.UploadInfo, error) {
+	return minio.Core{Client: m.c}.CompleteMultipartUpload(ctx, bucketName, objectName, uploadID, parts, opts)
+}
+
+func (m *minioWrapper) AbortMultipartUpload(ctx context.Context, bucketName, objectName, uploadID string) error {
+	return minio.Core{Client: m.c}.AbortMultipartUpload(ctx, bucketName, objectName, uploadID)
+}
+
 type StorageClient interface {
 	ListBuckets(ctx context.Context) ([]minio.BucketInfo, error)
 	BucketExists(ctx context.Context, bucketName string) (bool, error)
@@ -67,6 +102,14 @@ type StorageClient interface {
 	PutObject(ctx context.Context, bucketName, objectName string, reader io.Reader, objectSize int64, opts minio.PutObjectOptions) (minio.UploadInfo, error)
 	GetObject(ctx context.Context, bucketName, objectName string, opts minio.GetObjectOptions) (io.ReadCloser, error)
 	StatObject(ctx context.Context, bucketName, objectName string, opts minio.StatObjectOptions) (minio.ObjectInfo, error)
+	ListObjects(ctx context.Context, bucketName string, opts minio.ListObjectsOptions) <-chan minio.ObjectInfo
+	RemoveObject(ctx context.Context, bucketName, objectName string, opts minio.RemoveObjectOptions) error
+	CopyObject(ctx context.Context, dst minio.CopyDestOptions, src minio.CopySrcOptions) (minio.UploadInfo, error)
+	ComposeObject(ctx context.Context, dst minio.CopyDestOptions, srcs ...minio.CopySrcOptions) (minio.UploadInfo, error)
+	NewMultipartUpload(ctx context.Context, bucketName, objectName string, opts minio.PutObjectOptions) (string, error)
+	PutObjectPart(ctx context.Context, bucketName, objectName, uploadID string, partNumber int, reader io.Reader, size int64) (minio.ObjectPart, error)
+	CompleteMultipartUpload(ctx context.Context, bucketName, objectName, uploadID string, parts []minio.CompletePart, opts minio.PutObjectOptions) (minio.UploadInfo, error)
+	AbortMultipartUpload(ctx context.Context, bucketName, objectName, uploadID string) error
 }
 
 type BucketInfo struct {
@@ -96,9 +139,15 @@ type UploadObjectResponse struct {
 	Bucket      string `json:"bucket"`
 	Object      string `json:"object"`
 	ContentType string `json:"content_type"`
-	Size        int64  `json:"size"`
-	ETag        string `json:"etag"`
-	StoragePath string `json:"storage_path"`
+	// declared, detected, default 중 하나입니다. 본문을 판별하지 않는 응답에는 없습니다.
+	ContentTypeSource string `json:"content_type_source,omitempty"`
+	Size              int64  `json:"size"`
+	ETag              string `json:"etag"`
+	StoragePath       string `json:"storage_path"`
+	VersionID         string `json:"version_id,omitempty"`
+	// 서버가 본문을 읽어 계산한 체크섬(base64)입니다. 멀티파트 업로드 결과에는 content-addressable 버킷일 때만 있습니다.
+	ChecksumSHA256 string `json:"checksum_sha256,omitempty"`
+	ChecksumCRC32C string `json:"checksum_crc32c,omitempty"`
 }
 
 type CreateBucketRequest struct {
@@ -147,6 +196,9 @@ func (s *StorageService) ListBucket(ctx httpctx.Context) error {
 
 	result := make([]BucketInfo, 0, len(buckets))
 	for _, b := range buckets {
+		if b.Name == blobBucket() {
+			continue
+		}
 		result = append(result, BucketInfo{
 			Name:      b.Name,
 			CreatedAt: b.CreationDate,
@@ -272,13 +324,23 @@ func validateBucketName(name string) error {
 		return errors.New("bucket name cannot contain consecutive dots")
 	}
 
+	if name == blobBucket() {
+		return errors.New("bucket name is reserved")
+	}
+
 	return nil
 }
 
+// systemKeyPrefix 아래 키는 버전 복사본처럼 guiio가 내부적으로 쓰는 백엔드 객체입니다.
+const systemKeyPrefix = ".guiio/"
+
 func validateObjectName(name string) error {
 	if strings.TrimSpace(name) == "" {
 		return errors.New("object name is required")
 	}
+	if strings.HasPrefix(name, systemKeyPrefix) {
+		return fmt.Errorf("object name must not start with %s", systemKeyPrefix)
+	}
 	return nil
 }
 
@@ -311,103 +373,103 @@ func (s *StorageService) DownloadObject(ctx httpctx.Context) {
 		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
-	encodedName := encodeObjectKey(objectName)
-	cacheControl := config.Get[string]("object_cache_control")
 	req := ctx.Request()
 
-	if s.repo != nil {
-		if obj, err := s.repo.GetObject(ctx.Context(), bucketName, objectName); err == nil {
-			etag := obj.Etag
-			storageKey := normalizeStoragePath(bucketName, obj.StoragePath, encodedName)
-			if cacheControl != "" {
-				ctx.SetHeader("Cache-Control", cacheControl)
-			}
-			ctx.SetHeader("ETag", etag)
-			ctx.SetHeader("Last-Modified", obj.UpdatedAt.UTC().Format(http.TimeFormat))
-
-			if req != nil {
-				clientETag := strings.Trim(req.Header.Get("If-None-Match"), "\"")
-				serverETag := strings.Trim(etag, "\"")
-				if clientETag != "" && clientETag == serverETag {
-					_ = ctx.Stream(http.StatusNotModified, "", bytes.NewReader(nil))
-					return
-				}
-				if ims := req.Header.Get("If-Modified-Since"); ims != "" {
-					if t, err := http.ParseTime(ims); err == nil && !obj.UpdatedAt.After(t) {
-						_ = ctx.Stream(http.StatusNotModified, "", bytes.NewReader(nil))
-						return
-					}
-				}
-			}
-
-			objReader, err := s.client.GetObject(ctx.Context(), bucketName, storageKey, minio.GetObjectOptions{})
-			if err != nil {
-				ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("download failed: %v", err)})
-				return
-			}
-			defer objReader.Close()
-
-			if obj.ContentType != "" {
-				ctx.SetHeader("Content-Type", obj.ContentType)
-			}
-			ctx.SetHeader("Content-Length", fmt.Sprintf("%d", obj.Size))
-
-			if err := ctx.Stream(http.StatusOK, obj.ContentType, objReader); err != nil {
-				ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("stream failed: %v", err)})
-				return
-			}
-			return
-		} else if !ent.IsNotFound(err) {
-			ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("get object metadata failed: %v", err)})
+	if req != nil && req.URL.Query().Has("stat") {
+		s.statObjectJSON(ctx, bucketName, objectName)
+		return
+	}
+
+	overrides, err := responseOverridesFromQuery(ctx, bucketName, objectName)
+	if err != nil {
+		writeResponseOverrideError(ctx, err)
+		return
+	}
+	resize := isImageResizeRequest(ctx)
+	var spec imageResizeSpec
+	if resize {
+		if spec, err = parseImageResizeSpec(ctx); err != nil {
+			ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 			return
 		}
 	}
 
-	info, err := s.client.StatObject(ctx.Context(), bucketName, encodedName, minio.StatObjectOptions{})
-	if err != nil {
-		ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "object not found"})
+	head, ok := s.lookupObject(ctx, bucketName, objectName)
+	if !ok {
+		return
+	}
+	head = overrides.apply(head)
+	if resize {
+		s.serveImageDerivative(ctx, bucketName, objectName, head, spec)
 		return
 	}
 
-	ctx.SetHeader("ETag", info.ETag)
-	if cacheControl != "" {
-		ctx.SetHeader("Cache-Control", cacheControl)
+	// 압축 여부는 ETag를 정하므로 조건부 요청 확인보다 먼저 고릅니다. Range 요청은 원본 바이트 구간이어야 하므로 압축하지 않습니다.
+	rangeHeader := ctx.GetHeader("Range")
+	var encoding string
+	if compressible(head.ContentType, head.Size) {
+		ctx.SetHeader("Vary", "Accept-Encoding")
+		if rangeHeader == "" {
+			encoding = negotiateEncoding(ctx.GetHeader("Accept-Encoding"))
+		}
+	}
+	if encoding != "" {
+		head.ETag = weakETag(head.ETag)
 	}
-	ctx.SetHeader("Last-Modified", info.LastModified.UTC().Format(http.TimeFormat))
 
-	if req != nil {
-		clientETag := strings.Trim(req.Header.Get("If-None-Match"), "\"")
-		serverETag := strings.Trim(info.ETag, "\"")
-		if clientETag != "" && clientETag == serverETag {
-			_ = ctx.Stream(http.StatusNotModified, "", bytes.NewReader(nil))
+	writeObjectHeaders(ctx, head)
+	if isNotModified(req, head.ETag, head.LastModified) {
+		_ = ctx.Stream(http.StatusNotModified, "", bytes.NewReader(nil))
+		return
+	}
+
+	ctx.SetHeader("Accept-Ranges", "bytes")
+	if rangeHeader != "" && ifRangeMatches(ctx.GetHeader("If-Range"), head.ETag, head.LastModified) {
+		if s.serveRanges(ctx, bucketName, head, rangeHeader) {
 			return
 		}
-		if ims := req.Header.Get("If-Modified-Since"); ims != "" {
-			if t, err := http.ParseTime(ims); err == nil && !info.LastModified.After(t) {
-				_ = ctx.Stream(http.StatusNotModified, "", bytes.NewReader(nil))
-				return
-			}
-		}
 	}
 
-	obj, err := s.client.GetObject(ctx.Context(), bucketName, encodedName, minio.GetObjectOptions{})
+	objReader, err := s.client.GetObject(ctx.Context(), storageBucket(bucketName, head.StorageKey), head.StorageKey, minio.GetObjectOptions{})
 	if err != nil {
 		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("download failed: %v", err)})
 		return
 	}
-	defer obj.Close()
+	defer objReader.Close()
 
-	if info.ContentType != "" {
-		ctx.SetHeader("Content-Type", info.ContentType)
+	if head.ContentType != "" {
+		ctx.SetHeader("Content-Type", head.ContentType)
 	}
-	ctx.SetHeader("Content-Length", fmt.Sprintf("%d", info.Size))
 
-	if err := ctx.Stream(http.StatusOK, info.ContentType, obj); err != nil {
+	var body io.Reader = objReader
+	if encoding != "" {
+		// 압축한 길이는 미리 알 수 없으므로 Content-Length 없이 보냅니다.
+		compressed := compressStream(encoding, objReader)
+		defer compressed.Close()
+		ctx.SetHeader("Content-Encoding", encoding)
+		body = compressed
+	} else {
+		ctx.SetHeader("Content-Length", fmt.Sprintf("%d", head.Size))
+	}
+
+	if err := ctx.Stream(http.StatusOK, head.ContentType, body); err != nil {
 		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("stream failed: %v", err)})
 		return
 	}
 }
 
+// formMetadata는 meta- 접두사가 붙은 폼 필드를 메타데이터로 모읍니다.
+func formMetadata(r *http.Request) map[string]string {
+	metadata := map[string]string{}
+	for k, vals := range r.PostForm {
+		if strings.HasPrefix(k, "meta-") && len(vals) > 0 {
+			metadata[strings.TrimPrefix(k, "meta-")] = vals[0]
+		}
+	}
+	return metadata
+}
+
+// UploadObject는 멀티파트 폼의 file을 객체로 저장합니다. extract=true이면 file을 아카이브로 보고 prefix 아래에 풉니다.
 func (s *StorageService) UploadObject(ctx httpctx.Context) {
 	bucketName := strings.TrimSpace(ctx.Param("bucketName"))
 	if err := validateBucketName(bucketName); err != nil {
@@ -428,6 +490,18 @@ func (s *StorageService) UploadObject(ctx httpctx.Context) {
 	}
 	defer file.Close()
 
+	checksums, err := checksumsFromHeader(r.Header, false)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	metadata := formMetadata(r)
+	if extract, _ := strconv.ParseBool(ctx.Query("extract")); extract {
+		s.extractUpload(ctx, bucketName, file, header, metadata)
+		return
+	}
+
 	objectName := strings.TrimSpace(r.FormValue("objectName"))
 	if objectName == "" {
 		objectName = header.Filename
@@ -436,19 +510,6 @@ func (s *StorageService) UploadObject(ctx httpctx.Context) {
 		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
 		return
 	}
-	encodedName := encodeObjectKey(objectName)
-
-	metadata := map[string]string{}
-	for k, vals := range r.PostForm {
-		if strings.HasPrefix(k, "meta-") && len(vals) > 0 {
-			metadata[strings.TrimPrefix(k, "meta-")] = vals[0]
-		}
-	}
-
-	contentType := header.Header.Get("Content-Type")
-	if contentType == "" {
-		contentType = "application/octet-stream"
-	}
 
 	// We need size; use header.Size if available, else read into buffer
 	var reader io.Reader = file
@@ -464,31 +525,20 @@ func (s *StorageService) UploadObject(ctx httpctx.Context) {
 		reader = bytes.NewReader(data)
 	}
 
-	uinfo, err := s.client.PutObject(ctx.Context(), bucketName, encodedName, reader, size, minio.PutObjectOptions{ContentType: contentType})
+	resp, err := s.storeObject(ctx.Context(), putObjectInput{
+		BucketName:  bucketName,
+		ObjectName:  objectName,
+		ContentType: header.Header.Get("Content-Type"),
+		Size:        size,
+		Metadata:    metadata,
+		Body:        reader,
+		Condition:   writeConditionFromRequest(r),
+		Checksums:   checksums,
+	})
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("upload failed: %v", err)})
+		writeStoreError(ctx, err)
 		return
 	}
 
-	storagePath := encodedName
-	if s.repo != nil {
-		_, _ = s.repo.UpsertObject(ctx.Context(), repository.ObjectUpsertInput{
-			BucketName:  bucketName,
-			ObjectName:  objectName,
-			StoragePath: storagePath,
-			ContentType: contentType,
-			Size:        uinfo.Size,
-			ETag:        uinfo.ETag,
-			Metadata:    metadata,
-		})
-	}
-
-	ctx.JSON(http.StatusCreated, UploadObjectResponse{
-		Bucket:      bucketName,
-		Object:      objectName,
-		ContentType: contentType,
-		Size:        uinfo.Size,
-		ETag:        uinfo.ETag,
-		StoragePath: storagePath,
-	})
+	ctx.JSON(http.StatusCreated, resp)
 }
@@ -1,31 +1,90 @@
 package service
 
 import (
+	"archive/tar"
+	"archive/zip"
 	"bytes"
+	"compress/gzip"
 	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"hash/crc32"
+	"image"
+	"image/png"
 	"io"
+	"mime/multipart"
 	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"net/url"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 
+	"guiio/backend/ent"
+	"guiio/backend/internal/repository"
+
 	"github.com/minio/minio-go/v7"
+	"github.com/sphynx/config"
 )
 
+func TestMain(m *testing.M) {
+	tusDir, err := os.MkdirTemp("", "guiio-tus-test")
+	if err != nil {
+		panic(err)
+	}
+	config.NewConfig(map[string]config.ConfigValue[any]{
+		"object_cache_control":          {Value: "public, max-age=60"},
+		"object_max_upload_size":        {Value: int64(1 << 20)},
+		"tus_upload_dir":                {Value: tusDir},
+		"tus_upload_expiry_hours":       {Value: 24},
+		"trash_retention_hours":         {Value: 168},
+		"archive_max_objects":           {Value: 4},
+		"archive_max_size":              {Value: int64(1 << 20)},
+		"extract_max_entries":           {Value: 4},
+		"extract_max_size":              {Value: int64(1 << 20)},
+		"download_compression_types":    {Value: "text/*,application/json,image/*"},
+		"download_compression_min_size": {Value: int64(64)},
+		"image_resize_sizes":            {Value: "64,128"},
+		"image_resize_max_pixels":       {Value: int64(1 << 20)},
+		"object_lock_bypass_token":      {Value: "bypass-token"},
+		"response_override_secret":      {Value: "override-secret"},
+		"blob_bucket":                   {Value: "guiio-blobs"},
+	})
+	code := m.Run()
+	os.RemoveAll(tusDir)
+	os.Exit(code)
+}
+
 type fakeStorageClient struct {
-	listResp     []minio.BucketInfo
-	listErr      error
-	existsMap    map[string]bool
-	existsErr    error
-	makeErr      error
-	removeErr    error
-	makeCalled   []string
-	removeCalled []string
-	putCalled    []string
-	putErr       error
-	objects      map[string][]byte
+	listResp      []minio.BucketInfo
+	listErr       error
+	existsMap     map[string]bool
+	existsErr     error
+	makeErr       error
+	removeErr     error
+	makeCalled    []string
+	removeCalled  []string
+	putCalled     []string
+	putOpts       []minio.PutObjectOptions
+	putErr        error
+	putHook       func(key string)
+	composeCalled []string
+	statErr       error
+	objects       map[string][]byte
+	userMeta      map[string]map[string]string
+	uploads       map[string]map[int][]byte
+	mu            sync.Mutex
 }
 
 func (f *fakeStorageClient) ListBuckets(_ context.Context) ([]minio.BucketInfo, error) {
@@ -49,12 +108,18 @@ func (f *fakeStorageClient) RemoveBucket(_ context.Context, bucketName string) e
 	return f.removeErr
 }
 
-func (f *fakeStorageClient) PutObject(_ context.Context, bucketName, objectName string, reader io.Reader, objectSize int64, _ minio.PutObjectOptions) (minio.UploadInfo, error) {
+func (f *fakeStorageClient) PutObject(_ context.Context, bucketName, objectName string, reader io.Reader, objectSize int64, opts minio.PutObjectOptions) (minio.UploadInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.putOpts = append(f.putOpts, opts)
 	if f.putErr != nil {
 		return minio.UploadInfo{}, f.putErr
 	}
-	data, _ := io.ReadAll(reader)
-	if objectSize == 0 {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return minio.UploadInfo{}, err
+	}
+	if objectSize <= 0 {
 		objectSize = int64(len(data))
 	}
 	f.putCalled = append(f.putCalled, fmt.Sprintf("%s/%s", bucketName, objectName))
@@ -62,222 +127,4129 @@ func (f *fakeStorageClient) PutObject(_ context.Context, bucketName, objectName
 		f.objects = map[string][]byte{}
 	}
 	f.objects[fmt.Sprintf("%s/%s", bucketName, objectName)] = data
+	if f.putHook != nil {
+		f.putHook(fmt.Sprintf("%s/%s", bucketName, objectName))
+	}
 	return minio.UploadInfo{Size: objectSize, ETag: "etag"}, nil
 }
 
-func (f *fakeStorageClient) GetObject(_ context.Context, bucketName, objectName string, _ minio.GetObjectOptions) (io.ReadCloser, error) {
+func (f *fakeStorageClient) GetObject(_ context.Context, bucketName, objectName string, opts minio.GetObjectOptions) (io.ReadCloser, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	key := fmt.Sprintf("%s/%s", bucketName, objectName)
 	data := f.objects[key]
+	if rng := opts.Header().Get("Range"); rng != "" {
+		var start, end int
+		if _, err := fmt.Sscanf(rng, "bytes=%d-%d", &start, &end); err != nil {
+			return nil, err
+		}
+		data = data[start : end+1]
+	}
 	return io.NopCloser(bytes.NewReader(data)), nil
 }
 
 func (f *fakeStorageClient) StatObject(_ context.Context, bucketName, objectName string, _ minio.StatObjectOptions) (minio.ObjectInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.statErr != nil {
+		return minio.ObjectInfo{}, f.statErr
+	}
 	key := fmt.Sprintf("%s/%s", bucketName, objectName)
 	data, ok := f.objects[key]
 	if !ok {
-		return minio.ObjectInfo{}, errors.New("not found")
+		return minio.ObjectInfo{}, minio.ErrorResponse{Code: "NoSuchKey", StatusCode: http.StatusNotFound}
 	}
-	return minio.ObjectInfo{Size: int64(len(data)), ContentType: "application/octet-stream", ETag: "etag"}, nil
+	return minio.ObjectInfo{Size: int64(len(data)), ContentType: "application/octet-stream", ETag: "etag", UserMetadata: f.userMeta[key]}, nil
 }
 
-type fakeContext struct {
-	body    []byte
-	params  map[string]string
-	status  int
-	resp    interface{}
-	bindErr error
-	stream  []byte
+func (f *fakeStorageClient) ListObjects(_ context.Context, bucketName string, opts minio.ListObjectsOptions) <-chan minio.ObjectInfo {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	keys := make([]string, 0, len(f.objects))
+	for k := range f.objects {
+		if strings.HasPrefix(k, bucketName+"/"+opts.Prefix) {
+			keys = append(keys, strings.TrimPrefix(k, bucketName+"/"))
+		}
+	}
+	sort.Strings(keys)
+
+	ch := make(chan minio.ObjectInfo, len(keys))
+	for _, k := range keys {
+		if k <= opts.StartAfter {
+			continue
+		}
+		ch <- minio.ObjectInfo{Key: k, Size: int64(len(f.objects[bucketName+"/"+k])), ETag: "etag"}
+	}
+	close(ch)
+	return ch
 }
 
-func (c *fakeContext) JSON(code int, v interface{}) error {
-	c.status = code
-	c.resp = v
+func (f *fakeStorageClient) RemoveObject(_ context.Context, bucketName, objectName string, _ minio.RemoveObjectOptions) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.removeErr != nil {
+		return f.removeErr
+	}
+	delete(f.objects, fmt.Sprintf("%s/%s", bucketName, objectName))
 	return nil
 }
 
-func (c *fakeContext) Bind(v interface{}) error {
-	if c.bindErr != nil {
-		return c.bindErr
+func (f *fakeStorageClient) CopyObject(_ context.Context, dst minio.CopyDestOptions, src minio.CopySrcOptions) (minio.UploadInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	srcKey := fmt.Sprintf("%s/%s", src.Bucket, src.Object)
+	data, ok := f.objects[srcKey]
+	if !ok {
+		return minio.UploadInfo{}, minio.ErrorResponse{Code: "NoSuchKey"}
 	}
-	return json.Unmarshal(c.body, v)
+	dstKey := fmt.Sprintf("%s/%s", dst.Bucket, dst.Object)
+	f.objects[dstKey] = append([]byte(nil), data...)
+	if f.userMeta == nil {
+		f.userMeta = map[string]map[string]string{}
+	}
+	if dst.ReplaceMetadata {
+		f.userMeta[dstKey] = dst.UserMetadata
+	} else {
+		f.userMeta[dstKey] = f.userMeta[srcKey]
+	}
+	return minio.UploadInfo{Bucket: dst.Bucket, Key: dst.Object, Size: int64(len(data)), ETag: "etag-copy"}, nil
 }
 
-func (c *fakeContext) Param(name string) string {
-	return c.params[name]
+// ComposeObject는 원본들을 이어 붙입니다. 호출한 대상 키는 composeCalled에 남습니다.
+func (f *fakeStorageClient) ComposeObject(_ context.Context, dst minio.CopyDestOptions, srcs ...minio.CopySrcOptions) (minio.UploadInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var data []byte
+	for _, src := range srcs {
+		part, ok := f.objects[fmt.Sprintf("%s/%s", src.Bucket, src.Object)]
+		if !ok {
+			return minio.UploadInfo{}, minio.ErrorResponse{Code: "NoSuchKey"}
+		}
+		data = append(data, part...)
+	}
+	dstKey := fmt.Sprintf("%s/%s", dst.Bucket, dst.Object)
+	f.composeCalled = append(f.composeCalled, dstKey)
+	f.objects[dstKey] = data
+	if f.userMeta == nil {
+		f.userMeta = map[string]map[string]string{}
+	}
+	if dst.ReplaceMetadata {
+		f.userMeta[dstKey] = dst.UserMetadata
+	} else {
+		f.userMeta[dstKey] = f.userMeta[fmt.Sprintf("%s/%s", srcs[0].Bucket, srcs[0].Object)]
+	}
+	return minio.UploadInfo{Bucket: dst.Bucket, Key: dst.Object, Size: int64(len(data)), ETag: "etag-compose"}, nil
 }
 
-func (c *fakeContext) Query(string) string      { return "" }
-func (c *fakeContext) GetHeader(string) string  { return "" }
-func (c *fakeContext) SetHeader(string, string) {}
-func (c *fakeContext) Context() context.Context { return context.Background() }
-func (c *fakeContext) Request() *http.Request   { return nil }
-func (c *fakeContext) Stream(code int, _ string, r io.Reader) error {
-	c.status = code
-	data, _ := io.ReadAll(r)
-	c.stream = data
+func (f *fakeStorageClient) NewMultipartUpload(_ context.Context, bucketName, objectName string, _ minio.PutObjectOptions) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.uploads == nil {
+		f.uploads = map[string]map[int][]byte{}
+	}
+	id := fmt.Sprintf("backend-%d", len(f.uploads)+1)
+	f.uploads[id] = map[int][]byte{}
+	return id, nil
+}
+
+func (f *fakeStorageClient) PutObjectPart(_ context.Context, _, _, uploadID string, partNumber int, reader io.Reader, _ int64) (minio.ObjectPart, error) {
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return minio.ObjectPart{}, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	parts, ok := f.uploads[uploadID]
+	if !ok {
+		return minio.ObjectPart{}, minio.ErrorResponse{Code: "NoSuchUpload"}
+	}
+	parts[partNumber] = data
+	return minio.ObjectPart{PartNumber: partNumber, ETag: fmt.Sprintf("etag-%d", partNumber), Size: int64(len(data))}, nil
+}
+
+func (f *fakeStorageClient) CompleteMultipartUpload(_ context.Context, bucketName, objectName, uploadID string, parts []minio.CompletePart, _ minio.PutObjectOptions) (minio.UploadInfo, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	uploaded, ok := f.uploads[uploadID]
+	if !ok {
+		return minio.UploadInfo{}, minio.ErrorResponse{Code: "NoSuchUpload"}
+	}
+	var data []byte
+	for _, p := range parts {
+		data = append(data, uploaded[p.PartNumber]...)
+	}
+	if f.objects == nil {
+		f.objects = map[string][]byte{}
+	}
+	f.objects[fmt.Sprintf("%s/%s", bucketName, objectName)] = data
+	delete(f.uploads, uploadID)
+	return minio.UploadInfo{Size: int64(len(data)), ETag: "etag-multipart"}, nil
+}
+
+func (f *fakeStorageClient) AbortMultipartUpload(_ context.Context, _, _, uploadID string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if _, ok := f.uploads[uploadID]; !ok {
+		return minio.ErrorResponse{Code: "NoSuchUpload"}
+	}
+	delete(f.uploads, uploadID)
 	return nil
 }
 
-func TestCreateBucket(t *testing.T) {
-	client := &fakeStorageClient{existsMap: map[string]bool{"dup": true}}
-	svc := NewStorageServiceWithClient(client, "default", nil)
+// fakeObjectRepository는 테스트에 필요한 메서드만 메모리로 구현합니다.
+// 구현하지 않은 메서드를 호출하면 임베드된 nil 인터페이스 때문에 panic이 납니다.
+type fakeObjectRepository struct {
+	repository.ObjectRepository
+	mu      sync.Mutex
+	objects map[string]*ent.Object
+	// trashed는 휴지통 행입니다. 같은 키의 살아 있는 행(objects)과 따로 둡니다.
+	trashed  map[string]*ent.Object
+	sessions map[string]*ent.UploadSession
+	parts    map[int]map[int]*ent.UploadPart
+	tus      map[string]*ent.TusUpload
+	locks    map[string]*sync.Mutex
+	// lockDelay만큼 키를 잠근 뒤 기다려, 두 키를 잠그는 요청이 서로 끼어들 틈을 넓힙니다.
+	lockDelay time.Duration
+	buckets   map[string]string
+	policies  map[string]string
+	cas       map[string]bool
+	lockCfg   map[string]repository.BucketObjectLock
+	blobs     map[string]*ent.Blob
+	versions  []*ent.ObjectVersion
+	tags      map[string]map[string]string
+	search    []repository.ObjectSearchInput
+	rules     map[string][]*ent.LifecycleRule
+	events    []*ent.LifecycleEvent
+	nextID    int
+}
 
-	t.Run("success", func(t *testing.T) {
-		ctx := &fakeContext{body: []byte(`{"name":"ok1"}`)}
-		svc.CreateBucket(ctx)
+func newFakeObjectRepository() *fakeObjectRepository {
+	return &fakeObjectRepository{
+		objects:  map[string]*ent.Object{},
+		trashed:  map[string]*ent.Object{},
+		sessions: map[string]*ent.UploadSession{},
+		parts:    map[int]map[int]*ent.UploadPart{},
+		tus:      map[string]*ent.TusUpload{},
+		locks:    map[string]*sync.Mutex{},
+		buckets:  map[string]string{},
+		policies: map[string]string{},
+		cas:      map[string]bool{},
+		lockCfg:  map[string]repository.BucketObjectLock{},
+		blobs:    map[string]*ent.Blob{},
+		tags:     map[string]map[string]string{},
+		rules:    map[string][]*ent.LifecycleRule{},
+	}
+}
 
-		if ctx.status != http.StatusCreated {
-			t.Fatalf("expected %d got %d", http.StatusCreated, ctx.status)
+// storedObject는 objects 행(없으면 휴지통 행)이 가리키는 백엔드 본문을 반환합니다. 행이 없으면 false입니다.
+func storedObject(client *fakeStorageClient, repo *fakeObjectRepository, bucketName, objectName string) ([]byte, bool) {
+	obj, err := repo.GetObject(context.Background(), bucketName, objectName)
+	if err != nil {
+		return nil, false
+	}
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	data, ok := client.objects[storageBucket(bucketName, obj.StoragePath)+"/"+obj.StoragePath]
+	return data, ok
+}
+
+// dataKeys는 bucketName 버킷에 올라간 객체 본문 키(dataKeyPrefix 아래)를 반환합니다.
+func dataKeys(client *fakeStorageClient, bucketName string) []string {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+	var keys []string
+	for key := range client.objects {
+		if strings.HasPrefix(key, bucketName+"/"+dataKeyPrefix) {
+			keys = append(keys, key)
 		}
-		if len(client.makeCalled) != 1 || client.makeCalled[0] != "ok1" {
-			t.Fatalf("make bucket not called correctly: %+v", client.makeCalled)
+	}
+	return keys
+}
+
+func (r *fakeObjectRepository) UpsertObject(_ context.Context, in repository.ObjectUpsertInput) (*ent.Object, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := in.BucketName + "/" + in.ObjectName
+	if prev, ok := r.objects[key]; !ok || prev.StoragePath != in.StoragePath {
+		if in.BlobSHA256 != "" {
+			b, ok := r.blobs[in.StoragePath]
+			if !ok {
+				b = &ent.Blob{Sha256: in.BlobSHA256, StoragePath: in.StoragePath, Size: in.Size}
+				r.blobs[in.StoragePath] = b
+			}
+			b.RefCount++
 		}
-		resp := ctx.resp.(BucketResponse)
-		if resp.Name != "ok1" {
-			t.Fatalf("unexpected response: %+v", resp)
+		if ok {
+			r.releaseBlob(prev)
 		}
-	})
+	}
+	r.nextID++
+	obj := &ent.Object{
+		ID:                r.nextID,
+		BucketName:        in.BucketName,
+		ObjectName:        in.ObjectName,
+		StoragePath:       in.StoragePath,
+		ContentType:       in.ContentType,
+		ContentTypeSource: in.ContentTypeSource,
+		Size:              in.Size,
+		Etag:              in.ETag,
+		UpdatedAt:         time.Now(),
+		ChecksumSha256:    in.ChecksumSHA256,
+		ChecksumCrc32c:    in.ChecksumCRC32C,
+		RetentionMode:     in.RetentionMode,
+		RetainUntil:       in.RetainUntil,
+	}
+	for k, v := range in.Metadata {
+		obj.Edges.Metadata = append(obj.Edges.Metadata, &ent.ObjectMetadata{ObjectID: obj.ID, Key: k, Value: v})
+	}
+	r.objects[key] = obj
+	return obj, nil
+}
 
-	t.Run("already exists", func(t *testing.T) {
-		ctx := &fakeContext{body: []byte(`{"name":"dup"}`)}
-		svc.CreateBucket(ctx)
-		if ctx.status != http.StatusConflict {
-			t.Fatalf("expected conflict got %d", ctx.status)
-		}
-	})
+func (r *fakeObjectRepository) releaseBlob(obj *ent.Object) {
+	if b, ok := r.blobs[obj.StoragePath]; ok && b.RefCount > 0 {
+		b.RefCount--
+	}
+}
 
-	t.Run("invalid body", func(t *testing.T) {
-		ctx := &fakeContext{body: []byte(`{`)}
-		svc.CreateBucket(ctx)
-		if ctx.status != http.StatusBadRequest {
-			t.Fatalf("expected bad request got %d", ctx.status)
-		}
-	})
+func (r *fakeObjectRepository) GetObject(_ context.Context, bucketName, objectName string) (*ent.Object, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if obj, ok := r.objects[bucketName+"/"+objectName]; ok {
+		return obj, nil
+	}
+	if obj, ok := r.trashed[bucketName+"/"+objectName]; ok {
+		return obj, nil
+	}
+	return nil, &ent.NotFoundError{}
+}
 
-	t.Run("invalid name", func(t *testing.T) {
-		ctx := &fakeContext{body: []byte(`{"name":"UPPER"}`)}
-		svc.CreateBucket(ctx)
-		if ctx.status != http.StatusBadRequest {
-			t.Fatalf("expected bad request got %d", ctx.status)
-		}
-	})
+func (r *fakeObjectRepository) DeleteObject(_ context.Context, bucketName, objectName string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if obj, ok := r.objects[bucketName+"/"+objectName]; ok {
+		r.releaseBlob(obj)
+	}
+	delete(r.objects, bucketName+"/"+objectName)
+	delete(r.tags, bucketName+"/"+objectName)
+	return nil
+}
 
-	t.Run("bucket check error", func(t *testing.T) {
-		client.existsErr = errors.New("boom")
-		ctx := &fakeContext{body: []byte(`{"name":"err"}`)}
-		svc.CreateBucket(ctx)
-		if ctx.status != http.StatusInternalServerError {
-			t.Fatalf("expected 500 got %d", ctx.status)
-		}
-		client.existsErr = nil
-	})
+func (r *fakeObjectRepository) GetObjectTags(_ context.Context, bucketName, objectName string) (map[string]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := bucketName + "/" + objectName
+	if obj, ok := r.objects[key]; !ok || obj.DeletedAt != nil {
+		return nil, &ent.NotFoundError{}
+	}
+	tags := map[string]string{}
+	for k, v := range r.tags[key] {
+		tags[k] = v
+	}
+	return tags, nil
 }
 
-func TestDeleteBucket(t *testing.T) {
-	client := &fakeStorageClient{existsMap: map[string]bool{"keep": true}}
-	svc := NewStorageServiceWithClient(client, "", nil)
+func (r *fakeObjectRepository) PutObjectTags(_ context.Context, bucketName, objectName string, tags map[string]string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key := bucketName + "/" + objectName
+	if obj, ok := r.objects[key]; !ok || obj.DeletedAt != nil {
+		return &ent.NotFoundError{}
+	}
+	r.tags[key] = map[string]string{}
+	for k, v := range tags {
+		r.tags[key][k] = v
+	}
+	return nil
+}
 
-	t.Run("success", func(t *testing.T) {
-		ctx := &fakeContext{params: map[string]string{"bucketName": "keep"}}
-		svc.DeleteBucket(ctx)
-		if ctx.status != http.StatusOK {
-			t.Fatalf("expected ok got %d", ctx.status)
+func (t *fakeObjectTx) UpdateMetadata(_ context.Context, in repository.ObjectMetadataUpdateInput) (*ent.Object, error) {
+	t.r.mu.Lock()
+	defer t.r.mu.Unlock()
+	obj := t.current
+	if obj == nil || obj.DeletedAt != nil {
+		return nil, &ent.NotFoundError{}
+	}
+	meta := map[string]string{}
+	if !in.Replace {
+		for _, m := range obj.Edges.Metadata {
+			meta[m.Key] = m.Value
 		}
-		if len(client.removeCalled) != 1 || client.removeCalled[0] != "keep" {
-			t.Fatalf("remove not called: %+v", client.removeCalled)
+		for _, k := range in.Remove {
+			delete(meta, k)
 		}
-	})
+	}
+	for k, v := range in.Set {
+		meta[k] = v
+	}
+	obj.Edges.Metadata = nil
+	for k, v := range meta {
+		obj.Edges.Metadata = append(obj.Edges.Metadata, &ent.ObjectMetadata{ObjectID: obj.ID, Key: k, Value: v})
+	}
+	if in.ContentType != nil {
+		obj.ContentType = *in.ContentType
+	}
+	if in.CacheControl != nil {
+		obj.CacheControl = *in.CacheControl
+	}
+	obj.MetadataRevision++
+	obj.UpdatedAt = time.Now()
+	return obj, nil
+}
 
-	t.Run("not found", func(t *testing.T) {
-		ctx := &fakeContext{params: map[string]string{"bucketName": "none"}}
-		svc.DeleteBucket(ctx)
-		if ctx.status != http.StatusNotFound {
-			t.Fatalf("expected 404 got %d", ctx.status)
-		}
-	})
+func (r *fakeObjectRepository) BeginObjectTx(ctx context.Context, bucketName, objectName string) (repository.ObjectTx, error) {
+	key := bucketName + "/" + objectName
+	r.mu.Lock()
+	lock, ok := r.locks[key]
+	if !ok {
+		lock = &sync.Mutex{}
+		r.locks[key] = lock
+	}
+	r.mu.Unlock()
 
-	t.Run("invalid name", func(t *testing.T) {
-		ctx := &fakeContext{params: map[string]string{"bucketName": "Nope"}}
-		svc.DeleteBucket(ctx)
-		if ctx.status != http.StatusBadRequest {
-			t.Fatalf("expected 400 got %d", ctx.status)
-		}
-	})
+	lock.Lock()
+	time.Sleep(r.lockDelay)
+	current, err := r.GetObject(ctx, bucketName, objectName)
+	if err != nil {
+		current = nil
+	}
+	r.mu.Lock()
+	trashed := r.trashed[key]
+	r.mu.Unlock()
+	return &fakeObjectTx{r: r, bucketName: bucketName, objectName: objectName, current: current, trashed: trashed, unlock: lock.Unlock}, nil
+}
 
-	t.Run("exists error", func(t *testing.T) {
-		client.existsErr = errors.New("boom")
-		ctx := &fakeContext{params: map[string]string{"bucketName": "keep"}}
-		svc.DeleteBucket(ctx)
-		if ctx.status != http.StatusInternalServerError {
-			t.Fatalf("expected 500 got %d", ctx.status)
-		}
-		client.existsErr = nil
-	})
+// fakeObjectTx는 키별 mutex로 BeginObjectTx의 advisory lock을 흉내 냅니다.
+// 롤백해도 이미 반영된 변경은 되돌리지 않습니다.
+type fakeObjectTx struct {
+	r                      *fakeObjectRepository
+	bucketName, objectName string
+	current                *ent.Object
+	trashed                *ent.Object
+	unlock                 func()
+	done                   bool
 }
 
-func TestListBucket(t *testing.T) {
-	now := time.Now()
-	client := &fakeStorageClient{
-		listResp: []minio.BucketInfo{{Name: "a", CreationDate: now}},
+func (t *fakeObjectTx) Current() *ent.Object { return t.current }
+
+func (t *fakeObjectTx) Trashed() *ent.Object { return t.trashed }
+
+func (t *fakeObjectTx) Upsert(ctx context.Context, in repository.ObjectUpsertInput) (*ent.Object, error) {
+	return t.r.UpsertObject(ctx, in)
+}
+
+func (t *fakeObjectTx) Delete(ctx context.Context) error {
+	return t.r.DeleteObject(ctx, t.bucketName, t.objectName)
+}
+
+func (t *fakeObjectTx) DeleteTrashed(_ context.Context) error {
+	t.r.mu.Lock()
+	defer t.r.mu.Unlock()
+	key := t.bucketName + "/" + t.objectName
+	obj, ok := t.r.trashed[key]
+	if !ok {
+		return &ent.NotFoundError{}
 	}
-	svc := NewStorageServiceWithClient(client, "", nil)
-	ctx := &fakeContext{}
+	t.r.releaseBlob(obj)
+	delete(t.r.trashed, key)
+	return nil
+}
 
-	err := svc.ListBucket(ctx)
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
+func (t *fakeObjectTx) Trash(_ context.Context) error {
+	t.r.mu.Lock()
+	defer t.r.mu.Unlock()
+	key := t.bucketName + "/" + t.objectName
+	if _, ok := t.r.trashed[key]; ok {
+		return fmt.Errorf("duplicate trashed row for %s", key)
 	}
-	if ctx.status != http.StatusOK {
-		t.Fatalf("expected 200 got %d", ctx.status)
+	now := time.Now()
+	t.current.DeletedAt = &now
+	t.r.trashed[key] = t.current
+	delete(t.r.objects, key)
+	return nil
+}
+
+func (t *fakeObjectTx) Restore(_ context.Context) (*ent.Object, error) {
+	t.r.mu.Lock()
+	defer t.r.mu.Unlock()
+	key := t.bucketName + "/" + t.objectName
+	obj, ok := t.r.trashed[key]
+	if !ok {
+		return nil, &ent.NotFoundError{}
 	}
-	resp := ctx.resp.(BucketListResponse).Buckets
-	if len(resp) != 1 || resp[0].Name != "a" || !resp[0].CreatedAt.Equal(now) {
-		t.Fatalf("unexpected buckets: %+v", resp)
+	if _, ok := t.r.objects[key]; ok {
+		return nil, fmt.Errorf("duplicate live row for %s", key)
 	}
+	obj.DeletedAt = nil
+	t.r.objects[key] = obj
+	delete(t.r.trashed, key)
+	return obj, nil
 }
 
-func TestGetBucket(t *testing.T) {
-	now := time.Now()
-	client := &fakeStorageClient{
-		listResp: []minio.BucketInfo{{Name: "alive", CreationDate: now}},
+func (t *fakeObjectTx) SetRetention(_ context.Context, mode string, retainUntil *time.Time) (*ent.Object, error) {
+	t.r.mu.Lock()
+	defer t.r.mu.Unlock()
+	t.current.RetentionMode = mode
+	t.current.RetainUntil = retainUntil
+	return t.current, nil
+}
+
+func (t *fakeObjectTx) SetLegalHold(_ context.Context, hold bool) (*ent.Object, error) {
+	t.r.mu.Lock()
+	defer t.r.mu.Unlock()
+	t.current.LegalHold = hold
+	return t.current, nil
+}
+
+func (t *fakeObjectTx) CreateVersion(ctx context.Context, in repository.ObjectVersionInput) (*ent.ObjectVersion, error) {
+	return t.r.CreateObjectVersion(ctx, in)
+}
+
+// LockBlob은 아무것도 잠그지 않습니다. 테스트는 같은 blob을 동시에 정리하지 않습니다.
+func (t *fakeObjectTx) LockBlob(_ context.Context, _ string) error { return nil }
+
+func (t *fakeObjectTx) Commit() error { return t.Rollback() }
+
+func (t *fakeObjectTx) Rollback() error {
+	if !t.done {
+		t.done = true
+		t.unlock()
 	}
-	svc := NewStorageServiceWithClient(client, "", nil)
+	return nil
+}
 
-	t.Run("found", func(t *testing.T) {
-		ctx := &fakeContext{params: map[string]string{"bucketName": "alive"}}
-		svc.GetBucket(ctx)
-		if ctx.status != http.StatusOK {
-			t.Fatalf("expected 200 got %d", ctx.status)
+func (r *fakeObjectRepository) ListTrashedObjects(_ context.Context, in repository.TrashListInput) ([]*ent.Object, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []*ent.Object
+	for _, obj := range r.trashed {
+		if obj.BucketName == in.BucketName && obj.ObjectName > in.StartAfter && strings.HasPrefix(obj.ObjectName, in.Prefix) {
+			out = append(out, obj)
 		}
-		resp := ctx.resp.(BucketResponse)
-		if resp.Name != "alive" || !resp.CreatedAt.Equal(now) {
-			t.Fatalf("unexpected response: %+v", resp)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ObjectName < out[j].ObjectName })
+	if len(out) > in.Limit {
+		out = out[:in.Limit]
+	}
+	return out, nil
+}
+
+func (r *fakeObjectRepository) ListExpiredTrash(_ context.Context, deletedBefore time.Time, limit int) ([]*ent.Object, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []*ent.Object
+	for _, obj := range r.trashed {
+		if obj.DeletedAt.Before(deletedBefore) && len(out) < limit {
+			out = append(out, obj)
 		}
-	})
+	}
+	return out, nil
+}
 
-	t.Run("not found", func(t *testing.T) {
-		ctx := &fakeContext{params: map[string]string{"bucketName": "missing"}}
-		svc.GetBucket(ctx)
-		if ctx.status != http.StatusNotFound {
-			t.Fatalf("expected 404 got %d", ctx.status)
+// ListObjects는 구분자 없는 접두사 목록과 StartAfter/Limit 페이지네이션만 흉내 냅니다.
+func (r *fakeObjectRepository) ListObjects(_ context.Context, in repository.ObjectListInput) (*repository.ObjectListResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []*ent.Object
+	for _, obj := range r.objects {
+		if obj.DeletedAt != nil || obj.BucketName != in.BucketName || !strings.HasPrefix(obj.ObjectName, in.Prefix) {
+			continue
 		}
-	})
+		if in.StartAfter != "" && obj.ObjectName <= in.StartAfter {
+			continue
+		}
+		out = append(out, obj)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ObjectName < out[j].ObjectName })
+	res := &repository.ObjectListResult{Objects: out}
+	if len(out) > in.Limit {
+		res.Objects = out[:in.Limit]
+		res.IsTruncated = true
+	}
+	if len(res.Objects) > 0 {
+		res.NextMarker = res.Objects[len(res.Objects)-1].ObjectName
+	}
+	return res, nil
+}
 
-	t.Run("invalid name", func(t *testing.T) {
-		ctx := &fakeContext{params: map[string]string{"bucketName": "AA"}}
-		svc.GetBucket(ctx)
-		if ctx.status != http.StatusBadRequest {
-			t.Fatalf("expected 400 got %d", ctx.status)
+// SearchObjects는 이름 순 페이지네이션과 버킷/메타데이터 일치, 검색어 단어 포함 조건만 흉내 내고 받은 조건을 기록합니다.
+func (r *fakeObjectRepository) SearchObjects(_ context.Context, in repository.ObjectSearchInput) (*repository.ObjectSearchResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.search = append(r.search, in)
+	var out []*ent.Object
+	for _, obj := range r.objects {
+		if obj.DeletedAt != nil || (in.BucketName != "" && obj.BucketName != in.BucketName) {
+			continue
 		}
+		if in.After != nil && obj.ObjectName <= in.After.Name {
+			continue
+		}
+		matched := in.Query == "" || fakeTextMatch(obj, in.Query)
+		for k, v := range in.Metadata {
+			found := false
+			for _, m := range obj.Edges.Metadata {
+				found = found || (m.Key == k && m.Value == v)
+			}
+			matched = matched && found
+		}
+		if matched {
+			out = append(out, obj)
+		}
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].ObjectName < out[j].ObjectName })
+	res := &repository.ObjectSearchResult{Objects: out}
+	if len(out) > in.Limit {
+		res.Objects = out[:in.Limit]
+		res.Next = &repository.ObjectSearchCursor{Name: out[in.Limit-1].ObjectName, ID: out[in.Limit-1].ID}
+	}
+	return res, nil
+}
+
+func fakeTextMatch(obj *ent.Object, query string) bool {
+	text := repository.SearchTokens(obj.ObjectName)
+	for _, m := range obj.Edges.Metadata {
+		text = append(text, repository.SearchTokens(m.Value)...)
+	}
+	for _, t := range repository.SearchTokens(query) {
+		if slices.Contains(text, t) {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *fakeObjectRepository) GetBucketVersioning(_ context.Context, bucketName string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.buckets[bucketName], nil
+}
+
+func (r *fakeObjectRepository) SetBucketVersioning(_ context.Context, bucketName, status string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.buckets[bucketName] = status
+	return nil
+}
+
+func (r *fakeObjectRepository) GetBucketContentTypePolicy(_ context.Context, bucketName string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.policies[bucketName], nil
+}
+
+func (r *fakeObjectRepository) SetBucketContentTypePolicy(_ context.Context, bucketName, policy string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.policies[bucketName] = policy
+	return nil
+}
+
+func (r *fakeObjectRepository) GetBucketContentAddressable(_ context.Context, bucketName string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cas[bucketName], nil
+}
+
+func (r *fakeObjectRepository) SetBucketContentAddressable(_ context.Context, bucketName string, enabled bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cas[bucketName] = enabled
+	return nil
+}
+
+func (r *fakeObjectRepository) GetBucketObjectLock(_ context.Context, bucketName string) (repository.BucketObjectLock, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.lockCfg[bucketName], nil
+}
+
+func (r *fakeObjectRepository) SetBucketObjectLock(_ context.Context, bucketName string, lock repository.BucketObjectLock) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lockCfg[bucketName] = lock
+	return nil
+}
+
+func (r *fakeObjectRepository) BeginBlobTx(_ context.Context, storagePath string) (repository.BlobTx, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return &fakeBlobTx{r: r, key: storagePath, current: r.blobs[storagePath]}, nil
+}
+
+func (r *fakeObjectRepository) ListUnreferencedBlobs(_ context.Context, limit int) ([]*ent.Blob, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var out []*ent.Blob
+	for _, b := range r.blobs {
+		if b.RefCount <= 0 && len(out) < limit {
+			out = append(out, b)
+		}
+	}
+	return out, nil
+}
+
+type fakeBlobTx struct {
+	r       *fakeObjectRepository
+	key     string
+	current *ent.Blob
+}
+
+func (t *fakeBlobTx) Current() *ent.Blob { return t.current }
+
+func (t *fakeBlobTx) Delete(_ context.Context) error {
+	t.r.mu.Lock()
+	defer t.r.mu.Unlock()
+	delete(t.r.blobs, t.key)
+	return nil
+}
+
+func (t *fakeBlobTx) Commit() error   { return nil }
+func (t *fakeBlobTx) Rollback() error { return nil }
+
+func (r *fakeObjectRepository) CreateObjectVersion(_ context.Context, in repository.ObjectVersionInput) (*ent.ObjectVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	kept := r.versions[:0]
+	for _, v := range r.versions {
+		if v.BucketName != in.BucketName || v.ObjectName != in.ObjectName || v.VersionID != in.VersionID {
+			kept = append(kept, v)
+		}
+	}
+	r.nextID++
+	v := &ent.ObjectVersion{
+		ID:                r.nextID,
+		VersionID:         in.VersionID,
+		BucketName:        in.BucketName,
+		ObjectName:        in.ObjectName,
+		StoragePath:       in.StoragePath,
+		ContentType:       in.ContentType,
+		ContentTypeSource: in.ContentTypeSource,
+		Size:              in.Size,
+		Etag:              in.ETag,
+		ChecksumSha256:    in.ChecksumSHA256,
+		ChecksumCrc32c:    in.ChecksumCRC32C,
+		IsDeleteMarker:    in.IsDeleteMarker,
+		Metadata:          in.Metadata,
+		CreatedAt:         time.Now(),
+	}
+	r.versions = append(kept, v)
+	return v, nil
+}
+
+func (r *fakeObjectRepository) GetObjectVersion(_ context.Context, bucketName, objectName, versionID string) (*ent.ObjectVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, v := range r.versions {
+		if v.BucketName == bucketName && v.ObjectName == objectName && v.VersionID == versionID {
+			return v, nil
+		}
+	}
+	return nil, &ent.NotFoundError{}
+}
+
+func (r *fakeObjectRepository) ListObjectVersions(_ context.Context, bucketName, objectName string) ([]*ent.ObjectVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var versions []*ent.ObjectVersion
+	for i := len(r.versions) - 1; i >= 0; i-- {
+		if v := r.versions[i]; v.BucketName == bucketName && v.ObjectName == objectName {
+			versions = append(versions, v)
+		}
+	}
+	return versions, nil
+}
+
+func (r *fakeObjectRepository) CreateUploadSession(_ context.Context, in repository.UploadSessionInput) (*ent.UploadSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	session := &ent.UploadSession{
+		ID:              r.nextID,
+		UploadID:        in.UploadID,
+		BucketName:      in.BucketName,
+		ObjectName:      in.ObjectName,
+		StoragePath:     in.StoragePath,
+		BackendUploadID: in.BackendUploadID,
+		ContentType:     in.ContentType,
+		Metadata:        in.Metadata,
+		CreatedAt:       time.Now(),
+		UpdatedAt:       time.Now(),
+	}
+	r.sessions[in.UploadID] = session
+	r.parts[session.ID] = map[int]*ent.UploadPart{}
+	return session, nil
+}
+
+func (r *fakeObjectRepository) GetUploadSession(_ context.Context, bucketName, uploadID string) (*ent.UploadSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	session, ok := r.sessions[uploadID]
+	if !ok || session.BucketName != bucketName {
+		return nil, &ent.NotFoundError{}
+	}
+	return session, nil
+}
+
+func (r *fakeObjectRepository) UpsertUploadPart(_ context.Context, in repository.UploadPartInput) (*ent.UploadPart, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	part := &ent.UploadPart{SessionID: in.SessionID, PartNumber: in.PartNumber, Etag: in.ETag, Size: in.Size}
+	r.parts[in.SessionID][in.PartNumber] = part
+	for _, session := range r.sessions {
+		if session.ID == in.SessionID {
+			session.UpdatedAt = time.Now()
+		}
+	}
+	return part, nil
+}
+
+func (r *fakeObjectRepository) ListUploadParts(_ context.Context, sessionID int) ([]*ent.UploadPart, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	parts := make([]*ent.UploadPart, 0, len(r.parts[sessionID]))
+	for _, p := range r.parts[sessionID] {
+		parts = append(parts, p)
+	}
+	sort.Slice(parts, func(i, j int) bool { return parts[i].PartNumber < parts[j].PartNumber })
+	return parts, nil
+}
+
+func (r *fakeObjectRepository) DeleteUploadSession(_ context.Context, sessionID int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for id, session := range r.sessions {
+		if session.ID == sessionID {
+			delete(r.sessions, id)
+		}
+	}
+	delete(r.parts, sessionID)
+	return nil
+}
+
+func (r *fakeObjectRepository) ListExpiredUploadSessions(_ context.Context, idleSince time.Time, limit int) ([]*ent.UploadSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var sessions []*ent.UploadSession
+	for _, session := range r.sessions {
+		if session.UpdatedAt.Before(idleSince) && len(sessions) < limit {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+func (r *fakeObjectRepository) CreateTusUpload(_ context.Context, in repository.TusUploadInput) (*ent.TusUpload, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	upload := &ent.TusUpload{
+		ID:             r.nextID,
+		UploadID:       in.UploadID,
+		BucketName:     in.BucketName,
+		ObjectName:     in.ObjectName,
+		ContentType:    in.ContentType,
+		UploadLength:   in.UploadLength,
+		UploadMetadata: in.UploadMetadata,
+		ExpiresAt:      in.ExpiresAt,
+	}
+	r.tus[in.UploadID] = upload
+	return upload, nil
+}
+
+func (r *fakeObjectRepository) GetTusUpload(_ context.Context, bucketName, uploadID string) (*ent.TusUpload, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	upload, ok := r.tus[uploadID]
+	if !ok || upload.BucketName != bucketName {
+		return nil, &ent.NotFoundError{}
+	}
+	copied := *upload
+	return &copied, nil
+}
+
+func (r *fakeObjectRepository) UpdateTusUploadOffset(_ context.Context, id int, offset int64, expiresAt time.Time) (*ent.TusUpload, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, upload := range r.tus {
+		if upload.ID == id {
+			upload.UploadOffset = offset
+			upload.ExpiresAt = expiresAt
+			copied := *upload
+			return &copied, nil
+		}
+	}
+	return nil, &ent.NotFoundError{}
+}
+
+func (r *fakeObjectRepository) DeleteTusUpload(_ context.Context, id int) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for uploadID, upload := range r.tus {
+		if upload.ID == id {
+			delete(r.tus, uploadID)
+		}
+	}
+	return nil
+}
+
+func (r *fakeObjectRepository) ListExpiredTusUploads(_ context.Context, expiredBefore time.Time, limit int) ([]*ent.TusUpload, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var uploads []*ent.TusUpload
+	for _, upload := range r.tus {
+		if upload.ExpiresAt.Before(expiredBefore) && len(uploads) < limit {
+			uploads = append(uploads, upload)
+		}
+	}
+	return uploads, nil
+}
+
+func (t *fakeObjectTx) DeleteVersion(_ context.Context, id int) error {
+	r := t.r
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i, v := range r.versions {
+		if v.ID == id && v.BucketName == t.bucketName && v.ObjectName == t.objectName {
+			r.versions = append(r.versions[:i], r.versions[i+1:]...)
+			return nil
+		}
+	}
+	return &ent.NotFoundError{}
+}
+
+func (r *fakeObjectRepository) GetLifecycleRules(_ context.Context, bucketName string) ([]*ent.LifecycleRule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rules[bucketName], nil
+}
+
+func (r *fakeObjectRepository) PutLifecycleRules(_ context.Context, bucketName string, rules []repository.LifecycleRuleInput) ([]*ent.LifecycleRule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	saved := make([]*ent.LifecycleRule, 0, len(rules))
+	for _, in := range rules {
+		r.nextID++
+		saved = append(saved, &ent.LifecycleRule{
+			ID:                        r.nextID,
+			BucketName:                bucketName,
+			RuleID:                    in.RuleID,
+			Enabled:                   in.Enabled,
+			Prefix:                    in.Prefix,
+			Tags:                      in.Tags,
+			ExpirationDays:            in.ExpirationDays,
+			ExpirationDate:            in.ExpirationDate,
+			NoncurrentExpirationDays:  in.NoncurrentExpirationDays,
+			AbortIncompleteUploadDays: in.AbortIncompleteUploadDays,
+		})
+	}
+	r.rules[bucketName] = saved
+	return saved, nil
+}
+
+func (r *fakeObjectRepository) DeleteLifecycleRules(_ context.Context, bucketName string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delete(r.rules, bucketName)
+	return nil
+}
+
+func (r *fakeObjectRepository) ListEnabledLifecycleRules(_ context.Context) ([]*ent.LifecycleRule, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var rules []*ent.LifecycleRule
+	for _, bucketRules := range r.rules {
+		for _, rule := range bucketRules {
+			if rule.Enabled {
+				rules = append(rules, rule)
+			}
+		}
+	}
+	return rules, nil
+}
+
+func (r *fakeObjectRepository) ListLifecycleObjects(_ context.Context, in repository.LifecycleObjectInput) ([]*ent.Object, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var objects []*ent.Object
+	for key, obj := range r.objects {
+		if obj.BucketName != in.BucketName || obj.DeletedAt != nil || obj.ObjectName <= in.StartAfter ||
+			!strings.HasPrefix(obj.ObjectName, in.Prefix) || !obj.UpdatedAt.Before(in.ModifiedBefore) {
+			continue
+		}
+		matched := true
+		for k, v := range in.Tags {
+			if r.tags[key][k] != v {
+				matched = false
+			}
+		}
+		if matched {
+			objects = append(objects, obj)
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].ObjectName < objects[j].ObjectName })
+	if len(objects) > in.Limit {
+		objects = objects[:in.Limit]
+	}
+	return objects, nil
+}
+
+func (r *fakeObjectRepository) ListLifecycleVersions(_ context.Context, in repository.LifecycleVersionInput) ([]*ent.ObjectVersion, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var versions []*ent.ObjectVersion
+	for _, v := range r.versions {
+		if v.BucketName == in.BucketName && v.ID > in.AfterID && strings.HasPrefix(v.ObjectName, in.Prefix) &&
+			v.CreatedAt.Before(in.CreatedBefore) && len(versions) < in.Limit {
+			versions = append(versions, v)
+		}
+	}
+	return versions, nil
+}
+
+func (r *fakeObjectRepository) ListLifecycleUploadSessions(_ context.Context, bucketName, prefix string, createdBefore time.Time, limit int) ([]*ent.UploadSession, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var sessions []*ent.UploadSession
+	for _, session := range r.sessions {
+		if session.BucketName == bucketName && strings.HasPrefix(session.ObjectName, prefix) &&
+			session.CreatedAt.Before(createdBefore) && len(sessions) < limit {
+			sessions = append(sessions, session)
+		}
+	}
+	return sessions, nil
+}
+
+func (r *fakeObjectRepository) CreateLifecycleEvent(_ context.Context, in repository.LifecycleEventInput) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nextID++
+	r.events = append(r.events, &ent.LifecycleEvent{
+		ID:         r.nextID,
+		BucketName: in.BucketName,
+		RuleID:     in.RuleID,
+		Action:     in.Action,
+		ObjectName: in.ObjectName,
+		VersionID:  in.VersionID,
+		UploadID:   in.UploadID,
+		CreatedAt:  time.Now(),
 	})
+	return nil
+}
 
-	t.Run("list error", func(t *testing.T) {
-		client.listErr = errors.New("boom")
-		ctx := &fakeContext{params: map[string]string{"bucketName": "alive"}}
-		svc.GetBucket(ctx)
+func (r *fakeObjectRepository) ListLifecycleEvents(_ context.Context, bucketName string, beforeID, limit int) ([]*ent.LifecycleEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var events []*ent.LifecycleEvent
+	for i := len(r.events) - 1; i >= 0; i-- {
+		e := r.events[i]
+		if e.BucketName == bucketName && (beforeID <= 0 || e.ID < beforeID) && len(events) < limit {
+			events = append(events, e)
+		}
+	}
+	return events, nil
+}
+
+type fakeContext struct {
+	body    []byte
+	params  map[string]string
+	query   map[string]string
+	req     *http.Request
+	headers http.Header
+	status  int
+	resp    interface{}
+	bindErr error
+	stream  []byte
+}
+
+func (c *fakeContext) JSON(code int, v interface{}) error {
+	c.status = code
+	c.resp = v
+	return nil
+}
+
+func (c *fakeContext) Bind(v interface{}) error {
+	if c.bindErr != nil {
+		return c.bindErr
+	}
+	return json.NewDecoder(bytes.NewReader(c.body)).Decode(v)
+}
+
+func (c *fakeContext) Param(name string) string {
+	return c.params[name]
+}
+
+func (c *fakeContext) Query(name string) string { return c.query[name] }
+func (c *fakeContext) Context() context.Context { return context.Background() }
+func (c *fakeContext) Request() *http.Request   { return c.req }
+
+func (c *fakeContext) GetHeader(name string) string {
+	if c.req == nil {
+		return ""
+	}
+	return c.req.Header.Get(name)
+}
+
+func (c *fakeContext) SetHeader(name, value string) {
+	if c.headers == nil {
+		c.headers = http.Header{}
+	}
+	c.headers.Set(name, value)
+}
+func (c *fakeContext) Stream(code int, _ string, r io.Reader) error {
+	c.status = code
+	data, _ := io.ReadAll(r)
+	c.stream = data
+	return nil
+}
+
+func TestCreateBucket(t *testing.T) {
+	client := &fakeStorageClient{existsMap: map[string]bool{"dup": true}}
+	svc := NewStorageServiceWithClient(client, "default", nil)
+
+	t.Run("success", func(t *testing.T) {
+		ctx := &fakeContext{body: []byte(`{"name":"ok1"}`)}
+		svc.CreateBucket(ctx)
+
+		if ctx.status != http.StatusCreated {
+			t.Fatalf("expected %d got %d", http.StatusCreated, ctx.status)
+		}
+		if len(client.makeCalled) != 1 || client.makeCalled[0] != "ok1" {
+			t.Fatalf("make bucket not called correctly: %+v", client.makeCalled)
+		}
+		resp := ctx.resp.(BucketResponse)
+		if resp.Name != "ok1" {
+			t.Fatalf("unexpected response: %+v", resp)
+		}
+	})
+
+	t.Run("already exists", func(t *testing.T) {
+		ctx := &fakeContext{body: []byte(`{"name":"dup"}`)}
+		svc.CreateBucket(ctx)
+		if ctx.status != http.StatusConflict {
+			t.Fatalf("expected conflict got %d", ctx.status)
+		}
+	})
+
+	t.Run("invalid body", func(t *testing.T) {
+		ctx := &fakeContext{body: []byte(`{`)}
+		svc.CreateBucket(ctx)
+		if ctx.status != http.StatusBadRequest {
+			t.Fatalf("expected bad request got %d", ctx.status)
+		}
+	})
+
+	t.Run("invalid name", func(t *testing.T) {
+		ctx := &fakeContext{body: []byte(`{"name":"UPPER"}`)}
+		svc.CreateBucket(ctx)
+		if ctx.status != http.StatusBadRequest {
+			t.Fatalf("expected bad request got %d", ctx.status)
+		}
+	})
+
+	t.Run("bucket check error", func(t *testing.T) {
+		client.existsErr = errors.New("boom")
+		ctx := &fakeContext{body: []byte(`{"name":"err"}`)}
+		svc.CreateBucket(ctx)
 		if ctx.status != http.StatusInternalServerError {
 			t.Fatalf("expected 500 got %d", ctx.status)
 		}
-		client.listErr = nil
+		client.existsErr = nil
+	})
+}
+
+func TestDeleteBucket(t *testing.T) {
+	client := &fakeStorageClient{existsMap: map[string]bool{"keep": true}}
+	svc := NewStorageServiceWithClient(client, "", nil)
+
+	t.Run("success", func(t *testing.T) {
+		ctx := &fakeContext{params: map[string]string{"bucketName": "keep"}}
+		svc.DeleteBucket(ctx)
+		if ctx.status != http.StatusOK {
+			t.Fatalf("expected ok got %d", ctx.status)
+		}
+		if len(client.removeCalled) != 1 || client.removeCalled[0] != "keep" {
+			t.Fatalf("remove not called: %+v", client.removeCalled)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		ctx := &fakeContext{params: map[string]string{"bucketName": "none"}}
+		svc.DeleteBucket(ctx)
+		if ctx.status != http.StatusNotFound {
+			t.Fatalf("expected 404 got %d", ctx.status)
+		}
 	})
+
+	t.Run("invalid name", func(t *testing.T) {
+		ctx := &fakeContext{params: map[string]string{"bucketName": "Nope"}}
+		svc.DeleteBucket(ctx)
+		if ctx.status != http.StatusBadRequest {
+			t.Fatalf("expected 400 got %d", ctx.status)
+		}
+	})
+
+	t.Run("exists error", func(t *testing.T) {
+		client.existsErr = errors.New("boom")
+		ctx := &fakeContext{params: map[string]string{"bucketName": "keep"}}
+		svc.DeleteBucket(ctx)
+		if ctx.status != http.StatusInternalServerError {
+			t.Fatalf("expected 500 got %d", ctx.status)
+		}
+		client.existsErr = nil
+	})
+}
+
+func TestListBucket(t *testing.T) {
+	now := time.Now()
+	client := &fakeStorageClient{
+		listResp: []minio.BucketInfo{{Name: "a", CreationDate: now}},
+	}
+	svc := NewStorageServiceWithClient(client, "", nil)
+	ctx := &fakeContext{}
+
+	err := svc.ListBucket(ctx)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ctx.status != http.StatusOK {
+		t.Fatalf("expected 200 got %d", ctx.status)
+	}
+	resp := ctx.resp.(BucketListResponse).Buckets
+	if len(resp) != 1 || resp[0].Name != "a" || !resp[0].CreatedAt.Equal(now) {
+		t.Fatalf("unexpected buckets: %+v", resp)
+	}
+}
+
+func TestGetBucket(t *testing.T) {
+	now := time.Now()
+	client := &fakeStorageClient{
+		listResp: []minio.BucketInfo{{Name: "alive", CreationDate: now}},
+	}
+	svc := NewStorageServiceWithClient(client, "", nil)
+
+	t.Run("found", func(t *testing.T) {
+		ctx := &fakeContext{params: map[string]string{"bucketName": "alive"}}
+		svc.GetBucket(ctx)
+		if ctx.status != http.StatusOK {
+			t.Fatalf("expected 200 got %d", ctx.status)
+		}
+		resp := ctx.resp.(BucketResponse)
+		if resp.Name != "alive" || !resp.CreatedAt.Equal(now) {
+			t.Fatalf("unexpected response: %+v", resp)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		ctx := &fakeContext{params: map[string]string{"bucketName": "missing"}}
+		svc.GetBucket(ctx)
+		if ctx.status != http.StatusNotFound {
+			t.Fatalf("expected 404 got %d", ctx.status)
+		}
+	})
+
+	t.Run("invalid name", func(t *testing.T) {
+		ctx := &fakeContext{params: map[string]string{"bucketName": "AA"}}
+		svc.GetBucket(ctx)
+		if ctx.status != http.StatusBadRequest {
+			t.Fatalf("expected 400 got %d", ctx.status)
+		}
+	})
+
+	t.Run("list error", func(t *testing.T) {
+		client.listErr = errors.New("boom")
+		ctx := &fakeContext{params: map[string]string{"bucketName": "alive"}}
+		svc.GetBucket(ctx)
+		if ctx.status != http.StatusInternalServerError {
+			t.Fatalf("expected 500 got %d", ctx.status)
+		}
+		client.listErr = nil
+	})
+}
+
+func TestListObjects(t *testing.T) {
+	client := &fakeStorageClient{
+		existsMap: map[string]bool{"photos": true},
+		objects: map[string][]byte{
+			"photos/a.txt":          []byte("a"),
+			"photos/b.txt":          []byte("bb"),
+			"photos/2024/jan/1.jpg": []byte("1"),
+			"photos/2024/feb/2.jpg": []byte("2"),
+			"photos/2025/3.jpg":     []byte("3"),
+		},
+	}
+	svc := NewStorageServiceWithClient(client, "", nil)
+
+	t.Run("delimiter groups folders", func(t *testing.T) {
+		ctx := &fakeContext{
+			params: map[string]string{"bucketName": "photos"},
+			query:  map[string]string{"delimiter": "/"},
+		}
+		svc.ListObjects(ctx)
+		if ctx.status != http.StatusOK {
+			t.Fatalf("expected 200 got %d", ctx.status)
+		}
+		resp := ctx.resp.(ListObjectsResponse)
+		if len(resp.Objects) != 2 || resp.Objects[0].Key != "a.txt" || resp.Objects[1].Key != "b.txt" {
+			t.Fatalf("unexpected objects: %+v", resp.Objects)
+		}
+		if len(resp.CommonPrefixes) != 2 || resp.CommonPrefixes[0] != "2024/" || resp.CommonPrefixes[1] != "2025/" {
+			t.Fatalf("unexpected prefixes: %+v", resp.CommonPrefixes)
+		}
+		if resp.IsTruncated {
+			t.Fatalf("did not expect truncation")
+		}
+	})
+
+	t.Run("prefix", func(t *testing.T) {
+		ctx := &fakeContext{
+			params: map[string]string{"bucketName": "photos"},
+			query:  map[string]string{"prefix": "2024/", "delimiter": "/"},
+		}
+		svc.ListObjects(ctx)
+		resp := ctx.resp.(ListObjectsResponse)
+		if len(resp.Objects) != 0 || len(resp.CommonPrefixes) != 2 || resp.CommonPrefixes[0] != "2024/feb/" {
+			t.Fatalf("unexpected listing: %+v", resp)
+		}
+	})
+
+	t.Run("pagination", func(t *testing.T) {
+		var seen []string
+		cursor := ""
+		for i := 0; i < 10; i++ {
+			ctx := &fakeContext{
+				params: map[string]string{"bucketName": "photos"},
+				query:  map[string]string{"delimiter": "/", "limit": "1", "cursor": cursor},
+			}
+			svc.ListObjects(ctx)
+			if ctx.status != http.StatusOK {
+				t.Fatalf("expected 200 got %d", ctx.status)
+			}
+			resp := ctx.resp.(ListObjectsResponse)
+			seen = append(seen, resp.CommonPrefixes...)
+			for _, o := range resp.Objects {
+				seen = append(seen, o.Key)
+			}
+			if !resp.IsTruncated {
+				break
+			}
+			cursor = resp.NextCursor
+		}
+		want := "2024/,2025/,a.txt,b.txt"
+		if got := strings.Join(seen, ","); got != want {
+			t.Fatalf("expected %s got %s", want, got)
+		}
+	})
+
+	t.Run("korean names across pages", func(t *testing.T) {
+		names := []string{"가족/사진.jpg", "보고서 (최종).pdf", "a.txt", "b c.txt", "하늘.png", "z.txt"}
+		korean := &fakeStorageClient{existsMap: map[string]bool{"docs": true}, objects: map[string][]byte{}}
+		for _, name := range names {
+			korean.objects["docs/"+encodeObjectKey(name)] = []byte(name)
+		}
+		ksvc := NewStorageServiceWithClient(korean, "", nil)
+
+		for _, limit := range []string{"1", "2", "4"} {
+			var seen []string
+			cursor := ""
+			for i := 0; i < 20; i++ {
+				ctx := &fakeContext{
+					params: map[string]string{"bucketName": "docs"},
+					query:  map[string]string{"limit": limit, "cursor": cursor},
+				}
+				ksvc.ListObjects(ctx)
+				if ctx.status != http.StatusOK {
+					t.Fatalf("expected 200 got %d", ctx.status)
+				}
+				resp := ctx.resp.(ListObjectsResponse)
+				for _, o := range resp.Objects {
+					seen = append(seen, o.Key)
+				}
+				if !resp.IsTruncated {
+					break
+				}
+				cursor = resp.NextCursor
+			}
+			got := slices.Clone(seen)
+			want := slices.Clone(names)
+			sort.Strings(got)
+			sort.Strings(want)
+			if !slices.Equal(got, want) {
+				t.Fatalf("limit %s: every object must be listed exactly once, got %q", limit, seen)
+			}
+		}
+	})
+
+	t.Run("invalid cursor", func(t *testing.T) {
+		ctx := &fakeContext{
+			params: map[string]string{"bucketName": "photos"},
+			query:  map[string]string{"cursor": "!!"},
+		}
+		svc.ListObjects(ctx)
+		if ctx.status != http.StatusBadRequest {
+			t.Fatalf("expected 400 got %d", ctx.status)
+		}
+	})
+
+	t.Run("bucket not found", func(t *testing.T) {
+		ctx := &fakeContext{params: map[string]string{"bucketName": "missing"}}
+		svc.ListObjects(ctx)
+		if ctx.status != http.StatusNotFound {
+			t.Fatalf("expected 404 got %d", ctx.status)
+		}
+	})
+}
+
+func TestDeleteObject(t *testing.T) {
+	client := &fakeStorageClient{objects: map[string][]byte{"docs/a.txt": []byte("a")}}
+	svc := NewStorageServiceWithClient(client, "", nil)
+
+	t.Run("success", func(t *testing.T) {
+		ctx := &fakeContext{params: map[string]string{"bucketName": "docs", "objectName": "a.txt"}}
+		svc.DeleteObject(ctx)
+		if ctx.status != http.StatusOK {
+			t.Fatalf("expected 200 got %d", ctx.status)
+		}
+		if _, ok := client.objects["docs/a.txt"]; ok {
+			t.Fatalf("object was not removed")
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		ctx := &fakeContext{params: map[string]string{"bucketName": "docs", "objectName": "a.txt"}}
+		svc.DeleteObject(ctx)
+		if ctx.status != http.StatusNotFound {
+			t.Fatalf("expected 404 got %d", ctx.status)
+		}
+	})
+
+	t.Run("backend error", func(t *testing.T) {
+		client.objects["docs/b.txt"] = []byte("b")
+		client.statErr = errors.New("connection refused")
+		defer func() { client.statErr = nil }()
+		ctx := &fakeContext{params: map[string]string{"bucketName": "docs", "objectName": "b.txt"}}
+		svc.DeleteObject(ctx)
+		if ctx.status != http.StatusInternalServerError {
+			t.Fatalf("expected 500 got %d", ctx.status)
+		}
+		if _, ok := client.objects["docs/b.txt"]; !ok {
+			t.Fatalf("object must not be removed")
+		}
+	})
+}
+
+func TestDeleteObjects(t *testing.T) {
+	client := &fakeStorageClient{objects: map[string][]byte{
+		"docs/a.txt": []byte("a"),
+		"docs/b.txt": []byte("b"),
+	}}
+	svc := NewStorageServiceWithClient(client, "", nil)
+
+	t.Run("per key results", func(t *testing.T) {
+		ctx := &fakeContext{
+			params: map[string]string{"bucketName": "docs"},
+			body:   []byte(`{"keys":["a.txt","b.txt","a.txt","missing.txt"]}`),
+		}
+		svc.DeleteObjects(ctx)
+		if ctx.status != http.StatusOK {
+			t.Fatalf("expected 200 got %d", ctx.status)
+		}
+		results := ctx.resp.(DeleteObjectsResponse).Results
+		if len(results) != 3 {
+			t.Fatalf("expected 3 results got %+v", results)
+		}
+		if !results[0].Deleted || !results[1].Deleted || results[2].Deleted || results[2].Error == "" {
+			t.Fatalf("unexpected results: %+v", results)
+		}
+		if len(client.objects) != 0 {
+			t.Fatalf("objects left behind: %+v", client.objects)
+		}
+	})
+
+	t.Run("empty keys", func(t *testing.T) {
+		ctx := &fakeContext{params: map[string]string{"bucketName": "docs"}, body: []byte(`{"keys":[]}`)}
+		svc.DeleteObjects(ctx)
+		if ctx.status != http.StatusBadRequest {
+			t.Fatalf("expected 400 got %d", ctx.status)
+		}
+	})
+
+	t.Run("too many keys", func(t *testing.T) {
+		keys := make([]string, maxDeleteObjects+1)
+		for i := range keys {
+			keys[i] = fmt.Sprintf("k%d", i)
+		}
+		body, _ := json.Marshal(DeleteObjectsRequest{Keys: keys})
+		ctx := &fakeContext{params: map[string]string{"bucketName": "docs"}, body: body}
+		svc.DeleteObjects(ctx)
+		if ctx.status != http.StatusBadRequest {
+			t.Fatalf("expected 400 got %d", ctx.status)
+		}
+	})
+}
+
+func TestHeadObject(t *testing.T) {
+	client := &fakeStorageClient{
+		objects:  map[string][]byte{"docs/a.txt": []byte("hello")},
+		userMeta: map[string]map[string]string{"docs/a.txt": {"owner": "guiwoo"}},
+	}
+	svc := NewStorageServiceWithClient(client, "", nil)
+
+	t.Run("headers", func(t *testing.T) {
+		ctx := &fakeContext{
+			params: map[string]string{"bucketName": "docs", "objectName": "a.txt"},
+			req:    httptest.NewRequest(http.MethodHead, "/api/v1/buckets/docs/objects/a.txt", nil),
+		}
+		svc.HeadObject(ctx)
+		if ctx.status != http.StatusOK {
+			t.Fatalf("expected 200 got %d", ctx.status)
+		}
+		if ctx.headers.Get("Content-Length") != "5" || ctx.headers.Get("ETag") != "etag" {
+			t.Fatalf("unexpected headers: %+v", ctx.headers)
+		}
+		if ctx.headers.Get("X-Guiio-Meta-Owner") != "guiwoo" {
+			t.Fatalf("metadata header missing: %+v", ctx.headers)
+		}
+		if len(ctx.stream) != 0 {
+			t.Fatalf("HEAD must not send a body")
+		}
+	})
+
+	t.Run("not modified", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodHead, "/api/v1/buckets/docs/objects/a.txt", nil)
+		req.Header.Set("If-None-Match", `"etag"`)
+		ctx := &fakeContext{
+			params: map[string]string{"bucketName": "docs", "objectName": "a.txt"},
+			req:    req,
+		}
+		svc.HeadObject(ctx)
+		if ctx.status != http.StatusNotModified {
+			t.Fatalf("expected 304 got %d", ctx.status)
+		}
+	})
+
+	t.Run("stat json", func(t *testing.T) {
+		ctx := &fakeContext{
+			params: map[string]string{"bucketName": "docs", "objectName": "a.txt"},
+			req:    httptest.NewRequest(http.MethodGet, "/api/v1/buckets/docs/objects/a.txt?stat", nil),
+		}
+		svc.DownloadObject(ctx)
+		if ctx.status != http.StatusOK {
+			t.Fatalf("expected 200 got %d", ctx.status)
+		}
+		resp := ctx.resp.(ObjectStatResponse)
+		if resp.Size != 5 || resp.Metadata["owner"] != "guiwoo" {
+			t.Fatalf("unexpected stat: %+v", resp)
+		}
+	})
+
+	t.Run("not found", func(t *testing.T) {
+		ctx := &fakeContext{params: map[string]string{"bucketName": "docs", "objectName": "none"}}
+		svc.HeadObject(ctx)
+		if ctx.status != http.StatusNotFound {
+			t.Fatalf("expected 404 got %d", ctx.status)
+		}
+	})
+}
+
+func TestParseRange(t *testing.T) {
+	cases := []struct {
+		header string
+		size   int64
+		want   []httpRange
+		err    error
+	}{
+		{"bytes=0-4", 10, []httpRange{{0, 5}}, nil},
+		{"bytes=5-", 10, []httpRange{{5, 5}}, nil},
+		{"bytes=-3", 10, []httpRange{{7, 3}}, nil},
+		{"bytes=-30", 10, []httpRange{{0, 10}}, nil},
+		{"bytes=8-20", 10, []httpRange{{8, 2}}, nil},
+		{"bytes=0-1, 4-5", 10, []httpRange{{0, 2}, {4, 2}}, nil},
+		{"bytes=6-7,0-1", 10, []httpRange{{0, 2}, {6, 2}}, nil},
+		{"bytes=0-4,3-8", 10, []httpRange{{0, 9}}, nil},
+		{"bytes=0-1,2-3,-2", 10, []httpRange{{0, 4}, {8, 2}}, nil},
+		{"bytes=0-0,0-0,0-0", 10, []httpRange{{0, 1}}, nil},
+		{"bytes=10-", 10, nil, errRangeNoOverlap},
+		{"bytes=3-1", 10, nil, errInvalidRange},
+		{"items=0-1", 10, nil, errInvalidRange},
+		{"bytes=abc", 10, nil, errInvalidRange},
+	}
+	for _, tc := range cases {
+		got, err := parseRange(tc.header, tc.size)
+		if !errors.Is(err, tc.err) {
+			t.Fatalf("%s: expected err %v got %v", tc.header, tc.err, err)
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tc.want) {
+			t.Fatalf("%s: expected %v got %v", tc.header, tc.want, got)
+		}
+	}
+}
+
+func TestDownloadObjectRange(t *testing.T) {
+	client := &fakeStorageClient{objects: map[string][]byte{"docs/a.txt": []byte("0123456789")}}
+	svc := NewStorageServiceWithClient(client, "", nil)
+
+	newCtx := func(header map[string]string) *fakeContext {
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/buckets/docs/objects/a.txt", nil)
+		for k, v := range header {
+			req.Header.Set(k, v)
+		}
+		return &fakeContext{params: map[string]string{"bucketName": "docs", "objectName": "a.txt"}, req: req}
+	}
+
+	t.Run("single range", func(t *testing.T) {
+		ctx := newCtx(map[string]string{"Range": "bytes=2-5"})
+		svc.DownloadObject(ctx)
+		if ctx.status != http.StatusPartialContent {
+			t.Fatalf("expected 206 got %d", ctx.status)
+		}
+		if string(ctx.stream) != "2345" || ctx.headers.Get("Content-Range") != "bytes 2-5/10" {
+			t.Fatalf("unexpected body %q headers %+v", ctx.stream, ctx.headers)
+		}
+	})
+
+	t.Run("multi range", func(t *testing.T) {
+		ctx := newCtx(map[string]string{"Range": "bytes=0-1,8-"})
+		svc.DownloadObject(ctx)
+		if ctx.status != http.StatusPartialContent {
+			t.Fatalf("expected 206 got %d", ctx.status)
+		}
+		if cl := ctx.headers.Get("Content-Length"); cl != fmt.Sprint(len(ctx.stream)) {
+			t.Fatalf("content-length %s does not match body %d", cl, len(ctx.stream))
+		}
+		if !bytes.Contains(ctx.stream, []byte("Content-Range: bytes 8-9/10")) || !bytes.Contains(ctx.stream, []byte("\r\n\r\n89\r\n")) {
+			t.Fatalf("unexpected multipart body: %q", ctx.stream)
+		}
+	})
+
+	t.Run("not satisfiable", func(t *testing.T) {
+		ctx := newCtx(map[string]string{"Range": "bytes=20-"})
+		svc.DownloadObject(ctx)
+		if ctx.status != http.StatusRequestedRangeNotSatisfiable {
+			t.Fatalf("expected 416 got %d", ctx.status)
+		}
+		if ctx.headers.Get("Content-Range") != "bytes */10" {
+			t.Fatalf("unexpected content-range: %s", ctx.headers.Get("Content-Range"))
+		}
+	})
+
+	t.Run("invalid range is ignored", func(t *testing.T) {
+		ctx := newCtx(map[string]string{"Range": "bytes=abc"})
+		svc.DownloadObject(ctx)
+		if ctx.status != http.StatusOK || string(ctx.stream) != "0123456789" {
+			t.Fatalf("expected full 200 got %d %q", ctx.status, ctx.stream)
+		}
+	})
+
+	t.Run("too many ranges sends full body", func(t *testing.T) {
+		big := bytes.Repeat([]byte("x"), 2*(maxRanges+1))
+		client.objects["docs/big.bin"] = big
+		specs := make([]string, 0, maxRanges+1)
+		for i := 0; i <= maxRanges; i++ {
+			specs = append(specs, fmt.Sprintf("%d-%d", 2*i, 2*i))
+		}
+		req := httptest.NewRequest(http.MethodGet, "/api/v1/buckets/docs/objects/big.bin", nil)
+		req.Header.Set("Range", "bytes="+strings.Join(specs, ","))
+		ctx := &fakeContext{params: map[string]string{"bucketName": "docs", "objectName": "big.bin"}, req: req}
+		svc.DownloadObject(ctx)
+		if ctx.status != http.StatusOK || !bytes.Equal(ctx.stream, big) {
+			t.Fatalf("expected full 200 got %d", ctx.status)
+		}
+	})
+
+	t.Run("if-range mismatch sends full body", func(t *testing.T) {
+		ctx := newCtx(map[string]string{"Range": "bytes=0-1", "If-Range": `"other"`})
+		svc.DownloadObject(ctx)
+		if ctx.status != http.StatusOK || string(ctx.stream) != "0123456789" {
+			t.Fatalf("expected full 200 got %d %q", ctx.status, ctx.stream)
+		}
+	})
+}
+
+func TestPutObject(t *testing.T) {
+	client := &fakeStorageClient{}
+	svc := NewStorageServiceWithClient(client, "", nil)
+
+	t.Run("chunked body with metadata", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/buckets/docs/objects/a.txt", strings.NewReader("streamed"))
+		req.ContentLength = -1
+		req.Header.Set("Content-Type", "text/plain")
+		req.Header.Set("X-Guiio-Meta-Owner", "guiwoo")
+		ctx := &fakeContext{params: map[string]string{"bucketName": "docs", "objectName": "a.txt"}, req: req}
+		svc.PutObject(ctx)
+		if ctx.status != http.StatusCreated {
+			t.Fatalf("expected 201 got %d: %+v", ctx.status, ctx.resp)
+		}
+		resp := ctx.resp.(*UploadObjectResponse)
+		if resp.ContentType != "text/plain" || string(client.objects["docs/a.txt"]) != "streamed" {
+			t.Fatalf("unexpected upload: %+v", resp)
+		}
+		// 파트 크기를 지정하지 않으면 minio-go가 요청마다 수백 MiB 버퍼를 잡습니다.
+		if opts := client.putOpts[len(client.putOpts)-1]; opts.PartSize != streamPartSize {
+			t.Fatalf("expected part size %d for chunked upload got %d", streamPartSize, opts.PartSize)
+		}
+	})
+
+	t.Run("known length leaves part size to backend", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/buckets/docs/objects/b.txt", strings.NewReader("sized"))
+		ctx := &fakeContext{params: map[string]string{"bucketName": "docs", "objectName": "b.txt"}, req: req}
+		svc.PutObject(ctx)
+		if ctx.status != http.StatusCreated {
+			t.Fatalf("expected 201 got %d: %+v", ctx.status, ctx.resp)
+		}
+		if opts := client.putOpts[len(client.putOpts)-1]; opts.PartSize != 0 {
+			t.Fatalf("expected default part size got %d", opts.PartSize)
+		}
+	})
+
+	t.Run("content length over limit", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/buckets/docs/objects/big", strings.NewReader("x"))
+		req.ContentLength = 2 << 20
+		ctx := &fakeContext{params: map[string]string{"bucketName": "docs", "objectName": "big"}, req: req}
+		svc.PutObject(ctx)
+		if ctx.status != http.StatusRequestEntityTooLarge {
+			t.Fatalf("expected 413 got %d", ctx.status)
+		}
+	})
+
+	t.Run("chunked body over limit", func(t *testing.T) {
+		req := httptest.NewRequest(http.MethodPut, "/api/v1/buckets/docs/objects/big", bytes.NewReader(make([]byte, 1<<20+1)))
+		req.ContentLength = -1
+		ctx := &fakeContext{params: map[string]string{"bucketName": "docs", "objectName": "big"}, req: req}
+		svc.PutObject(ctx)
+		if ctx.status != http.StatusRequestEntityTooLarge {
+			t.Fatalf("expected 413 got %d", ctx.status)
+		}
+	})
+}
+
+func TestStreamPartSizeFor(t *testing.T) {
+	if got := streamPartSizeFor(5 << 30); got != streamPartSize {
+		t.Fatalf("expected %d got %d", streamPartSize, got)
+	}
+	if got := streamPartSizeFor(0); got != streamPartSize {
+		t.Fatalf("expected %d for unlimited size got %d", streamPartSize, got)
+	}
+	// 1TiB는 16MiB 파트로 10000개를 넘으므로 파트를 키웁니다.
+	if got := streamPartSizeFor(1 << 40); got*maxPartNumber < 1<<40 {
+		t.Fatalf("part size %d cannot fit 1TiB in %d parts", got, maxPartNumber)
+	}
+}
+
+func TestMetadataFromHeader(t *testing.T) {
+	h := http.Header{}
+	h.Set("X-Guiio-Meta-Project", "guiio")
+	h.Set("X-Guiio-Meta-", "empty")
+	h.Set("Content-Type", "text/plain")
+	meta := metadataFromHeader(h)
+	if len(meta) != 1 || meta["project"] != "guiio" {
+		t.Fatalf("unexpected metadata: %+v", meta)
+	}
+}
+
+func TestMultipartUpload(t *testing.T) {
+	client := &fakeStorageClient{existsMap: map[string]bool{"builds": true}}
+	repo := newFakeObjectRepository()
+	svc := NewStorageServiceWithClient(client, "", repo)
+
+	ctx := &fakeContext{
+		params: map[string]string{"bucketName": "builds"},
+		body:   []byte(`{"object":"app/release.tar","content_type":"application/x-tar","metadata":{"build":"42"}}`),
+	}
+	svc.InitiateMultipartUpload(ctx)
+	if ctx.status != http.StatusCreated {
+		t.Fatalf("expected 201 got %d: %+v", ctx.status, ctx.resp)
+	}
+	uploadID := ctx.resp.(MultipartUploadResponse).UploadID
+
+	uploadPart := func(number, body string) *fakeContext {
+		req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
+		ctx := &fakeContext{
+			params: map[string]string{"bucketName": "builds", "uploadId": uploadID, "partNumber": number},
+			req:    req,
+		}
+		svc.UploadPart(ctx)
+		return ctx
+	}
+
+	// 파트 순서와 무관하게 올리고, 2번 파트는 재시도로 덮어씁니다.
+	for _, p := range [][2]string{{"2", "broken"}, {"1", "hello "}, {"2", "world"}} {
+		if ctx := uploadPart(p[0], p[1]); ctx.status != http.StatusOK {
+			t.Fatalf("upload part %s: expected 200 got %d", p[0], ctx.status)
+		}
+	}
+
+	if ctx := uploadPart("0", "x"); ctx.status != http.StatusBadRequest {
+		t.Fatalf("expected 400 for part 0 got %d", ctx.status)
+	}
+
+	ctx = &fakeContext{params: map[string]string{"bucketName": "builds", "uploadId": uploadID}}
+	svc.ListParts(ctx)
+	if parts := ctx.resp.(ListPartsResponse).Parts; len(parts) != 2 || parts[1].Size != 5 {
+		t.Fatalf("unexpected parts: %+v", parts)
+	}
+
+	ctx = &fakeContext{
+		params: map[string]string{"bucketName": "builds", "uploadId": uploadID},
+		body:   []byte(`{"parts":[{"part_number":2,"etag":"etag-2"},{"part_number":1}]}`),
+	}
+	svc.CompleteMultipartUpload(ctx)
+	if ctx.status != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unordered parts got %d", ctx.status)
+	}
+
+	ctx = &fakeContext{params: map[string]string{"bucketName": "builds", "uploadId": uploadID}}
+	svc.CompleteMultipartUpload(ctx)
+	if ctx.status != http.StatusOK {
+		t.Fatalf("expected 200 got %d: %+v", ctx.status, ctx.resp)
+	}
+	if got, _ := storedObject(client, repo, "builds", "app/release.tar"); string(got) != "hello world" {
+		t.Fatalf("unexpected object content %q", got)
+	}
+	obj := repo.objects["builds/app/release.tar"]
+	if obj == nil || obj.Size != 11 || obj.ContentType != "application/x-tar" || len(obj.Edges.Metadata) != 1 {
+		t.Fatalf("unexpected object row: %+v", obj)
+	}
+	if len(repo.sessions) != 0 {
+		t.Fatalf("session was not removed")
+	}
+
+	ctx = &fakeContext{params: map[string]string{"bucketName": "builds", "uploadId": uploadID}}
+	svc.AbortMultipartUpload(ctx)
+	if ctx.status != http.StatusNotFound {
+		t.Fatalf("expected 404 after completion got %d", ctx.status)
+	}
+}
+
+func TestCleanupExpiredUploads(t *testing.T) {
+	client := &fakeStorageClient{existsMap: map[string]bool{"builds": true}}
+	repo := newFakeObjectRepository()
+	svc := NewStorageServiceWithClient(client, "", repo)
+
+	for _, name := range []string{"old", "new", "active"} {
+		ctx := &fakeContext{
+			params: map[string]string{"bucketName": "builds"},
+			body:   []byte(fmt.Sprintf(`{"object":%q}`, name)),
+		}
+		svc.InitiateMultipartUpload(ctx)
+	}
+	var active *ent.UploadSession
+	for _, session := range repo.sessions {
+		if session.ObjectName != "new" {
+			session.CreatedAt = time.Now().Add(-48 * time.Hour)
+			session.UpdatedAt = session.CreatedAt
+		}
+		if session.ObjectName == "active" {
+			active = session
+		}
+	}
+
+	// 오래전에 시작했어도 최근에 파트를 올린 업로드는 정리하지 않습니다.
+	ctx := &fakeContext{
+		params: map[string]string{"bucketName": "builds", "uploadId": active.UploadID, "partNumber": "1"},
+		req:    httptest.NewRequest(http.MethodPut, "/", strings.NewReader("part")),
+	}
+	svc.UploadPart(ctx)
+	if ctx.status != http.StatusOK {
+		t.Fatalf("expected 200 got %d: %+v", ctx.status, ctx.resp)
+	}
+
+	n, err := svc.CleanupExpiredUploads(context.Background(), time.Now().Add(-24*time.Hour))
+	if err != nil || n != 1 {
+		t.Fatalf("expected 1 aborted upload got %d (%v)", n, err)
+	}
+	if len(repo.sessions) != 2 || len(client.uploads) != 2 || repo.sessions[active.UploadID] == nil {
+		t.Fatalf("unexpected remaining state: sessions=%d uploads=%d", len(repo.sessions), len(client.uploads))
+	}
+}
+
+func TestMultipartUploadSizeLimit(t *testing.T) {
+	client := &fakeStorageClient{existsMap: map[string]bool{"builds": true}}
+	repo := newFakeObjectRepository()
+	svc := NewStorageServiceWithClient(client, "", repo)
+
+	ctx := &fakeContext{params: map[string]string{"bucketName": "builds"}, body: []byte(`{"object":"big.bin"}`)}
+	svc.InitiateMultipartUpload(ctx)
+	uploadID := ctx.resp.(MultipartUploadResponse).UploadID
+
+	part := bytes.Repeat([]byte("x"), 600<<10)
+	uploadPart := func(number string) int {
+		ctx := &fakeContext{
+			params: map[string]string{"bucketName": "builds", "uploadId": uploadID, "partNumber": number},
+			req:    httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(part)),
+		}
+		svc.UploadPart(ctx)
+		return ctx.status
+	}
+
+	if status := uploadPart("1"); status != http.StatusOK {
+		t.Fatalf("expected 200 got %d", status)
+	}
+	// 파트 하나는 제한 안이어도 합계가 object_max_upload_size(1MiB)를 넘으면 거절합니다.
+	if status := uploadPart("2"); status != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413 for running total got %d", status)
+	}
+	// 같은 번호를 다시 올리면 이전 파트를 대체하므로 합계에 두 번 세지 않습니다.
+	if status := uploadPart("1"); status != http.StatusOK {
+		t.Fatalf("expected 200 for retried part got %d", status)
+	}
+
+	// 동시에 올라와 각각 확인을 통과한 파트도 완료할 때 합계로 다시 거절합니다.
+	session := repo.sessions[uploadID]
+	if _, err := repo.UpsertUploadPart(context.Background(), repository.UploadPartInput{SessionID: session.ID, PartNumber: 2, ETag: "etag-2", Size: int64(len(part))}); err != nil {
+		t.Fatal(err)
+	}
+	ctx = &fakeContext{params: map[string]string{"bucketName": "builds", "uploadId": uploadID}}
+	svc.CompleteMultipartUpload(ctx)
+	if ctx.status != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413 on complete got %d: %+v", ctx.status, ctx.resp)
+	}
+	if _, ok := client.objects["builds/big.bin"]; ok || len(dataKeys(client, "builds")) != 0 {
+		t.Fatal("oversized upload must not be completed")
+	}
+}
+
+func TestTusUpload(t *testing.T) {
+	client := &fakeStorageClient{existsMap: map[string]bool{"media": true}}
+	repo := newFakeObjectRepository()
+	svc := NewStorageServiceWithClient(client, "", repo)
+
+	b64 := base64.StdEncoding.EncodeToString
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/buckets/media/tus", nil)
+	req.Header.Set("Tus-Resumable", "1.0.0")
+	req.Header.Set("Upload-Length", "11")
+	req.Header.Set("Upload-Metadata", "filename "+b64([]byte("clips/a.txt"))+",filetype "+b64([]byte("text/plain"))+",author "+b64([]byte("kim")))
+	ctx := &fakeContext{params: map[string]string{"bucketName": "media"}, req: req}
+	svc.CreateTusUpload(ctx)
+	if ctx.status != http.StatusCreated {
+		t.Fatalf("expected 201 got %d: %+v", ctx.status, ctx.resp)
+	}
+	location := ctx.headers.Get("Location")
+	uploadID := location[strings.LastIndex(location, "/")+1:]
+	if !strings.HasPrefix(location, "/api/v1/buckets/media/tus/") || uploadID == "" {
+		t.Fatalf("unexpected location %q", location)
+	}
+
+	patch := func(offset, body string) *fakeContext {
+		req := httptest.NewRequest(http.MethodPatch, location, strings.NewReader(body))
+		req.Header.Set("Tus-Resumable", "1.0.0")
+		req.Header.Set("Content-Type", "application/offset+octet-stream")
+		req.Header.Set("Upload-Offset", offset)
+		ctx := &fakeContext{params: map[string]string{"bucketName": "media", "uploadId": uploadID}, req: req}
+		svc.PatchTusUpload(ctx)
+		return ctx
+	}
+
+	if ctx := patch("0", "hello "); ctx.status != http.StatusNoContent || ctx.headers.Get("Upload-Offset") != "6" {
+		t.Fatalf("expected 204 with offset 6 got %d %q", ctx.status, ctx.headers.Get("Upload-Offset"))
+	}
+	if ctx := patch("0", "hello "); ctx.status != http.StatusConflict {
+		t.Fatalf("expected 409 for stale offset got %d", ctx.status)
+	}
+
+	req = httptest.NewRequest(http.MethodHead, location, nil)
+	req.Header.Set("Tus-Resumable", "1.0.0")
+	ctx = &fakeContext{params: map[string]string{"bucketName": "media", "uploadId": uploadID}, req: req}
+	svc.HeadTusUpload(ctx)
+	if ctx.status != http.StatusOK || ctx.headers.Get("Upload-Offset") != "6" || ctx.headers.Get("Upload-Length") != "11" {
+		t.Fatalf("unexpected HEAD response %d %+v", ctx.status, ctx.headers)
+	}
+
+	if ctx := patch("6", "world"); ctx.status != http.StatusNoContent {
+		t.Fatalf("expected 204 got %d: %+v", ctx.status, ctx.resp)
+	}
+	if got, _ := storedObject(client, repo, "media", "clips/a.txt"); string(got) != "hello world" {
+		t.Fatalf("unexpected object content %q", got)
+	}
+	obj := repo.objects["media/clips/a.txt"]
+	if obj == nil || obj.ContentType != "text/plain" || len(obj.Edges.Metadata) != 1 || obj.Edges.Metadata[0].Key != "author" {
+		t.Fatalf("unexpected object row: %+v", obj)
+	}
+	if len(repo.tus) != 0 {
+		t.Fatalf("tus upload was not removed")
+	}
+}
+
+func TestTusUploadRequiresResumableHeader(t *testing.T) {
+	svc := NewStorageServiceWithClient(&fakeStorageClient{}, "", newFakeObjectRepository())
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/buckets/media/tus", nil)
+	req.Header.Set("Upload-Length", "1")
+	ctx := &fakeContext{params: map[string]string{"bucketName": "media"}, req: req}
+	svc.CreateTusUpload(ctx)
+	if ctx.status != http.StatusPreconditionFailed || ctx.headers.Get("Tus-Version") != "1.0.0" {
+		t.Fatalf("expected 412 with Tus-Version got %d %+v", ctx.status, ctx.headers)
+	}
+}
+
+func TestCleanupExpiredTusUploadsSkipsLockedUploads(t *testing.T) {
+	repo := newFakeObjectRepository()
+	svc := NewStorageServiceWithClient(&fakeStorageClient{}, "", repo)
+	expired := time.Now().Add(-time.Hour)
+	for _, id := range []string{"busy", "idle"} {
+		repo.tus[id] = &ent.TusUpload{ID: len(repo.tus) + 1, UploadID: id, BucketName: "media", ExpiresAt: expired}
+		if err := os.WriteFile(tusFilePath(id), []byte("partial"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// PATCH가 처리 중인 업로드는 스테이징 파일과 행을 그대로 둬야 합니다.
+	unlock, ok := svc.tryLockTusUpload("media", "busy")
+	if !ok {
+		t.Fatal("expected to lock upload")
+	}
+	n, err := svc.CleanupExpiredTusUploads(context.Background(), time.Now())
+	if err != nil || n != 1 {
+		t.Fatalf("expected 1 removed upload, got %d (%v)", n, err)
+	}
+	if _, ok := repo.tus["busy"]; !ok {
+		t.Fatal("locked upload must not be removed")
+	}
+	if _, err := os.Stat(tusFilePath("busy")); err != nil {
+		t.Fatalf("locked upload file must be kept: %v", err)
+	}
+	if _, ok := repo.tus["idle"]; ok {
+		t.Fatal("idle expired upload must be removed")
+	}
+
+	unlock()
+	if n, err := svc.CleanupExpiredTusUploads(context.Background(), time.Now()); err != nil || n != 1 {
+		t.Fatalf("expected released upload to be removed, got %d (%v)", n, err)
+	}
+	if _, err := os.Stat(tusFilePath("busy")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("released upload file must be removed: %v", err)
+	}
+}
+
+func TestParseTusMetadata(t *testing.T) {
+	meta, err := parseTusMetadata("filename d29ybGQudHh0,is_confidential")
+	if err != nil || meta["filename"] != "world.txt" || meta["is_confidential"] != "" || len(meta) != 2 {
+		t.Fatalf("unexpected metadata %+v (%v)", meta, err)
+	}
+	for _, header := range []string{"a YQ==,a Yg==", "name !!!", " , "} {
+		if _, err := parseTusMetadata(header); err == nil {
+			t.Fatalf("expected error for %q", header)
+		}
+	}
+}
+
+func TestCopyObject(t *testing.T) {
+	client := &fakeStorageClient{
+		existsMap: map[string]bool{"src": true, "dst": true},
+		objects:   map[string][]byte{"src/report.pdf": []byte("pdf")},
+	}
+	repo := newFakeObjectRepository()
+	repo.UpsertObject(context.Background(), repository.ObjectUpsertInput{
+		BucketName:  "src",
+		ObjectName:  "report.pdf",
+		StoragePath: "report.pdf",
+		ContentType: "application/pdf",
+		Size:        3,
+		Metadata:    map[string]string{"owner": "kim"},
+	})
+	svc := NewStorageServiceWithClient(client, "", repo)
+
+	copyCtx := func(body string) *fakeContext {
+		return &fakeContext{params: map[string]string{"bucketName": "src", "objectName": "report.pdf"}, body: []byte(body)}
+	}
+
+	ctx := copyCtx(`{"destination_bucket":"dst","destination_key":"archive/report.pdf"}`)
+	svc.CopyObject(ctx)
+	if ctx.status != http.StatusOK {
+		t.Fatalf("expected 200 got %d: %+v", ctx.status, ctx.resp)
+	}
+	if got, _ := storedObject(client, repo, "dst", "archive/report.pdf"); string(got) != "pdf" || client.objects["src/report.pdf"] == nil {
+		t.Fatalf("unexpected backend objects: %v", client.objects)
+	}
+	copied := repo.objects["dst/archive/report.pdf"]
+	if copied == nil || copied.ContentType != "application/pdf" || len(copied.Edges.Metadata) != 1 || copied.Edges.Metadata[0].Value != "kim" {
+		t.Fatalf("metadata was not copied: %+v", copied)
+	}
+
+	ctx = copyCtx(`{"destination_key":"renamed.pdf","metadata_directive":"replace","content_type":"application/x-pdf","metadata":{"Stage":"final"}}`)
+	svc.MoveObject(ctx)
+	if ctx.status != http.StatusOK || !ctx.resp.(*CopyObjectResponse).Moved {
+		t.Fatalf("expected moved 200 got %d: %+v", ctx.status, ctx.resp)
+	}
+	if _, ok := client.objects["src/report.pdf"]; ok {
+		t.Fatalf("source object was not removed")
+	}
+	if _, ok := repo.objects["src/report.pdf"]; ok {
+		t.Fatalf("source row was not removed")
+	}
+	moved := repo.objects["src/renamed.pdf"]
+	if moved == nil || moved.ContentType != "application/x-pdf" || len(moved.Edges.Metadata) != 1 || moved.Edges.Metadata[0].Key != "stage" {
+		t.Fatalf("metadata was not replaced: %+v", moved)
+	}
+	if meta := client.userMeta["src/"+moved.StoragePath]; meta["Content-Type"] != "application/x-pdf" {
+		t.Fatalf("backend metadata was not replaced: %v", meta)
+	}
+
+	cases := []struct {
+		source string
+		body   string
+		status int
+	}{
+		{"report.pdf", `{"destination_key":"x"}`, http.StatusNotFound},
+		{"renamed.pdf", `{"destination_bucket":"missing","destination_key":"x"}`, http.StatusNotFound},
+		{"renamed.pdf", `{"destination_key":""}`, http.StatusBadRequest},
+		{"renamed.pdf", `{"destination_key":"x","metadata_directive":"MERGE"}`, http.StatusBadRequest},
+		{"renamed.pdf", `{"destination_key":"renamed.pdf"}`, http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		ctx := &fakeContext{params: map[string]string{"bucketName": "src", "objectName": tc.source}, body: []byte(tc.body)}
+		svc.CopyObject(ctx)
+		if ctx.status != tc.status {
+			t.Fatalf("%s %s: expected %d got %d", tc.source, tc.body, tc.status, ctx.status)
+		}
+	}
+}
+
+func TestMoveObject(t *testing.T) {
+	client := &fakeStorageClient{existsMap: map[string]bool{"docs": true}}
+	repo := newFakeObjectRepository()
+	svc := NewStorageServiceWithClient(client, "", repo)
+	put := func(name, body string) {
+		t.Helper()
+		ctx := &fakeContext{
+			params: map[string]string{"bucketName": "docs", "objectName": name},
+			req:    httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body)),
+		}
+		svc.PutObject(ctx)
+		if ctx.status != http.StatusCreated {
+			t.Fatalf("%s: expected 201 got %d: %+v", name, ctx.status, ctx.resp)
+		}
+	}
+	move := func(src, dst string) int {
+		ctx := &fakeContext{params: map[string]string{"bucketName": "docs", "objectName": src}, body: []byte(`{"destination_key":"` + dst + `"}`)}
+		svc.MoveObject(ctx)
+		return ctx.status
+	}
+
+	// 원본을 지울 수 없으면 키를 잠근 채 확인해 대상을 만들지 않습니다.
+	put("locked.txt", "locked")
+	until := time.Now().Add(time.Hour)
+	repo.objects["docs/locked.txt"].RetentionMode = retentionModeGovernance
+	repo.objects["docs/locked.txt"].RetainUntil = &until
+	if status := move("locked.txt", "a-locked.txt"); status != http.StatusForbidden {
+		t.Fatalf("expected 403 got %d", status)
+	}
+	if _, ok := repo.objects["docs/a-locked.txt"]; ok || len(dataKeys(client, "docs")) != 1 {
+		t.Fatalf("locked source must not leave a destination: %v", dataKeys(client, "docs"))
+	}
+
+	// 원본 행은 커밋한 뒤 본문을 지우므로, 백엔드 삭제가 실패해도 두 객체가 함께 남지 않습니다.
+	put("a.txt", "a")
+	client.removeErr = errors.New("backend unavailable")
+	if status := move("a.txt", "b.txt"); status != http.StatusOK {
+		t.Fatalf("expected 200 got %d", status)
+	}
+	client.removeErr = nil
+	if _, ok := repo.objects["docs/a.txt"]; ok {
+		t.Fatal("source row must be removed")
+	}
+	if got, _ := storedObject(client, repo, "docs", "b.txt"); string(got) != "a" {
+		t.Fatalf("unexpected destination body %q", got)
+	}
+
+	// 반대 방향 이동은 두 키를 같은 순서로 잠그므로 서로 기다리다 멈추지 않습니다.
+	put("c.txt", "c")
+	repo.lockDelay = 10 * time.Millisecond
+	for i := 0; i < 5; i++ {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			var wg sync.WaitGroup
+			wg.Add(2)
+			go func() { defer wg.Done(); move("b.txt", "c.txt") }()
+			go func() { defer wg.Done(); move("c.txt", "b.txt") }()
+			wg.Wait()
+		}()
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("opposite moves deadlocked")
+		}
+		if _, ok := repo.objects["docs/b.txt"]; !ok {
+			put("b.txt", "b")
+		}
+		if _, ok := repo.objects["docs/c.txt"]; !ok {
+			put("c.txt", "c")
+		}
+	}
+}
+
+func TestUpdateObjectMetadata(t *testing.T) {
+	repo := newFakeObjectRepository()
+	repo.UpsertObject(context.Background(), repository.ObjectUpsertInput{
+		BucketName:  "docs",
+		ObjectName:  "a.txt",
+		StoragePath: "a.txt",
+		ContentType: "text/plain",
+		Metadata:    map[string]string{"owner": "kim", "stage": "draft"},
+	})
+	svc := NewStorageServiceWithClient(&fakeStorageClient{}, "", repo)
+
+	patch := func(body string) *fakeContext {
+		ctx := &fakeContext{params: map[string]string{"bucketName": "docs", "objectName": "a.txt"}, body: []byte(body)}
+		svc.UpdateObjectMetadata(ctx)
+		return ctx
+	}
+
+	ctx := patch(`{"metadata":{"Stage":"final","owner":null},"cache_control":"no-cache"}`)
+	if ctx.status != http.StatusOK {
+		t.Fatalf("expected 200 got %d: %+v", ctx.status, ctx.resp)
+	}
+	resp := ctx.resp.(ObjectStatResponse)
+	if len(resp.Metadata) != 1 || resp.Metadata["stage"] != "final" || resp.MetadataRevision != 1 {
+		t.Fatalf("unexpected merge result: %+v", resp)
+	}
+	if ctx.headers.Get("Cache-Control") != "no-cache" || resp.ContentType != "text/plain" {
+		t.Fatalf("unexpected headers %v / content type %q", ctx.headers, resp.ContentType)
+	}
+
+	ctx = patch(`{"mode":"replace","metadata":{"team":"infra"},"content_type":"text/markdown"}`)
+	resp = ctx.resp.(ObjectStatResponse)
+	if len(resp.Metadata) != 1 || resp.Metadata["team"] != "infra" || resp.ContentType != "text/markdown" || resp.MetadataRevision != 2 {
+		t.Fatalf("unexpected replace result: %+v", resp)
+	}
+
+	for _, body := range []string{`{"mode":"append"}`, `{"mode":"replace","metadata":{"a":null}}`, `{"metadata":{"bad key":"x"}}`} {
+		if ctx := patch(body); ctx.status != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400 got %d", body, ctx.status)
+		}
+	}
+
+	ctx = &fakeContext{params: map[string]string{"bucketName": "docs", "objectName": "missing.txt"}, body: []byte(`{}`)}
+	svc.UpdateObjectMetadata(ctx)
+	if ctx.status != http.StatusNotFound {
+		t.Fatalf("expected 404 got %d", ctx.status)
+	}
+}
+
+func TestConditionalWrites(t *testing.T) {
+	client := &fakeStorageClient{existsMap: map[string]bool{"cfg": true}}
+	repo := newFakeObjectRepository()
+	svc := NewStorageServiceWithClient(client, "", repo)
+
+	put := func(body string, header ...string) *fakeContext {
+		req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		ctx := &fakeContext{params: map[string]string{"bucketName": "cfg", "objectName": "app.json"}, req: req}
+		svc.PutObject(ctx)
+		return ctx
+	}
+
+	if ctx := put("v1", "If-None-Match", "*"); ctx.status != http.StatusCreated {
+		t.Fatalf("create only: expected 201 got %d: %+v", ctx.status, ctx.resp)
+	}
+	if ctx := put("v2", "If-None-Match", "*"); ctx.status != http.StatusPreconditionFailed {
+		t.Fatalf("create only on existing key: expected 412 got %d", ctx.status)
+	}
+	repo.objects["cfg/app.json"].Etag = "rev-1"
+
+	if ctx := put("v2", "If-Match", `"rev-0"`); ctx.status != http.StatusPreconditionFailed {
+		t.Fatalf("stale If-Match: expected 412 got %d", ctx.status)
+	}
+	if ctx := put("v2", "If-Match", `W/"rev-1"`); ctx.status != http.StatusPreconditionFailed {
+		t.Fatalf("weak If-Match: expected 412 got %d", ctx.status)
+	}
+	if ctx := put("v2", "If-Match", `"rev-0", "rev-1"`); ctx.status != http.StatusCreated {
+		t.Fatalf("matching If-Match: expected 201 got %d: %+v", ctx.status, ctx.resp)
+	}
+	if got, _ := storedObject(client, repo, "cfg", "app.json"); string(got) != "v2" {
+		t.Fatalf("unexpected content %q", got)
+	}
+
+	del := func(ifMatch string) *fakeContext {
+		req := httptest.NewRequest(http.MethodDelete, "/", nil)
+		req.Header.Set("If-Match", ifMatch)
+		ctx := &fakeContext{params: map[string]string{"bucketName": "cfg", "objectName": "app.json"}, req: req}
+		svc.DeleteObject(ctx)
+		return ctx
+	}
+	repo.objects["cfg/app.json"].Etag = "rev-2"
+	if ctx := del(`"rev-1"`); ctx.status != http.StatusPreconditionFailed {
+		t.Fatalf("stale delete: expected 412 got %d", ctx.status)
+	}
+	if _, ok := storedObject(client, repo, "cfg", "app.json"); !ok {
+		t.Fatalf("object removed despite failed precondition")
+	}
+	if ctx := del(`"rev-2"`); ctx.status != http.StatusOK {
+		t.Fatalf("matching delete: expected 200 got %d", ctx.status)
+	}
+}
+
+func TestConditionalCreateIsAtomic(t *testing.T) {
+	client := &fakeStorageClient{existsMap: map[string]bool{"cfg": true}}
+	svc := NewStorageServiceWithClient(client, "", newFakeObjectRepository())
+
+	const writers = 8
+	statuses := make(chan int, writers)
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(fmt.Sprintf("writer-%d", i)))
+			req.Header.Set("If-None-Match", "*")
+			ctx := &fakeContext{params: map[string]string{"bucketName": "cfg", "objectName": "leader"}, req: req}
+			svc.PutObject(ctx)
+			statuses <- ctx.status
+		}(i)
+	}
+	wg.Wait()
+	close(statuses)
+
+	created := 0
+	for status := range statuses {
+		if status == http.StatusCreated {
+			created++
+		} else if status != http.StatusPreconditionFailed {
+			t.Fatalf("unexpected status %d", status)
+		}
+	}
+	if created != 1 {
+		t.Fatalf("expected exactly one writer to win, got %d", created)
+	}
+}
+
+func TestUnconditionalWriteTakesKeyLock(t *testing.T) {
+	client := &fakeStorageClient{existsMap: map[string]bool{"cfg": true}}
+	repo := newFakeObjectRepository()
+	svc := NewStorageServiceWithClient(client, "", repo)
+	uploaded := make(chan string, 1)
+	client.putHook = func(key string) { uploaded <- key }
+
+	waitUpload := func() string {
+		t.Helper()
+		select {
+		case key := <-uploaded:
+			return key
+		case <-time.After(5 * time.Second):
+			t.Fatal("write did not reach the backend while the key was locked")
+			return ""
+		}
+	}
+	rowWritten := func(name string) bool {
+		repo.mu.Lock()
+		defer repo.mu.Unlock()
+		_, ok := repo.objects["cfg/"+name]
+		return ok
+	}
+
+	// 다른 쓰기가 키를 잡고 있어도 본문은 고유 키에 먼저 올리고, 행은 잠금이 풀린 뒤에 바꿉니다.
+	otx, _ := repo.BeginObjectTx(context.Background(), "cfg", "leader")
+	done := make(chan int)
+	go func() {
+		req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader("plain"))
+		ctx := &fakeContext{params: map[string]string{"bucketName": "cfg", "objectName": "leader"}, req: req}
+		svc.PutObject(ctx)
+		done <- ctx.status
+	}()
+	key := waitUpload()
+	if !strings.HasPrefix(key, "cfg/"+dataKeyPrefix) {
+		t.Fatalf("expected upload to a unique data key, got %s", key)
+	}
+	if rowWritten("leader") {
+		t.Fatal("object row was written while the key was locked")
+	}
+	otx.Rollback()
+
+	if status := <-done; status != http.StatusCreated {
+		t.Fatalf("expected 201 got %d", status)
+	}
+	if obj := repo.objects["cfg/leader"]; obj == nil || "cfg/"+obj.StoragePath != key {
+		t.Fatalf("row should point at the uploaded key %s, got %+v", key, obj)
+	}
+
+	// 잠금을 기다리는 동안 다른 쓰기가 객체를 만들면 조건부 쓰기는 412로 끝나고 올린 본문을 지웁니다.
+	otx, _ = repo.BeginObjectTx(context.Background(), "cfg", "follower")
+	go func() {
+		req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader("late"))
+		req.Header.Set("If-None-Match", "*")
+		ctx := &fakeContext{params: map[string]string{"bucketName": "cfg", "objectName": "follower"}, req: req}
+		svc.PutObject(ctx)
+		done <- ctx.status
+	}()
+	key = waitUpload()
+	if _, err := otx.Upsert(context.Background(), repository.ObjectUpsertInput{BucketName: "cfg", ObjectName: "follower", StoragePath: "winner"}); err != nil {
+		t.Fatalf("upsert: %v", err)
+	}
+	otx.Commit()
+
+	if status := <-done; status != http.StatusPreconditionFailed {
+		t.Fatalf("expected 412 got %d", status)
+	}
+	client.mu.Lock()
+	_, left := client.objects[key]
+	client.mu.Unlock()
+	if left {
+		t.Fatalf("rejected write left its upload %s behind", key)
+	}
+	if obj := repo.objects["cfg/follower"]; obj.StoragePath != "winner" {
+		t.Fatalf("rejected write replaced the row: %+v", obj)
+	}
+}
+
+func TestObjectVersioning(t *testing.T) {
+	client := &fakeStorageClient{existsMap: map[string]bool{"docs": true}}
+	repo := newFakeObjectRepository()
+	svc := NewStorageServiceWithClient(client, "", repo)
+
+	ctx := &fakeContext{params: map[string]string{"bucketName": "docs"}, body: []byte(`{"status":"enabled"}`)}
+	svc.PutBucketVersioning(ctx)
+	if ctx.status != http.StatusOK || ctx.resp.(BucketVersioningResponse).Status != "Enabled" {
+		t.Fatalf("unexpected versioning response %d %+v", ctx.status, ctx.resp)
+	}
+
+	objectParams := func() map[string]string {
+		return map[string]string{"bucketName": "docs", "objectName": "plan.md"}
+	}
+	put := func(body, stage string) UploadObjectResponse {
+		req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
+		req.Header.Set("X-Guiio-Meta-Stage", stage)
+		ctx := &fakeContext{params: objectParams(), req: req}
+		svc.PutObject(ctx)
+		if ctx.status != http.StatusCreated {
+			t.Fatalf("put: expected 201 got %d: %+v", ctx.status, ctx.resp)
+		}
+		return *ctx.resp.(*UploadObjectResponse)
+	}
+
+	v1 := put("first", "draft")
+	v2 := put("second", "final")
+	if v1.VersionID == "" || v2.VersionID == "" || v1.VersionID == v2.VersionID {
+		t.Fatalf("expected distinct version ids, got %q and %q", v1.VersionID, v2.VersionID)
+	}
+
+	ctx = &fakeContext{params: objectParams(), query: map[string]string{"versionId": v1.VersionID}, req: httptest.NewRequest(http.MethodGet, "/", nil)}
+	svc.DownloadObject(ctx)
+	if ctx.status != http.StatusOK || string(ctx.stream) != "first" || ctx.headers.Get("X-Guiio-Version-Id") != v1.VersionID {
+		t.Fatalf("unexpected version download %d %q %v", ctx.status, ctx.stream, ctx.headers)
+	}
+
+	ctx = &fakeContext{params: objectParams()}
+	svc.DeleteObject(ctx)
+	if ctx.status != http.StatusOK {
+		t.Fatalf("delete: expected 200 got %d", ctx.status)
+	}
+
+	ctx = &fakeContext{params: objectParams()}
+	svc.ListObjectVersions(ctx)
+	versions := ctx.resp.(ListObjectVersionsResponse).Versions
+	if len(versions) != 3 || !versions[0].IsDeleteMarker || !versions[0].IsLatest || versions[2].VersionID != v1.VersionID {
+		t.Fatalf("unexpected versions %+v", versions)
+	}
+
+	params := objectParams()
+	params["versionId"] = v1.VersionID
+	ctx = &fakeContext{params: params}
+	svc.RestoreObjectVersion(ctx)
+	if ctx.status != http.StatusOK {
+		t.Fatalf("restore: expected 200 got %d: %+v", ctx.status, ctx.resp)
+	}
+	restored := ctx.resp.(UploadObjectResponse)
+	if restored.VersionID == "" || restored.VersionID == v1.VersionID {
+		t.Fatalf("restore should create a new version, got %q", restored.VersionID)
+	}
+	if got, _ := storedObject(client, repo, "docs", "plan.md"); string(got) != "first" {
+		t.Fatalf("unexpected restored content %q", got)
+	}
+	obj := repo.objects["docs/plan.md"]
+	if obj == nil || len(obj.Edges.Metadata) != 1 || obj.Edges.Metadata[0].Value != "draft" {
+		t.Fatalf("metadata was not restored: %+v", obj)
+	}
+	if obj.ContentTypeSource == "" || obj.ContentTypeSource != v1.ContentTypeSource || restored.ContentTypeSource != v1.ContentTypeSource {
+		t.Fatalf("content type source was not restored: %q, want %q", obj.ContentTypeSource, v1.ContentTypeSource)
+	}
+
+	params["versionId"] = versions[0].VersionID
+	ctx = &fakeContext{params: params}
+	svc.RestoreObjectVersion(ctx)
+	if ctx.status != http.StatusBadRequest {
+		t.Fatalf("restoring a delete marker: expected 400 got %d", ctx.status)
+	}
+}
+
+func TestRestoreVersionInContentAddressableBucket(t *testing.T) {
+	client := &fakeStorageClient{existsMap: map[string]bool{"docs": true}}
+	repo := newFakeObjectRepository()
+	repo.buckets["docs"] = versioningEnabled
+	repo.cas["docs"] = true
+	svc := NewStorageServiceWithClient(client, "", repo)
+
+	put := func(body string) UploadObjectResponse {
+		req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
+		ctx := &fakeContext{params: map[string]string{"bucketName": "docs", "objectName": "plan.md"}, req: req}
+		svc.PutObject(ctx)
+		if ctx.status != http.StatusCreated {
+			t.Fatalf("put: expected 201 got %d: %+v", ctx.status, ctx.resp)
+		}
+		return *ctx.resp.(*UploadObjectResponse)
+	}
+	v1 := put("first")
+	put("second")
+
+	ctx := &fakeContext{params: map[string]string{"bucketName": "docs", "objectName": "plan.md", "versionId": v1.VersionID}}
+	svc.RestoreObjectVersion(ctx)
+	if ctx.status != http.StatusOK {
+		t.Fatalf("restore: expected 200 got %d: %+v", ctx.status, ctx.resp)
+	}
+	// 복원한 객체는 객체 키가 아니라 원래 본문의 blob을 다시 가리킵니다.
+	obj := repo.objects["docs/plan.md"]
+	if obj.StoragePath != v1.StoragePath || !isBlobKey(obj.StoragePath) {
+		t.Fatalf("expected restore to link blob %s, got %s", v1.StoragePath, obj.StoragePath)
+	}
+	if b := repo.blobs[v1.StoragePath]; b == nil || b.RefCount != 1 {
+		t.Fatalf("unexpected blob refs: %+v", b)
+	}
+	if _, ok := client.objects["docs/plan.md"]; ok || len(dataKeys(client, "docs")) != 0 {
+		t.Fatal("restore must not write the plain object key")
+	}
+}
+
+func TestConcurrentWritesSnapshotOwnVersion(t *testing.T) {
+	client := &fakeStorageClient{existsMap: map[string]bool{"docs": true}}
+	repo := newFakeObjectRepository()
+	svc := NewStorageServiceWithClient(client, "", repo)
+	svc.PutBucketVersioning(&fakeContext{params: map[string]string{"bucketName": "docs"}, body: []byte(`{"status":"Enabled"}`)})
+
+	// 버전 복사가 키 잠금 안에서 일어나지 않으면 다음 쓰기의 본문이 이전 버전에 복사될 수 있습니다.
+	const writers = 8
+	versions := make([]string, writers)
+	var wg sync.WaitGroup
+	for i := 0; i < writers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(fmt.Sprintf("body-%d", i)))
+			ctx := &fakeContext{params: map[string]string{"bucketName": "docs", "objectName": "a.txt"}, req: req}
+			svc.PutObject(ctx)
+			if ctx.status != http.StatusCreated {
+				t.Errorf("put %d: expected 201 got %d", i, ctx.status)
+				return
+			}
+			versions[i] = ctx.resp.(*UploadObjectResponse).VersionID
+		}(i)
+	}
+	wg.Wait()
+
+	for i, versionID := range versions {
+		v, err := repo.GetObjectVersion(context.Background(), "docs", "a.txt", versionID)
+		if err != nil {
+			t.Fatalf("version %s: %v", versionID, err)
+		}
+		got := string(client.objects["docs/"+v.StoragePath])
+		if want := fmt.Sprintf("body-%d", i); got != want {
+			t.Fatalf("version %s of writer %d holds %q, want %q", versionID, i, got, want)
+		}
+	}
+}
+
+func TestSuspendedVersioningKeepsSingleNullVersion(t *testing.T) {
+	client := &fakeStorageClient{existsMap: map[string]bool{"docs": true}}
+	repo := newFakeObjectRepository()
+	repo.buckets["docs"] = "Suspended"
+	svc := NewStorageServiceWithClient(client, "", repo)
+
+	for _, body := range []string{"a", "b"} {
+		req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
+		ctx := &fakeContext{params: map[string]string{"bucketName": "docs", "objectName": "x"}, req: req}
+		svc.PutObject(ctx)
+		if id := ctx.resp.(*UploadObjectResponse).VersionID; id != "null" {
+			t.Fatalf("expected null version got %q", id)
+		}
+	}
+	if len(repo.versions) != 1 || string(client.objects["docs/"+repo.versions[0].StoragePath]) != "b" {
+		t.Fatalf("expected a single null version holding the latest content, got %d versions", len(repo.versions))
+	}
+	// 대체된 null 버전의 복사본은 지워서 버전 복사본도 하나만 남습니다.
+	copies := 0
+	for key := range client.objects {
+		if strings.HasPrefix(key, "docs/"+versionKeyPrefix) {
+			copies++
+		}
+	}
+	if copies != 1 {
+		t.Fatalf("expected a single null version copy, got %d", copies)
+	}
+}
+
+func TestTrash(t *testing.T) {
+	client := &fakeStorageClient{existsMap: map[string]bool{"docs": true}}
+	repo := newFakeObjectRepository()
+	svc := NewStorageServiceWithClient(client, "", repo)
+
+	objectParams := func(name string) map[string]string {
+		return map[string]string{"bucketName": "docs", "objectName": name}
+	}
+	put := func(name, body string, header ...string) int {
+		req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
+		for i := 0; i+1 < len(header); i += 2 {
+			req.Header.Set(header[i], header[i+1])
+		}
+		ctx := &fakeContext{params: objectParams(name), req: req}
+		svc.PutObject(ctx)
+		return ctx.status
+	}
+	del := func(name string) {
+		ctx := &fakeContext{params: objectParams(name)}
+		svc.DeleteObject(ctx)
+		if ctx.status != http.StatusOK {
+			t.Fatalf("delete %s: expected 200 got %d", name, ctx.status)
+		}
+	}
+	listTrash := func() []TrashedObjectInfo {
+		ctx := &fakeContext{params: map[string]string{"bucketName": "docs"}}
+		svc.ListTrash(ctx)
+		if ctx.status != http.StatusOK {
+			t.Fatalf("list trash: expected 200 got %d: %+v", ctx.status, ctx.resp)
+		}
+		return ctx.resp.(ListTrashResponse).Objects
+	}
+
+	put("a.txt", "first")
+	del("a.txt")
+	if _, ok := storedObject(client, repo, "docs", "a.txt"); !ok {
+		t.Fatalf("trashed object should stay in the backend")
+	}
+	ctx := &fakeContext{params: objectParams("a.txt"), req: httptest.NewRequest(http.MethodGet, "/", nil)}
+	svc.DownloadObject(ctx)
+	if ctx.status != http.StatusNotFound {
+		t.Fatalf("download of trashed object: expected 404 got %d", ctx.status)
+	}
+	trashed := listTrash()
+	if len(trashed) != 1 || trashed[0].Key != "a.txt" || trashed[0].PurgeAt.Sub(trashed[0].DeletedAt) != 168*time.Hour {
+		t.Fatalf("unexpected trash %+v", trashed)
+	}
+
+	ctx = &fakeContext{params: objectParams("a.txt")}
+	svc.RestoreTrashedObject(ctx)
+	if ctx.status != http.StatusOK {
+		t.Fatalf("restore: expected 200 got %d: %+v", ctx.status, ctx.resp)
+	}
+	ctx = &fakeContext{params: objectParams("a.txt"), req: httptest.NewRequest(http.MethodGet, "/", nil)}
+	svc.DownloadObject(ctx)
+	if ctx.status != http.StatusOK || string(ctx.stream) != "first" {
+		t.Fatalf("download after restore: %d %q", ctx.status, ctx.stream)
+	}
+	ctx = &fakeContext{params: objectParams("a.txt")}
+	svc.RestoreTrashedObject(ctx)
+	if ctx.status != http.StatusNotFound {
+		t.Fatalf("restoring a live object: expected 404 got %d", ctx.status)
+	}
+
+	// 휴지통에 있는 키에 다시 쓰면 없는 키로 보고 새 객체를 만들며, 휴지통 항목과 그 본문은 그대로 남습니다.
+	del("a.txt")
+	firstKey := repo.trashed["docs/a.txt"].StoragePath
+	if status := put("a.txt", "second", "If-None-Match", "*"); status != http.StatusCreated {
+		t.Fatalf("create over trashed key: expected 201 got %d", status)
+	}
+	if got := listTrash(); len(got) != 1 || got[0].Key != "a.txt" {
+		t.Fatalf("trashed object should survive a re-upload, got %+v", got)
+	}
+	if got := string(client.objects["docs/"+firstKey]); got != "first" {
+		t.Fatalf("re-upload clobbered the trashed content: %q", got)
+	}
+	ctx = &fakeContext{params: objectParams("a.txt")}
+	svc.RestoreTrashedObject(ctx)
+	if ctx.status != http.StatusConflict {
+		t.Fatalf("restoring over a re-uploaded object: expected 409 got %d", ctx.status)
+	}
+
+	// 새 객체를 다시 지우면 휴지통 항목을 대체하고, 대체된 항목의 본문은 지웁니다.
+	del("a.txt")
+	if _, ok := client.objects["docs/"+firstKey]; ok {
+		t.Fatalf("replaced trash entry left its content behind")
+	}
+	ctx = &fakeContext{params: objectParams("a.txt")}
+	svc.RestoreTrashedObject(ctx)
+	if ctx.status != http.StatusOK {
+		t.Fatalf("restore: expected 200 got %d: %+v", ctx.status, ctx.resp)
+	}
+	if got := listTrash(); len(got) != 0 {
+		t.Fatalf("restored object should leave the trash, got %+v", got)
+	}
+
+	put("b.txt", "b")
+	put("c.txt", "c")
+	del("b.txt")
+	del("c.txt")
+	if n, err := svc.PurgeExpiredTrash(context.Background(), time.Now().Add(-time.Hour)); err != nil || n != 0 {
+		t.Fatalf("nothing should be expired yet, purged %d: %v", n, err)
+	}
+
+	purgedKey := repo.trashed["docs/b.txt"].StoragePath
+	ctx = &fakeContext{params: map[string]string{"bucketName": "docs"}}
+	svc.EmptyTrash(ctx)
+	if ctx.status != http.StatusOK || ctx.resp.(EmptyTrashResponse).Purged != 2 {
+		t.Fatalf("empty trash: %d %+v", ctx.status, ctx.resp)
+	}
+	if _, ok := client.objects["docs/"+purgedKey]; ok {
+		t.Fatalf("purged object left in the backend")
+	}
+	if _, ok := repo.trashed["docs/c.txt"]; ok {
+		t.Fatalf("purged object row left behind")
+	}
+	if got, _ := storedObject(client, repo, "docs", "a.txt"); string(got) != "second" {
+		t.Fatalf("live object was purged")
+	}
+
+	put("d.txt", "d")
+	del("d.txt")
+	if n, err := svc.PurgeExpiredTrash(context.Background(), time.Now().Add(time.Hour)); err != nil || n != 1 {
+		t.Fatalf("expected one expired object purged, got %d: %v", n, err)
+	}
+}
+
+func TestPurgeSkipsObjectRevivedUnderLock(t *testing.T) {
+	client := &fakeStorageClient{existsMap: map[string]bool{"docs": true}}
+	repo := newFakeObjectRepository()
+	svc := NewStorageServiceWithClient(client, "", repo)
+
+	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader("body"))
+	svc.PutObject(&fakeContext{params: map[string]string{"bucketName": "docs", "objectName": "a.txt"}, req: req})
+	svc.DeleteObject(&fakeContext{params: map[string]string{"bucketName": "docs", "objectName": "a.txt"}})
+	stale := *repo.trashed["docs/a.txt"]
+
+	// 목록을 읽은 purge가 키 잠금을 기다리는 동안 다른 요청이 객체를 되살립니다.
+	otx, _ := repo.BeginObjectTx(context.Background(), "docs", "a.txt")
+	done := make(chan bool)
+	go func() {
+		ok, err := svc.purgeTrashedObject(context.Background(), &stale)
+		if err != nil {
+			t.Errorf("purge: %v", err)
+		}
+		done <- ok
+	}()
+	if _, err := otx.Restore(context.Background()); err != nil {
+		t.Fatalf("restore: %v", err)
+	}
+	otx.Commit()
+
+	if <-done {
+		t.Fatalf("revived object should not be purged")
+	}
+	if _, ok := storedObject(client, repo, "docs", "a.txt"); !ok {
+		t.Fatalf("revived object's backend bytes were removed")
+	}
+	if obj, ok := repo.objects["docs/a.txt"]; !ok || obj.DeletedAt != nil {
+		t.Fatalf("revived object row should stay live")
+	}
+}
+
+func TestObjectTagging(t *testing.T) {
+	client := &fakeStorageClient{existsMap: map[string]bool{"docs": true}}
+	repo := newFakeObjectRepository()
+	svc := NewStorageServiceWithClient(client, "", repo)
+
+	req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader("report"))
+	req.Header.Set("X-Guiio-Meta-Owner", "kim")
+	ctx := &fakeContext{params: map[string]string{"bucketName": "docs", "objectName": "q3.pdf"}, req: req}
+	svc.PutObject(ctx)
+
+	params := func(name string) map[string]string {
+		return map[string]string{"bucketName": "docs", "objectName": name}
+	}
+	tooMany := map[string]string{}
+	for i := 0; i <= maxObjectTags; i++ {
+		tooMany[fmt.Sprintf("k%d", i)] = "v"
+	}
+	tooManyBody, _ := json.Marshal(ObjectTaggingRequest{Tags: tooMany})
+
+	cases := []struct {
+		name   string
+		object string
+		body   string
+		status int
+	}{
+		{"set", "q3.pdf", `{"tags":{"team":"finance","retention":"7y"}}`, http.StatusOK},
+		{"too many", "q3.pdf", string(tooManyBody), http.StatusBadRequest},
+		{"long key", "q3.pdf", `{"tags":{"` + strings.Repeat("k", maxTagKeyLength+1) + `":"v"}}`, http.StatusBadRequest},
+		{"long value", "q3.pdf", `{"tags":{"k":"` + strings.Repeat("v", maxTagValueLength+1) + `"}}`, http.StatusBadRequest},
+		{"invalid char", "q3.pdf", `{"tags":{"team":"a,b"}}`, http.StatusBadRequest},
+		{"missing object", "missing.pdf", `{"tags":{"team":"finance"}}`, http.StatusNotFound},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := &fakeContext{params: params(tc.object), body: []byte(tc.body)}
+			svc.PutObjectTagging(ctx)
+			if ctx.status != tc.status {
+				t.Fatalf("expected %d got %d: %+v", tc.status, ctx.status, ctx.resp)
+			}
+		})
+	}
+
+	ctx = &fakeContext{params: params("q3.pdf")}
+	svc.GetObjectTagging(ctx)
+	tags := ctx.resp.(ObjectTaggingResponse).Tags
+	if ctx.status != http.StatusOK || len(tags) != 2 || tags["team"] != "finance" {
+		t.Fatalf("unexpected tags %d %+v", ctx.status, ctx.resp)
+	}
+	head := repo.objects["docs/q3.pdf"]
+	if len(head.Edges.Metadata) != 1 || head.Edges.Metadata[0].Key != "owner" {
+		t.Fatalf("tagging should not touch metadata: %+v", head.Edges.Metadata)
+	}
+
+	ctx = &fakeContext{params: params("q3.pdf"), body: []byte(`{"destination_key":"archive/q3.pdf"}`)}
+	svc.MoveObject(ctx)
+	if ctx.status != http.StatusOK {
+		t.Fatalf("move: expected 200 got %d: %+v", ctx.status, ctx.resp)
+	}
+	ctx = &fakeContext{params: params("archive/q3.pdf")}
+	svc.GetObjectTagging(ctx)
+	if got := ctx.resp.(ObjectTaggingResponse).Tags; got["retention"] != "7y" {
+		t.Fatalf("tags were not moved: %+v", got)
+	}
+
+	ctx = &fakeContext{params: params("archive/q3.pdf")}
+	svc.DeleteObjectTagging(ctx)
+	if ctx.status != http.StatusOK || len(repo.tags["docs/archive/q3.pdf"]) != 0 {
+		t.Fatalf("tags were not deleted: %d %+v", ctx.status, repo.tags)
+	}
+}
+
+func TestParseTagFilter(t *testing.T) {
+	tags, err := parseTagFilter("env:prod:eu")
+	if err != nil || tags["env"] != "prod:eu" {
+		t.Fatalf("unexpected filter %+v: %v", tags, err)
+	}
+	if _, err := parseTagFilter("env"); err == nil {
+		t.Fatalf("expected error for filter without value separator")
+	}
+
+	svc := NewStorageServiceWithClient(&fakeStorageClient{existsMap: map[string]bool{"docs": true}}, "", nil)
+	ctx := &fakeContext{params: map[string]string{"bucketName": "docs"}, query: map[string]string{"tag": "env:prod"}}
+	svc.ListObjects(ctx)
+	if ctx.status != http.StatusNotImplemented {
+		t.Fatalf("tag filter without repository: expected 501 got %d", ctx.status)
+	}
+}
+
+func TestSearchObjects(t *testing.T) {
+	client := &fakeStorageClient{existsMap: map[string]bool{"docs": true}}
+	repo := newFakeObjectRepository()
+	svc := NewStorageServiceWithClient(client, "", repo)
+	for _, name := range []string{"a.pdf", "b.pdf", "c.pdf"} {
+		req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(name))
+		req.Header.Set("X-Guiio-Meta-Dept", "finance")
+		svc.PutObject(&fakeContext{params: map[string]string{"bucketName": "docs", "objectName": name}, req: req})
+	}
+
+	search := func(query string) *fakeContext {
+		ctx := &fakeContext{
+			params: map[string]string{"bucketName": "docs"},
+			req:    httptest.NewRequest(http.MethodGet, "/search?"+query, nil),
+		}
+		ctx.query = map[string]string{}
+		for k, v := range ctx.req.URL.Query() {
+			ctx.query[k] = v[0]
+		}
+		svc.SearchObjects(ctx)
+		return ctx
+	}
+
+	ctx := search("meta=Dept:finance&meta=year:2024&meta_prefix=owner:k&content_type=application/*&min_size=1&max_size=10&updated_after=2024-01-01T00:00:00Z&sort=size&order=desc&name=*.pdf")
+	if ctx.status != http.StatusOK {
+		t.Fatalf("expected 200 got %d: %+v", ctx.status, ctx.resp)
+	}
+	in := repo.search[len(repo.search)-1]
+	if in.BucketName != "docs" || in.Metadata["dept"] != "finance" || in.Metadata["year"] != "2024" || in.MetadataPrefix["owner"] != "k" {
+		t.Fatalf("unexpected metadata filters %+v", in)
+	}
+	if *in.MinSize != 1 || *in.MaxSize != 10 || in.UpdatedAfter == nil || in.CreatedAfter != nil {
+		t.Fatalf("unexpected range filters %+v", in)
+	}
+	if in.Sort != repository.SearchSortSize || !in.Desc || in.ContentType != "application/*" || in.NameGlob != "*.pdf" {
+		t.Fatalf("unexpected sort/type/name %+v", in)
+	}
+
+	ctx = search("meta=dept:finance&limit=2")
+	first := ctx.resp.(SearchObjectsResponse)
+	if len(first.Objects) != 2 || !first.IsTruncated || first.Objects[0].Metadata["dept"] != "finance" {
+		t.Fatalf("unexpected first page %+v", first)
+	}
+	ctx = search("meta=dept:finance&limit=2&cursor=" + first.NextCursor)
+	second := ctx.resp.(SearchObjectsResponse)
+	if len(second.Objects) != 1 || second.Objects[0].Key != "c.pdf" || second.IsTruncated {
+		t.Fatalf("unexpected second page %+v", second)
+	}
+
+	negative := encodeSearchCursor(&repository.ObjectSearchCursor{Offset: -5})
+	for _, query := range []string{"meta=dept", "min_size=-1", "created_after=yesterday", "sort=owner", "order=up", "cursor=bm90LWpzb24", "q=pdf&cursor=" + negative} {
+		if ctx := search(query); ctx.status != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400 got %d", query, ctx.status)
+		}
+	}
+}
+
+func TestSearchFullText(t *testing.T) {
+	client := &fakeStorageClient{existsMap: map[string]bool{"docs": true}}
+	repo := newFakeObjectRepository()
+	svc := NewStorageServiceWithClient(client, "", repo)
+	for name, title := range map[string]string{"reports/2024-March_Invoice.pdf": "Quarterly summary", "notes.txt": "invoice draft"} {
+		req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(name))
+		req.Header.Set("X-Guiio-Meta-Title", title)
+		svc.PutObject(&fakeContext{params: map[string]string{"bucketName": "docs", "objectName": name}, req: req})
+	}
+
+	ctx := &fakeContext{
+		params: map[string]string{"bucketName": "docs"},
+		query:  map[string]string{"q": "invoice march -draft"},
+		req:    httptest.NewRequest(http.MethodGet, "/search", nil),
+	}
+	svc.SearchObjects(ctx)
+	if ctx.status != http.StatusOK {
+		t.Fatalf("expected 200 got %d: %+v", ctx.status, ctx.resp)
+	}
+	if in := repo.search[len(repo.search)-1]; in.Query != "invoice march -draft" || in.Sort != repository.SearchSortRelevance {
+		t.Fatalf("unexpected search input %+v", in)
+	}
+	resp := ctx.resp.(SearchObjectsResponse)
+	if len(resp.Objects) != 2 {
+		t.Fatalf("unexpected results %+v", resp.Objects)
+	}
+	name := resp.Objects[1]
+	if name.Key != "reports/2024-March_Invoice.pdf" || name.Rank != 2 {
+		t.Fatalf("unexpected result %+v", name)
+	}
+	if h := name.Highlights["name"]; h != "reports/2024-<mark>March</mark>_<mark>Invoice</mark>.pdf" {
+		t.Fatalf("unexpected name highlight %q", h)
+	}
+	if _, ok := name.Highlights["metadata.title"]; ok {
+		t.Fatalf("unmatched metadata should not be highlighted: %+v", name.Highlights)
+	}
+	if h := resp.Objects[0].Highlights["metadata.title"]; h != "<mark>invoice</mark> draft" {
+		t.Fatalf("unexpected metadata highlight %q", h)
+	}
+
+	ctx = &fakeContext{
+		params: map[string]string{"bucketName": "docs"},
+		query:  map[string]string{"sort": "relevance"},
+		req:    httptest.NewRequest(http.MethodGet, "/search", nil),
+	}
+	svc.SearchObjects(ctx)
+	if ctx.status != http.StatusBadRequest {
+		t.Fatalf("expected 400 for relevance without q, got %d", ctx.status)
+	}
+}
+
+func TestHighlightTextEscapesHTML(t *testing.T) {
+	terms := highlightTerms("invoice img")
+	cases := []struct {
+		text, want string
+		matched    bool
+	}{
+		{"<img src=x onerror=alert(1)>", "&lt;<mark>img</mark> src=x onerror=alert(1)&gt;", true},
+		{"Tom & Jerry <invoice>", "Tom &amp; Jerry &lt;<mark>invoice</mark>&gt;", true},
+		{"a < b & \"c\"", "a &lt; b &amp; &#34;c&#34;", false},
+	}
+	for _, tc := range cases {
+		got, matched := highlightText(tc.text, terms)
+		if got != tc.want || matched != tc.matched {
+			t.Fatalf("%q: expected %q (%v) got %q (%v)", tc.text, tc.want, tc.matched, got, matched)
+		}
+	}
+}
+
+func TestDownloadArchive(t *testing.T) {
+	client := &fakeStorageClient{existsMap: map[string]bool{"media": true}}
+	repo := newFakeObjectRepository()
+	svc := NewStorageServiceWithClient(client, "", repo)
+	for _, name := range []string{"photos/2024/a.txt", "photos/2024/sub/b.txt", "photos/other.txt", "docs/x.txt", "docs/y.txt"} {
+		req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader("data:"+name))
+		svc.PutObject(&fakeContext{params: map[string]string{"bucketName": "media", "objectName": name}, req: req})
+	}
+	download := func(query map[string]string) *fakeContext {
+		ctx := &fakeContext{params: map[string]string{"bucketName": "media"}, query: query}
+		svc.DownloadArchive(ctx)
+		return ctx
+	}
+
+	ctx := download(map[string]string{"prefix": "photos/2024/"})
+	if ctx.status != http.StatusOK {
+		t.Fatalf("expected 200 got %d: %+v", ctx.status, ctx.resp)
+	}
+	if cd := ctx.headers["Content-Disposition"]; len(cd) != 1 || cd[0] != `attachment; filename=2024.zip` {
+		t.Fatalf("unexpected Content-Disposition %q", cd)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(ctx.stream), int64(len(ctx.stream)))
+	if err != nil {
+		t.Fatalf("invalid zip: %v", err)
+	}
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		rc, _ := f.Open()
+		data, _ := io.ReadAll(rc)
+		rc.Close()
+		if string(data) != "data:photos/2024/"+f.Name {
+			t.Fatalf("unexpected content for %s: %q", f.Name, data)
+		}
+	}
+	if strings.Join(names, ",") != "a.txt,sub/b.txt" {
+		t.Fatalf("unexpected zip entries %v", names)
+	}
+
+	ctx = download(map[string]string{"prefix": "photos/20", "format": "tar.gz"})
+	if ctx.status != http.StatusOK {
+		t.Fatalf("expected 200 got %d: %+v", ctx.status, ctx.resp)
+	}
+	gz, err := gzip.NewReader(bytes.NewReader(ctx.stream))
+	if err != nil {
+		t.Fatalf("invalid gzip: %v", err)
+	}
+	tr := tar.NewReader(gz)
+	names = nil
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("invalid tar: %v", err)
+		}
+		names = append(names, hdr.Name)
+	}
+	if strings.Join(names, ",") != "2024/a.txt,2024/sub/b.txt" {
+		t.Fatalf("unexpected tar entries %v", names)
+	}
+
+	if ctx := download(nil); ctx.status != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413 for too many objects, got %d", ctx.status)
+	}
+	if ctx := download(map[string]string{"prefix": "missing/"}); ctx.status != http.StatusNotFound {
+		t.Fatalf("expected 404 for empty prefix, got %d", ctx.status)
+	}
+	if ctx := download(map[string]string{"format": "rar"}); ctx.status != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown format, got %d", ctx.status)
+	}
+}
+
+func TestArchiveEntryName(t *testing.T) {
+	cases := map[[2]string]string{
+		{"photos/", "photos/a.jpg"}:        "a.jpg",
+		{"photos/20", "photos/2024/a.jpg"}: "2024/a.jpg",
+		{"", "a/../../b.txt"}:              "a/b.txt",
+		{"photos/", "photos/dir/"}:         "",
+	}
+	for in, want := range cases {
+		if got := archiveEntryName(in[0], in[1]); got != want {
+			t.Fatalf("archiveEntryName(%q, %q) = %q, want %q", in[0], in[1], got, want)
+		}
+	}
+}
+
+func TestUploadObjectExtract(t *testing.T) {
+	client := &fakeStorageClient{existsMap: map[string]bool{"builds": true}}
+	repo := newFakeObjectRepository()
+	svc := NewStorageServiceWithClient(client, "", repo)
+
+	upload := func(archive []byte, prefix string) *fakeContext {
+		var body bytes.Buffer
+		mw := multipart.NewWriter(&body)
+		mw.WriteField("prefix", prefix)
+		mw.WriteField("meta-build", "42")
+		fw, _ := mw.CreateFormFile("file", "build.bin")
+		fw.Write(archive)
+		mw.Close()
+		req := httptest.NewRequest(http.MethodPost, "/", &body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		ctx := &fakeContext{params: map[string]string{"bucketName": "builds"}, query: map[string]string{"extract": "true"}, req: req}
+		svc.UploadObject(ctx)
+		return ctx
+	}
+
+	var zbuf bytes.Buffer
+	zw := zip.NewWriter(&zbuf)
+	for _, name := range []string{"bin/app", "docs/", "docs/readme.txt", "../evil.sh"} {
+		fw, _ := zw.Create(name)
+		if !strings.HasSuffix(name, "/") {
+			fw.Write([]byte("content:" + name))
+		}
+	}
+	zw.Close()
+
+	ctx := upload(zbuf.Bytes(), "ci/42")
+	if ctx.status != http.StatusCreated {
+		t.Fatalf("expected 201 got %d: %+v", ctx.status, ctx.resp)
+	}
+	resp := ctx.resp.(ExtractArchiveResponse)
+	if resp.Format != "zip" || resp.Prefix != "ci/42/" || resp.Extracted != 2 || resp.Failed != 1 || len(resp.Results) != 3 {
+		t.Fatalf("unexpected extract response %+v", resp)
+	}
+	if r := resp.Results[2]; r.Entry != "../evil.sh" || r.Extracted || r.Error != errUnsafeArchivePath.Error() {
+		t.Fatalf("expected zip-slip entry to be rejected, got %+v", r)
+	}
+	obj := repo.objects["builds/ci/42/docs/readme.txt"]
+	if obj == nil || obj.ContentType != "text/plain; charset=utf-8" {
+		t.Fatalf("expected extracted object record, got %+v", obj)
+	}
+	if got, _ := storedObject(client, repo, "builds", "ci/42/docs/readme.txt"); string(got) != "content:docs/readme.txt" {
+		t.Fatalf("unexpected extracted content %q", got)
+	}
+
+	var tbuf bytes.Buffer
+	gz := gzip.NewWriter(&tbuf)
+	tw := tar.NewWriter(gz)
+	tw.WriteHeader(&tar.Header{Name: "out/", Typeflag: tar.TypeDir, Mode: 0o755})
+	tw.WriteHeader(&tar.Header{Name: "out/a.txt", Typeflag: tar.TypeReg, Mode: 0o644, Size: 1})
+	tw.Write([]byte("a"))
+	tw.WriteHeader(&tar.Header{Name: "out/link", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"})
+	tw.Close()
+	gz.Close()
+
+	ctx = upload(tbuf.Bytes(), "")
+	resp = ctx.resp.(ExtractArchiveResponse)
+	if ctx.status != http.StatusCreated || resp.Format != "tar.gz" || resp.Extracted != 1 || resp.Failed != 1 {
+		t.Fatalf("unexpected tar.gz extract %d %+v", ctx.status, resp)
+	}
+	if _, ok := repo.objects["builds/out/a.txt"]; !ok {
+		t.Fatalf("expected out/a.txt to be extracted")
+	}
+
+	zbuf.Reset()
+	zw = zip.NewWriter(&zbuf)
+	for i := 0; i < 5; i++ {
+		fw, _ := zw.Create(fmt.Sprintf("f%d", i))
+		fw.Write([]byte("x"))
+	}
+	zw.Close()
+	if ctx := upload(zbuf.Bytes(), "many"); ctx.status != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected 413 for too many entries, got %d", ctx.status)
+	}
+	if _, ok := repo.objects["builds/many/f0"]; ok {
+		t.Fatalf("nothing should be written when limits are exceeded")
+	}
+
+	if ctx := upload([]byte("plain text"), ""); ctx.status != http.StatusBadRequest {
+		t.Fatalf("expected 400 for non-archive upload, got %d", ctx.status)
+	}
+}
+
+func TestSafeArchivePath(t *testing.T) {
+	for name, want := range map[string]string{
+		"a/b.txt":      "a/b.txt",
+		"./a//b.txt":   "a/b.txt",
+		`dir\file.txt`: "dir/file.txt",
+		"../x":         "",
+		"a/../../x":    "",
+		"/etc/passwd":  "",
+		"C:/windows":   "",
+	} {
+		got, err := safeArchivePath(name)
+		if (want == "") != (err != nil) || got != want {
+			t.Fatalf("safeArchivePath(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+}
+
+func TestObjectChecksums(t *testing.T) {
+	client := &fakeStorageClient{existsMap: map[string]bool{"docs": true}}
+	repo := newFakeObjectRepository()
+	svc := NewStorageServiceWithClient(client, "", repo)
+	body := "checksummed body"
+	sha := sha256.Sum256([]byte(body))
+	wantSHA := base64.StdEncoding.EncodeToString(sha[:])
+	crc := make([]byte, 4)
+	binary.BigEndian.PutUint32(crc, crc32.Checksum([]byte(body), crc32.MakeTable(crc32.Castagnoli)))
+	wantCRC := base64.StdEncoding.EncodeToString(crc)
+
+	put := func(name string, headers map[string]string) *fakeContext {
+		req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		ctx := &fakeContext{params: map[string]string{"bucketName": "docs", "objectName": name}, req: req}
+		svc.PutObject(ctx)
+		return ctx
+	}
+
+	md5sum := md5.Sum([]byte(body))
+	ctx := put("ok.txt", map[string]string{
+		"Content-MD5":             base64.StdEncoding.EncodeToString(md5sum[:]),
+		"X-Guiio-Checksum-Sha256": wantSHA,
+	})
+	if ctx.status != http.StatusCreated {
+		t.Fatalf("expected 201 got %d: %+v", ctx.status, ctx.resp)
+	}
+	resp := ctx.resp.(*UploadObjectResponse)
+	if resp.ChecksumSHA256 != wantSHA || resp.ChecksumCRC32C != wantCRC {
+		t.Fatalf("unexpected checksums %+v", resp)
+	}
+	if obj := repo.objects["docs/ok.txt"]; obj.ChecksumSha256 != wantSHA || obj.ChecksumCrc32c != wantCRC {
+		t.Fatalf("checksums not stored on object row: %+v", obj)
+	}
+
+	dl := &fakeContext{params: map[string]string{"bucketName": "docs", "objectName": "ok.txt"}, req: httptest.NewRequest(http.MethodGet, "/", nil)}
+	svc.DownloadObject(dl)
+	if got := dl.headers["X-Guiio-Checksum-Sha256"]; len(got) != 1 || got[0] != wantSHA {
+		t.Fatalf("unexpected sha256 header %v", got)
+	}
+	if got := dl.headers["X-Guiio-Checksum-Crc32c"]; len(got) != 1 || got[0] != wantCRC {
+		t.Fatalf("unexpected crc32c header %v", got)
+	}
+
+	wrong := md5.Sum([]byte("other"))
+	for name, headers := range map[string]map[string]string{
+		"bad-md5":    {"Content-MD5": base64.StdEncoding.EncodeToString(wrong[:])},
+		"bad-crc32c": {"X-Guiio-Checksum-Crc32c": "AAAAAA=="},
+	} {
+		before := len(dataKeys(client, "docs"))
+		ctx := put(name, headers)
+		if ctx.status != http.StatusBadRequest {
+			t.Fatalf("%s: expected 400 got %d", name, ctx.status)
+		}
+		if _, ok := client.objects["docs/"+name]; ok || len(dataKeys(client, "docs")) != before {
+			t.Fatalf("%s: mismatched body must not reach storage", name)
+		}
+		if _, ok := repo.objects["docs/"+name]; ok {
+			t.Fatalf("%s: mismatched body must not be recorded", name)
+		}
+	}
+	if ctx := put("bad-header", map[string]string{"X-Guiio-Checksum-Sha256": "not base64"}); ctx.status != http.StatusBadRequest {
+		t.Fatalf("expected 400 for malformed checksum header, got %d", ctx.status)
+	}
+}
+
+func TestUploadContentTypePolicy(t *testing.T) {
+	client := &fakeStorageClient{existsMap: map[string]bool{"media": true}}
+	repo := newFakeObjectRepository()
+	svc := NewStorageServiceWithClient(client, "", repo)
+	png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 32)...)
+
+	upload := func(name, contentType string) *UploadObjectResponse {
+		t.Helper()
+		body := &bytes.Buffer{}
+		mw := multipart.NewWriter(body)
+		h := textproto.MIMEHeader{}
+		h.Set("Content-Disposition", fmt.Sprintf(`form-data; name="file"; filename=%q`, name))
+		if contentType != "" {
+			h.Set("Content-Type", contentType)
+		}
+		part, _ := mw.CreatePart(h)
+		part.Write(png)
+		mw.Close()
+		req := httptest.NewRequest(http.MethodPost, "/", body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		ctx := &fakeContext{params: map[string]string{"bucketName": "media"}, req: req}
+		svc.UploadObject(ctx)
+		if ctx.status != http.StatusCreated {
+			t.Fatalf("%s: expected 201 got %d: %+v", name, ctx.status, ctx.resp)
+		}
+		return ctx.resp.(*UploadObjectResponse)
+	}
+	setPolicy := func(policy string) int {
+		ctx := &fakeContext{params: map[string]string{"bucketName": "media"}, body: []byte(`{"policy":"` + policy + `"}`)}
+		svc.PutBucketContentTypePolicy(ctx)
+		return ctx.status
+	}
+	check := func(resp *UploadObjectResponse, wantType, wantSource string) {
+		t.Helper()
+		if resp.ContentType != wantType || resp.ContentTypeSource != wantSource {
+			t.Fatalf("%s: expected %s (%s) got %s (%s)", resp.Object, wantType, wantSource, resp.ContentType, resp.ContentTypeSource)
+		}
+		if obj := repo.objects["media/"+resp.Object]; obj.ContentType != wantType || obj.ContentTypeSource != wantSource {
+			t.Fatalf("%s: unexpected stored type %s (%s)", resp.Object, obj.ContentType, obj.ContentTypeSource)
+		}
+		if got, _ := storedObject(client, repo, "media", resp.Object); !bytes.Equal(got, png) {
+			t.Fatalf("%s: sniffing must not consume the body, got %d bytes", resp.Object, len(got))
+		}
+	}
+
+	get := &fakeContext{params: map[string]string{"bucketName": "media"}}
+	svc.GetBucketContentTypePolicy(get)
+	if resp := get.resp.(BucketContentTypePolicyResponse); resp.Policy != contentTypePolicySniffIfMissing {
+		t.Fatalf("expected default policy, got %+v", resp)
+	}
+
+	check(upload("missing", ""), "image/png", contentTypeSourceDetected)
+	check(upload("opaque", "application/octet-stream"), "image/png", contentTypeSourceDetected)
+	check(upload("declared", "image/x-custom"), "image/x-custom", contentTypeSourceDeclared)
+
+	if status := setPolicy("trust-client"); status != http.StatusOK {
+		t.Fatalf("expected 200 got %d", status)
+	}
+	check(upload("trusted", "application/octet-stream"), "application/octet-stream", contentTypeSourceDefault)
+	check(upload("trusted.pdf", ""), "application/pdf", contentTypeSourceDetected)
+
+	if status := setPolicy("always-sniff"); status != http.StatusOK {
+		t.Fatalf("expected 200 got %d", status)
+	}
+	check(upload("always", "text/html"), "image/png", contentTypeSourceDetected)
+
+	if status := setPolicy("sometimes"); status != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown policy, got %d", status)
+	}
+}
+
+func TestDetectContentType(t *testing.T) {
+	cases := []struct {
+		name string
+		head string
+		want string
+	}{
+		{"a.png", "\x89PNG\r\n\x1a\n", "image/png"},
+		{"doc.pdf", "%PDF-1.7", "application/pdf"},
+		{"data.json", `{"a":1}`, "application/json"},
+		{"notes", "plain words", "text/plain; charset=utf-8"},
+		{"empty.pdf", "", "application/pdf"},
+		{"blob", "\x00\x01\x02\x03", ""},
+	}
+	for _, tc := range cases {
+		if got := detectContentType(tc.name, []byte(tc.head)); got != tc.want {
+			t.Fatalf("%s: expected %q got %q", tc.name, tc.want, got)
+		}
+	}
+}
+
+func TestDownloadResponseOverrides(t *testing.T) {
+	client := &fakeStorageClient{existsMap: map[string]bool{"docs": true}}
+	svc := NewStorageServiceWithClient(client, "", newFakeObjectRepository())
+	put := &fakeContext{
+		params: map[string]string{"bucketName": "docs", "objectName": "reports/보고서 2024.pdf"},
+		req:    httptest.NewRequest(http.MethodPut, "/", strings.NewReader("%PDF-1.7 body")),
+	}
+	svc.PutObject(put)
+	if put.status != http.StatusCreated {
+		t.Fatalf("expected 201 got %d: %+v", put.status, put.resp)
+	}
+
+	get := func(query map[string]string) *fakeContext {
+		ctx := &fakeContext{
+			params: map[string]string{"bucketName": "docs", "objectName": "reports/보고서 2024.pdf"},
+			query:  query,
+			req:    httptest.NewRequest(http.MethodGet, "/", nil),
+		}
+		svc.DownloadObject(ctx)
+		return ctx
+	}
+	sign := func(query map[string]string, expires time.Time) map[string]string {
+		signed := map[string]string{"expires": strconv.FormatInt(expires.Unix(), 10)}
+		for k, v := range query {
+			signed[k] = v
+		}
+		signed["signature"] = signResponseOverrides("override-secret", "docs", "reports/보고서 2024.pdf", func(name string) string { return signed[name] }, expires.Unix())
+		return signed
+	}
+	download := func(query map[string]string) *fakeContext {
+		return get(sign(query, time.Now().Add(time.Hour)))
+	}
+
+	ctx := download(map[string]string{
+		"response-content-type":        "application/force-download",
+		"response-content-disposition": "attachment",
+		"response-cache-control":       "no-store",
+		"response-expires":             "Wed, 21 Oct 2026 07:28:00 GMT",
+	})
+	if ctx.status != http.StatusOK || string(ctx.stream) != "%PDF-1.7 body" {
+		t.Fatalf("unexpected download %d %q", ctx.status, ctx.stream)
+	}
+	want := map[string]string{
+		"Content-Type":        "application/force-download",
+		"Content-Disposition": `attachment; filename="___ 2024.pdf"; filename*=UTF-8''%EB%B3%B4%EA%B3%A0%EC%84%9C%202024.pdf`,
+		"Cache-Control":       "no-store",
+		"Expires":             "Wed, 21 Oct 2026 07:28:00 GMT",
+	}
+	for k, v := range want {
+		if got := ctx.headers.Get(k); got != v {
+			t.Fatalf("%s: expected %q got %q", k, v, got)
+		}
+	}
+
+	ctx = download(map[string]string{"response-content-disposition": "inline; filename=요약.pdf"})
+	if got := ctx.headers.Get("Content-Disposition"); got != `inline; filename="__.pdf"; filename*=UTF-8''%EC%9A%94%EC%95%BD.pdf` {
+		t.Fatalf("unexpected Content-Disposition %q", got)
+	}
+	if got := ctx.headers.Get("Content-Type"); got != "application/pdf" {
+		t.Fatalf("stored content type must be kept without override, got %q", got)
+	}
+
+	for _, query := range []map[string]string{
+		{"response-content-disposition": "evil; filename=a"},
+		{"response-content-disposition": "attachment; filename=\"a\r\nSet-Cookie: x\""},
+		{"response-expires": "tomorrow"},
+		{"response-content-type": "not a type"},
+	} {
+		if ctx := download(query); ctx.status != http.StatusBadRequest {
+			t.Fatalf("%v: expected 400 got %d", query, ctx.status)
+		}
+	}
+
+	// 서명이 없거나, 서명 뒤에 값을 바꾸거나, 만료된 링크는 거부해야 합니다.
+	html := map[string]string{"response-content-type": "text/html"}
+	tampered := sign(map[string]string{"response-content-type": "text/plain"}, time.Now().Add(time.Hour))
+	tampered["response-content-type"] = "text/html"
+	for name, query := range map[string]map[string]string{
+		"unsigned": html,
+		"tampered": tampered,
+		"expired":  sign(html, time.Now().Add(-time.Minute)),
+	} {
+		if ctx := get(query); ctx.status != http.StatusForbidden {
+			t.Fatalf("%s: expected 403 got %d", name, ctx.status)
+		}
+	}
+
+	// :sign으로 받은 경로를 그대로 쓰면 덮어쓴 헤더로 내려받아야 합니다.
+	body, _ := json.Marshal(SignDownloadRequest{ResponseContentDisposition: "attachment", ExpiresIn: 60})
+	signCtx := &fakeContext{params: map[string]string{"bucketName": "docs", "objectName": "reports/보고서 2024.pdf"}, body: body}
+	svc.SignDownload(signCtx)
+	if signCtx.status != http.StatusOK {
+		t.Fatalf("expected 200 got %d: %+v", signCtx.status, signCtx.resp)
+	}
+	signed, err := url.Parse(signCtx.resp.(SignDownloadResponse).URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	query := map[string]string{}
+	for k := range signed.Query() {
+		query[k] = signed.Query().Get(k)
+	}
+	if ctx := get(query); ctx.status != http.StatusOK || !strings.HasPrefix(ctx.headers.Get("Content-Disposition"), "attachment;") {
+		t.Fatalf("signed link must apply overrides, got %d %v", ctx.status, ctx.headers)
+	}
+	for _, req := range []SignDownloadRequest{
+		{ResponseContentType: "not a type"},
+		{ResponseCacheControl: "no-store", ExpiresIn: 8 * 24 * 3600},
+	} {
+		body, _ := json.Marshal(req)
+		ctx := &fakeContext{params: map[string]string{"bucketName": "docs", "objectName": "reports/보고서 2024.pdf"}, body: body}
+		if svc.SignDownload(ctx); ctx.status != http.StatusBadRequest {
+			t.Fatalf("%+v: expected 400 got %d", req, ctx.status)
+		}
+	}
+}
+
+func TestFormatContentDisposition(t *testing.T) {
+	cases := []struct {
+		dispType, filename, want string
+	}{
+		{"attachment", "", "attachment"},
+		{"attachment", "a.txt", "attachment; filename=a.txt"},
+		{"inline", "my file.txt", `inline; filename="my file.txt"`},
+		{"attachment", "한글.txt", `attachment; filename="__.txt"; filename*=UTF-8''%ED%95%9C%EA%B8%80.txt`},
+	}
+	for _, tc := range cases {
+		if got := formatContentDisposition(tc.dispType, tc.filename); got != tc.want {
+			t.Fatalf("%q: expected %q got %q", tc.filename, tc.want, got)
+		}
+	}
+}
+
+func TestDownloadCompression(t *testing.T) {
+	client := &fakeStorageClient{existsMap: map[string]bool{"logs": true}}
+	svc := NewStorageServiceWithClient(client, "", newFakeObjectRepository())
+	text := strings.Repeat("2026-10-17 INFO request served\n", 20)
+	png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 256)...)
+	for name, body := range map[string][]byte{"app.log": []byte(text), "small.log": []byte("tiny\n"), "pic.png": png} {
+		ctx := &fakeContext{
+			params: map[string]string{"bucketName": "logs", "objectName": name},
+			req:    httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(body)),
+		}
+		ctx.req.Header.Set("Content-Type", "text/plain")
+		if name == "pic.png" {
+			ctx.req.Header.Set("Content-Type", "image/png")
+		}
+		svc.PutObject(ctx)
+		if ctx.status != http.StatusCreated {
+			t.Fatalf("%s: expected 201 got %d", name, ctx.status)
+		}
+	}
+
+	download := func(name string, headers map[string]string) *fakeContext {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		ctx := &fakeContext{params: map[string]string{"bucketName": "logs", "objectName": name}, req: req}
+		svc.DownloadObject(ctx)
+		return ctx
+	}
+
+	ctx := download("app.log", map[string]string{"Accept-Encoding": "gzip, deflate"})
+	if ctx.status != http.StatusOK || ctx.headers.Get("Content-Encoding") != "gzip" {
+		t.Fatalf("expected gzip response, got %d %v", ctx.status, ctx.headers)
+	}
+	if ctx.headers.Get("Content-Length") != "" || ctx.headers.Get("Vary") != "Accept-Encoding" || ctx.headers.Get("ETag") != `W/"etag"` {
+		t.Fatalf("unexpected compressed headers %v", ctx.headers)
+	}
+	zr, err := gzip.NewReader(bytes.NewReader(ctx.stream))
+	if err != nil {
+		t.Fatalf("invalid gzip body: %v", err)
+	}
+	if got, _ := io.ReadAll(zr); string(got) != text {
+		t.Fatalf("decompressed body mismatch: %q", got)
+	}
+
+	if ctx := download("app.log", map[string]string{"Accept-Encoding": "gzip;q=0.2, br;q=0.8, zstd;q=0.5"}); ctx.headers.Get("Content-Encoding") != "br" {
+		t.Fatalf("expected br by q-value, got %v", ctx.headers)
+	}
+	if ctx := download("app.log", map[string]string{"Accept-Encoding": "*"}); ctx.headers.Get("Content-Encoding") != "zstd" {
+		t.Fatalf("expected server preference for wildcard, got %v", ctx.headers)
+	}
+	if ctx := download("app.log", map[string]string{"If-None-Match": `W/"etag"`, "Accept-Encoding": "gzip"}); ctx.status != http.StatusNotModified {
+		t.Fatalf("expected 304 for weak etag, got %d", ctx.status)
+	}
+
+	for _, tc := range []struct {
+		name    string
+		headers map[string]string
+		vary    bool
+	}{
+		{"app.log", map[string]string{"Accept-Encoding": "gzip;q=0, identity"}, true},
+		{"app.log", map[string]string{"Accept-Encoding": "gzip", "Range": "bytes=0-9"}, true},
+		{"small.log", map[string]string{"Accept-Encoding": "gzip"}, false},
+		{"pic.png", map[string]string{"Accept-Encoding": "gzip"}, false},
+	} {
+		ctx := download(tc.name, tc.headers)
+		if ctx.headers.Get("Content-Encoding") != "" || strings.HasPrefix(ctx.headers.Get("ETag"), "W/") {
+			t.Fatalf("%s %v: must not be compressed, got %v", tc.name, tc.headers, ctx.headers)
+		}
+		if got := ctx.headers.Get("Vary") != ""; got != tc.vary {
+			t.Fatalf("%s %v: unexpected Vary %v", tc.name, tc.headers, ctx.headers)
+		}
+	}
+}
+
+func TestImageResize(t *testing.T) {
+	client := &fakeStorageClient{existsMap: map[string]bool{"gallery": true}}
+	repo := newFakeObjectRepository()
+	svc := NewStorageServiceWithClient(client, "", repo)
+	src := image.NewRGBA(image.Rect(0, 0, 300, 200))
+	var encoded bytes.Buffer
+	if err := png.Encode(&encoded, src); err != nil {
+		t.Fatal(err)
+	}
+	put := &fakeContext{
+		params: map[string]string{"bucketName": "gallery", "objectName": "photo.png"},
+		req:    httptest.NewRequest(http.MethodPut, "/", bytes.NewReader(encoded.Bytes())),
+	}
+	svc.PutObject(put)
+	if put.status != http.StatusCreated {
+		t.Fatalf("expected 201 got %d", put.status)
+	}
+
+	download := func(query map[string]string, headers map[string]string) *fakeContext {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		ctx := &fakeContext{params: map[string]string{"bucketName": "gallery", "objectName": "photo.png"}, query: query, req: req}
+		svc.DownloadObject(ctx)
+		return ctx
+	}
+	decode := func(ctx *fakeContext) (image.Config, string) {
+		t.Helper()
+		cfg, format, err := image.DecodeConfig(bytes.NewReader(ctx.stream))
+		if err != nil {
+			t.Fatalf("invalid image body: %v", err)
+		}
+		return cfg, format
+	}
+
+	ctx := download(map[string]string{"w": "128"}, nil)
+	if ctx.status != http.StatusOK || ctx.headers.Get("X-Guiio-Derivative-Cache") != "miss" {
+		t.Fatalf("expected rendered derivative, got %d %v", ctx.status, ctx.headers)
+	}
+	if cfg, format := decode(ctx); format != "png" || cfg.Width != 128 || cfg.Height != 85 {
+		t.Fatalf("unexpected derivative %s %dx%d", format, cfg.Width, cfg.Height)
+	}
+	etag := ctx.headers.Get("ETag")
+
+	ctx = download(map[string]string{"w": "128"}, nil)
+	if ctx.headers.Get("X-Guiio-Derivative-Cache") != "hit" || ctx.headers.Get("ETag") != etag {
+		t.Fatalf("expected cached derivative, got %v", ctx.headers)
+	}
+	if ctx := download(map[string]string{"w": "128"}, map[string]string{"If-None-Match": etag}); ctx.status != http.StatusNotModified {
+		t.Fatalf("expected 304 got %d", ctx.status)
+	}
+
+	ctx = download(map[string]string{"w": "64", "h": "64", "fit": "cover", "format": "jpeg"}, nil)
+	if cfg, format := decode(ctx); format != "jpeg" || cfg.Width != 64 || cfg.Height != 64 {
+		t.Fatalf("unexpected cover derivative %s %dx%d", format, cfg.Width, cfg.Height)
+	}
+
+	for _, query := range []map[string]string{
+		{"w": "100"},
+		{"w": "128", "fit": "stretch"},
+		{"format": "bmp"},
+		{"format": "webp"},
+		{"fit": "cover"},
+	} {
+		if ctx := download(query, nil); ctx.status != http.StatusBadRequest {
+			t.Fatalf("%v: expected 400 got %d", query, ctx.status)
+		}
+	}
+
+	// repository 없이 백엔드를 직접 나열해도 숨김 객체는 보이지 않아야 합니다.
+	// repository 없는 배포처럼 객체 이름 키에 본문을 두고, repository가 올린 본문 키는 숨김 객체로 취급합니다.
+	client.objects["gallery/photo.png"], _ = storedObject(client, repo, "gallery", "photo.png")
+	list := &fakeContext{params: map[string]string{"bucketName": "gallery"}, req: httptest.NewRequest(http.MethodGet, "/", nil)}
+	NewStorageServiceWithClient(client, "", nil).ListObjects(list)
+	if resp := list.resp.(ListObjectsResponse); len(resp.Objects) != 1 {
+		t.Fatalf("derivatives must stay hidden, got %+v", resp.Objects)
+	}
+
+	del := &fakeContext{params: map[string]string{"bucketName": "gallery", "objectName": "photo.png"}, req: httptest.NewRequest(http.MethodDelete, "/", nil)}
+	NewStorageServiceWithClient(client, "", nil).DeleteObject(del)
+	if del.status != http.StatusOK {
+		t.Fatalf("expected 200 got %d: %+v", del.status, del.resp)
+	}
+	for key := range client.objects {
+		if strings.HasPrefix(key, "gallery/"+derivativeKeyPrefix) {
+			t.Fatalf("derivative %s must be removed with the original", key)
+		}
+	}
+}
+
+func TestOverwritePrunesStaleDerivatives(t *testing.T) {
+	client := &fakeStorageClient{existsMap: map[string]bool{"gallery": true}}
+	repo := newFakeObjectRepository()
+	svc := NewStorageServiceWithClient(client, "", repo)
+	put := func() {
+		t.Helper()
+		ctx := &fakeContext{
+			params: map[string]string{"bucketName": "gallery", "objectName": "photo.png"},
+			req:    httptest.NewRequest(http.MethodPut, "/", strings.NewReader("image")),
+		}
+		svc.PutObject(ctx)
+		if ctx.status != http.StatusCreated {
+			t.Fatalf("expected 201 got %d: %+v", ctx.status, ctx.resp)
+		}
+	}
+	put()
+
+	// 이전 본문의 ETag로 만든 파생 이미지가 남아 있는 상태를 만듭니다.
+	repo.objects["gallery/photo.png"].Etag = "stale"
+	spec := imageResizeSpec{Width: 64, Fit: imageFitContain, Format: imageFormatPNG}
+	stale := "gallery/" + derivativeKey("photo.png", "stale", spec)
+	current := "gallery/" + derivativeKey("photo.png", "etag", spec)
+	client.objects[stale] = []byte("old")
+	client.objects[current] = []byte("new")
+
+	put()
+	if _, ok := client.objects[stale]; ok {
+		t.Fatalf("derivative of the overwritten ETag must be removed")
+	}
+	if _, ok := client.objects[current]; !ok {
+		t.Fatalf("derivative of the current ETag must be kept")
+	}
+}
+
+func TestContentAddressableDeduplication(t *testing.T) {
+	client := &fakeStorageClient{existsMap: map[string]bool{"docs": true}}
+	repo := newFakeObjectRepository()
+	svc := NewStorageServiceWithClient(client, "", repo)
+	ctx := context.Background()
+
+	enable := &fakeContext{params: map[string]string{"bucketName": "docs"}, body: []byte(`{"enabled":true}`)}
+	svc.PutBucketContentAddressing(enable)
+	if enable.status != http.StatusOK {
+		t.Fatalf("expected 200 got %d", enable.status)
+	}
+
+	put := func(name, body string) *UploadObjectResponse {
+		t.Helper()
+		pctx := &fakeContext{
+			params: map[string]string{"bucketName": "docs", "objectName": name},
+			req:    httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body)),
+		}
+		svc.PutObject(pctx)
+		if pctx.status != http.StatusCreated {
+			t.Fatalf("%s: expected 201 got %d: %+v", name, pctx.status, pctx.resp)
+		}
+		return pctx.resp.(*UploadObjectResponse)
+	}
+	refs := func(storagePath string) int {
+		t.Helper()
+		b, ok := repo.blobs[storagePath]
+		if !ok {
+			return -1
+		}
+		return b.RefCount
+	}
+
+	a := put("a.txt", "same content")
+	c := put("nested/c.txt", "same content")
+	d := put("d.txt", "other content")
+	if !strings.HasPrefix(a.StoragePath, blobKeyPrefix) || a.StoragePath != c.StoragePath {
+		t.Fatalf("expected shared blob, got %s and %s", a.StoragePath, c.StoragePath)
+	}
+	if d.StoragePath == a.StoragePath {
+		t.Fatal("different content must not share a blob")
+	}
+	if n := refs(a.StoragePath); n != 2 {
+		t.Fatalf("expected 2 references, got %d", n)
+	}
+	for key := range client.objects {
+		if strings.HasPrefix(key, "docs/"+stagingKeyPrefix) || key == "docs/a.txt" {
+			t.Fatalf("unexpected backend object %s", key)
+		}
+	}
+
+	if _, err := svc.copyObject(ctx, "docs", "a.txt", "docs", "e.txt", metadataDirectiveCopy, CopyObjectRequest{}, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	if repo.objects["docs/e.txt"].StoragePath != a.StoragePath || refs(a.StoragePath) != 3 {
+		t.Fatalf("copy must share the source blob, refs %d", refs(a.StoragePath))
+	}
+	// 덮어쓰면 이전 blob의 참조가 빠지고 새 blob의 참조가 더해집니다.
+	put("e.txt", "other content")
+	if refs(a.StoragePath) != 2 || refs(d.StoragePath) != 2 {
+		t.Fatalf("unexpected refs after overwrite: %d, %d", refs(a.StoragePath), refs(d.StoragePath))
+	}
+
+	if err := svc.deleteObject(ctx, "docs", "a.txt", writeCondition{}, true); err != nil {
+		t.Fatal(err)
+	}
+	if refs(a.StoragePath) != 1 || client.objects["guiio-blobs/"+a.StoragePath] == nil {
+		t.Fatalf("blob must survive while referenced, refs %d", refs(a.StoragePath))
+	}
+
+	// 휴지통에 있는 객체도 참조를 유지하고, 비울 때 마지막 참조가 빠지면 blob을 지웁니다.
+	if err := svc.deleteObject(ctx, "docs", "nested/c.txt", writeCondition{}, false); err != nil {
+		t.Fatal(err)
+	}
+	if refs(a.StoragePath) != 1 {
+		t.Fatalf("trashed object must keep its reference, refs %d", refs(a.StoragePath))
+	}
+	if _, err := svc.PurgeExpiredTrash(ctx, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if refs(a.StoragePath) != -1 || client.objects["guiio-blobs/"+a.StoragePath] != nil {
+		t.Fatal("unreferenced blob must be removed")
+	}
+	if n, err := svc.CollectUnreferencedBlobs(ctx); err != nil || n != 0 {
+		t.Fatalf("expected nothing to collect, got %d, %v", n, err)
+	}
+}
+
+func TestContentAddressableAcrossBuckets(t *testing.T) {
+	client := &fakeStorageClient{existsMap: map[string]bool{"docs": true, "media": true}}
+	repo := newFakeObjectRepository()
+	svc := NewStorageServiceWithClient(client, "", repo)
+	repo.cas["docs"] = true
+	repo.cas["media"] = true
+	ctx := context.Background()
+
+	put := func(bucket, name string) *UploadObjectResponse {
+		t.Helper()
+		pctx := &fakeContext{
+			params: map[string]string{"bucketName": bucket, "objectName": name},
+			req:    httptest.NewRequest(http.MethodPut, "/", strings.NewReader("shared body")),
+		}
+		svc.PutObject(pctx)
+		if pctx.status != http.StatusCreated {
+			t.Fatalf("%s/%s: expected 201 got %d: %+v", bucket, name, pctx.status, pctx.resp)
+		}
+		return pctx.resp.(*UploadObjectResponse)
+	}
+
+	a := put("docs", "a.txt")
+	b := put("media", "b.txt")
+	if a.StoragePath != b.StoragePath || !isBlobKey(a.StoragePath) {
+		t.Fatalf("expected one blob across buckets, got %s and %s", a.StoragePath, b.StoragePath)
+	}
+	if blob := repo.blobs[a.StoragePath]; blob == nil || blob.RefCount != 2 {
+		t.Fatalf("expected 2 references, got %+v", blob)
+	}
+	for key := range client.objects {
+		if strings.HasSuffix(key, a.StoragePath) && key != "guiio-blobs/"+a.StoragePath {
+			t.Fatalf("blob must only be stored in the blob bucket, found %s", key)
+		}
+	}
+	if data, ok := storedObject(client, repo, "media", "b.txt"); !ok || string(data) != "shared body" {
+		t.Fatalf("unexpected body %q", data)
+	}
+
+	// 다른 버킷으로 복사해도 본문을 옮기지 않고 같은 blob을 가리킵니다.
+	if _, err := svc.copyObject(ctx, "docs", "a.txt", "media", "c.txt", metadataDirectiveCopy, CopyObjectRequest{}, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	if repo.objects["media/c.txt"].StoragePath != a.StoragePath || repo.blobs[a.StoragePath].RefCount != 3 {
+		t.Fatalf("cross-bucket copy must share the blob, refs %d", repo.blobs[a.StoragePath].RefCount)
+	}
+
+	for _, target := range []struct{ bucket, name string }{{"docs", "a.txt"}, {"media", "b.txt"}, {"media", "c.txt"}} {
+		if err := svc.deleteObject(ctx, target.bucket, target.name, writeCondition{}, true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, ok := client.objects["guiio-blobs/"+a.StoragePath]; ok || repo.blobs[a.StoragePath] != nil {
+		t.Fatal("blob must be removed with its last reference")
+	}
+
+	// blob 버킷은 사용자 버킷으로 보이지 않고 직접 다룰 수 없습니다.
+	client.listResp = []minio.BucketInfo{{Name: "docs"}, {Name: "guiio-blobs"}}
+	list := &fakeContext{}
+	if err := svc.ListBucket(list); err != nil {
+		t.Fatal(err)
+	}
+	if buckets := list.resp.(BucketListResponse).Buckets; len(buckets) != 1 || buckets[0].Name != "docs" {
+		t.Fatalf("blob bucket must be hidden, got %+v", buckets)
+	}
+	get := &fakeContext{params: map[string]string{"bucketName": "guiio-blobs", "objectName": a.StoragePath}}
+	svc.DownloadObject(get)
+	if get.status != http.StatusBadRequest {
+		t.Fatalf("expected 400 for the blob bucket, got %d", get.status)
+	}
+}
+
+func TestContentAddressableLargeCopy(t *testing.T) {
+	client := &fakeStorageClient{existsMap: map[string]bool{"docs": true}}
+	repo := newFakeObjectRepository()
+	svc := NewStorageServiceWithClient(client, "", repo)
+	repo.cas["docs"] = true
+
+	// CopyObject 한도를 넘는 원본은 ComposeObject로 blob에 복사합니다.
+	client.objects = map[string][]byte{"docs/" + dataKeyPrefix + "big": []byte("large body")}
+	sum := sha256.Sum256([]byte("large body"))
+	repo.objects["docs/big.bin"] = &ent.Object{
+		ID:             1,
+		BucketName:     "docs",
+		ObjectName:     "big.bin",
+		StoragePath:    dataKeyPrefix + "big",
+		Size:           maxCopyObjectSize + 1,
+		ChecksumSha256: base64.StdEncoding.EncodeToString(sum[:]),
+	}
+	if _, err := svc.copyObject(context.Background(), "docs", "big.bin", "docs", "copy.bin", metadataDirectiveCopy, CopyObjectRequest{}, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	key, _, _ := blobKey(base64.StdEncoding.EncodeToString(sum[:]))
+	if len(client.composeCalled) != 1 || client.composeCalled[0] != "guiio-blobs/"+key {
+		t.Fatalf("expected a composed blob copy, got %v", client.composeCalled)
+	}
+	if data, ok := storedObject(client, repo, "docs", "copy.bin"); !ok || string(data) != "large body" {
+		t.Fatalf("unexpected body %q", data)
+	}
+}
+
+func TestContentAddressableMultipartUpload(t *testing.T) {
+	client := &fakeStorageClient{existsMap: map[string]bool{"docs": true}}
+	repo := newFakeObjectRepository()
+	svc := NewStorageServiceWithClient(client, "", repo)
+	repo.cas["docs"] = true
+
+	put := &fakeContext{
+		params: map[string]string{"bucketName": "docs", "objectName": "a.txt"},
+		req:    httptest.NewRequest(http.MethodPut, "/", strings.NewReader("hello world")),
+	}
+	svc.PutObject(put)
+	if put.status != http.StatusCreated {
+		t.Fatalf("expected 201 got %d: %+v", put.status, put.resp)
+	}
+	blob := put.resp.(*UploadObjectResponse).StoragePath
+
+	ctx := &fakeContext{params: map[string]string{"bucketName": "docs"}, body: []byte(`{"object":"b.txt"}`)}
+	svc.InitiateMultipartUpload(ctx)
+	if ctx.status != http.StatusCreated {
+		t.Fatalf("expected 201 got %d: %+v", ctx.status, ctx.resp)
+	}
+	uploadID := ctx.resp.(MultipartUploadResponse).UploadID
+	for i, body := range []string{"hello ", "world"} {
+		part := &fakeContext{
+			params: map[string]string{"bucketName": "docs", "uploadId": uploadID, "partNumber": strconv.Itoa(i + 1)},
+			req:    httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body)),
+		}
+		if svc.UploadPart(part); part.status != http.StatusOK {
+			t.Fatalf("upload part %d: expected 200 got %d", i+1, part.status)
+		}
+	}
+
+	ctx = &fakeContext{params: map[string]string{"bucketName": "docs", "uploadId": uploadID}}
+	svc.CompleteMultipartUpload(ctx)
+	if ctx.status != http.StatusOK {
+		t.Fatalf("expected 200 got %d: %+v", ctx.status, ctx.resp)
+	}
+	resp := ctx.resp.(UploadObjectResponse)
+	if resp.StoragePath != blob || repo.objects["docs/b.txt"].StoragePath != blob {
+		t.Fatalf("multipart upload must share the blob %s, got %+v", blob, resp)
+	}
+	if b := repo.blobs[blob]; b == nil || b.RefCount != 2 {
+		t.Fatalf("expected 2 references, got %+v", b)
+	}
+	for key := range client.objects {
+		if strings.HasPrefix(key, "docs/"+stagingKeyPrefix) || key == "docs/b.txt" {
+			t.Fatalf("unexpected backend object %s", key)
+		}
+	}
+}
+
+func TestObjectLock(t *testing.T) {
+	client := &fakeStorageClient{existsMap: map[string]bool{"vault": true}}
+	repo := newFakeObjectRepository()
+	svc := NewStorageServiceWithClient(client, "", repo)
+
+	request := func(method string, bypass bool) *http.Request {
+		req := httptest.NewRequest(method, "/", nil)
+		if bypass {
+			req.Header.Set(bypassGovernanceHeader, "bypass-token")
+		}
+		return req
+	}
+	put := func(name string) int {
+		ctx := &fakeContext{
+			params: map[string]string{"bucketName": "vault", "objectName": name},
+			req:    httptest.NewRequest(http.MethodPut, "/", strings.NewReader("record")),
+		}
+		svc.PutObject(ctx)
+		return ctx.status
+	}
+	del := func(name string, bypass bool) int {
+		ctx := &fakeContext{params: map[string]string{"bucketName": "vault", "objectName": name}, req: request(http.MethodDelete, bypass)}
+		svc.DeleteObject(ctx)
+		return ctx.status
+	}
+	retention := func(name, body string, bypass bool) int {
+		ctx := &fakeContext{params: map[string]string{"bucketName": "vault", "objectName": name}, req: request(http.MethodPut, bypass), body: []byte(body)}
+		svc.PutObjectRetention(ctx)
+		return ctx.status
+	}
+	legalHold := func(name string, enabled bool) int {
+		ctx := &fakeContext{params: map[string]string{"bucketName": "vault", "objectName": name}, body: []byte(fmt.Sprintf(`{"enabled":%t}`, enabled))}
+		svc.PutObjectLegalHold(ctx)
+		return ctx.status
+	}
+	until := func(d time.Duration) string {
+		return time.Now().Add(d).UTC().Format(time.RFC3339)
+	}
+
+	if status := retention("missing", `{}`, false); status != http.StatusBadRequest {
+		t.Fatalf("expected 400 before object lock is enabled, got %d", status)
+	}
+	enable := &fakeContext{params: map[string]string{"bucketName": "vault"}, body: []byte(`{"enabled":true,"default_retention_mode":"governance","default_retention_days":1}`)}
+	svc.PutBucketObjectLock(enable)
+	if enable.status != http.StatusOK {
+		t.Fatalf("expected 200 got %d: %+v", enable.status, enable.resp)
+	}
+
+	// 기본 보존이 붙은 객체는 우회 권한 없이 지우거나 덮어쓸 수 없습니다.
+	if status := put("gov.txt"); status != http.StatusCreated {
+		t.Fatalf("expected 201 got %d", status)
+	}
+	if obj := repo.objects["vault/gov.txt"]; obj.RetentionMode != retentionModeGovernance || obj.RetainUntil == nil || !obj.RetainUntil.After(time.Now().Add(23*time.Hour)) {
+		t.Fatalf("expected default retention, got %s %v", obj.RetentionMode, obj.RetainUntil)
+	}
+	if status := put("gov.txt"); status != http.StatusForbidden {
+		t.Fatalf("expected 403 for overwrite, got %d", status)
+	}
+	if status := del("gov.txt", false); status != http.StatusForbidden {
+		t.Fatalf("expected 403 for delete, got %d", status)
+	}
+	if _, err := svc.copyObject(context.Background(), "vault", "gov.txt", "vault", "gov.txt.bak", metadataDirectiveCopy, CopyObjectRequest{}, false, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := svc.copyObject(context.Background(), "vault", "gov.txt.bak", "vault", "gov.txt", metadataDirectiveCopy, CopyObjectRequest{}, false, nil); !errors.Is(err, errObjectLocked) {
+		t.Fatalf("expected copy onto locked object to fail, got %v", err)
+	}
+	if _, err := svc.copyObject(context.Background(), "vault", "gov.txt.bak", "vault", "gov.txt", metadataDirectiveCopy, CopyObjectRequest{}, true, nil); err != nil {
+		t.Fatalf("expected bypass copy onto governance object to succeed, got %v", err)
+	}
+	meta := &fakeContext{params: map[string]string{"bucketName": "vault", "objectName": "gov.txt"}, req: request(http.MethodPatch, false), body: []byte(`{"metadata":{"owner":"alice"}}`)}
+	svc.UpdateObjectMetadata(meta)
+	if meta.status != http.StatusForbidden {
+		t.Fatalf("expected 403 for metadata update on locked object, got %d", meta.status)
+	}
+	if status := retention("gov.txt", `{"mode":"GOVERNANCE","retain_until":"`+until(time.Hour)+`"}`, false); status != http.StatusForbidden {
+		t.Fatalf("expected 403 for shortening without bypass, got %d", status)
+	}
+	if status := retention("gov.txt", `{"mode":"GOVERNANCE","retain_until":"`+until(time.Hour)+`"}`, true); status != http.StatusOK {
+		t.Fatalf("expected 200 for shortening with bypass, got %d", status)
+	}
+	if status := del("gov.txt", true); status != http.StatusOK {
+		t.Fatalf("expected bypass delete to succeed, got %d", status)
+	}
+	// 휴지통에 있는 행의 보존 설정은 같은 키를 새로 만드는 것을 막지 않습니다.
+	if trashed := repo.trashed["vault/gov.txt"]; trashed == nil || trashed.RetainUntil == nil {
+		t.Fatal("expected governance object to be trashed with its retention")
+	}
+	if status := put("gov.txt"); status != http.StatusCreated {
+		t.Fatalf("expected 201 for re-creating trashed key, got %d", status)
+	}
+
+	// COMPLIANCE는 연장만 가능하고 우회 권한으로도 지울 수 없습니다.
+	put("comp.txt")
+	if status := retention("comp.txt", `{"mode":"COMPLIANCE","retain_until":"`+until(48*time.Hour)+`"}`, false); status != http.StatusOK {
+		t.Fatalf("expected 200 for strengthening, got %d", status)
+	}
+	if status := retention("comp.txt", `{}`, true); status != http.StatusForbidden {
+		t.Fatalf("expected 403 for removing compliance retention, got %d", status)
+	}
+	if status := retention("comp.txt", `{"mode":"GOVERNANCE","retain_until":"`+until(72*time.Hour)+`"}`, true); status != http.StatusForbidden {
+		t.Fatalf("expected 403 for weakening compliance mode, got %d", status)
+	}
+	if status := del("comp.txt", true); status != http.StatusForbidden {
+		t.Fatalf("expected 403 for compliance delete, got %d", status)
+	}
+
+	// legal hold는 보존 기한과 상관없이 풀 때까지 지울 수 없습니다.
+	put("hold.txt")
+	if status := retention("hold.txt", `{}`, true); status != http.StatusOK {
+		t.Fatalf("expected 200 for removing governance retention, got %d", status)
+	}
+	if status := legalHold("hold.txt", true); status != http.StatusOK {
+		t.Fatalf("expected 200 got %d", status)
+	}
+	if status := del("hold.txt", true); status != http.StatusForbidden {
+		t.Fatalf("expected 403 under legal hold, got %d", status)
+	}
+	legalHold("hold.txt", false)
+	if status := del("hold.txt", false); status != http.StatusOK {
+		t.Fatalf("expected delete after releasing legal hold, got %d", status)
+	}
+
+	disable := &fakeContext{params: map[string]string{"bucketName": "vault"}, body: []byte(`{"enabled":false}`)}
+	svc.PutBucketObjectLock(disable)
+	if disable.status != http.StatusBadRequest {
+		t.Fatalf("expected 400 for disabling object lock, got %d", disable.status)
+	}
+}
+
+func TestLifecycle(t *testing.T) {
+	client := &fakeStorageClient{existsMap: map[string]bool{"logs": true, "docs": true}}
+	repo := newFakeObjectRepository()
+	svc := NewStorageServiceWithClient(client, "", repo)
+
+	put := func(bucket, name, body string) {
+		req := httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body))
+		ctx := &fakeContext{params: map[string]string{"bucketName": bucket, "objectName": name}, req: req}
+		svc.PutObject(ctx)
+		if ctx.status != http.StatusOK && ctx.status != http.StatusCreated {
+			t.Fatalf("put %s/%s: expected 200 or 201 got %d: %+v", bucket, name, ctx.status, ctx.resp)
+		}
+	}
+	putLifecycle := func(bucket, body string) int {
+		ctx := &fakeContext{params: map[string]string{"bucketName": bucket}, body: []byte(body)}
+		svc.PutBucketLifecycle(ctx)
+		return ctx.status
+	}
+
+	if status := putLifecycle("logs", `{"rules":[{"id":"tmp","expiration_days":7,"expiration_date":"2026-01-01T00:00:00Z"}]}`); status != http.StatusBadRequest {
+		t.Fatalf("expected 400 for days with date got %d", status)
+	}
+	if status := putLifecycle("logs", `{"rules":[{"id":"tmp"}]}`); status != http.StatusBadRequest {
+		t.Fatalf("expected 400 for rule without action got %d", status)
+	}
+	if status := putLifecycle("logs", `{"rules":[{"id":"tmp","tags":{"a":"b"},"abort_incomplete_upload_days":1}]}`); status != http.StatusBadRequest {
+		t.Fatalf("expected 400 for tag filter on upload abort got %d", status)
+	}
+	if status := putLifecycle("logs", `{"rules":[{"id":"tmp","prefix":"tmp/","expiration_days":7,"abort_incomplete_upload_days":1}]}`); status != http.StatusOK {
+		t.Fatalf("put lifecycle: expected 200 got %d", status)
+	}
+
+	put("logs", "tmp/old.log", "old")
+	put("logs", "tmp/new.log", "new")
+	put("logs", "app/old.log", "keep")
+	old := time.Now().AddDate(0, 0, -10)
+	repo.objects["logs/tmp/old.log"].UpdatedAt = old
+	repo.objects["logs/app/old.log"].UpdatedAt = old
+
+	initCtx := &fakeContext{params: map[string]string{"bucketName": "logs"}, body: []byte(`{"object":"tmp/big.log"}`)}
+	svc.InitiateMultipartUpload(initCtx)
+	for _, session := range repo.sessions {
+		session.CreatedAt = time.Now().AddDate(0, 0, -2)
+	}
+
+	// 이전 버전 만료: v1은 v2가 생긴 지 20일이 지났고, v2는 방금 현재 버전이 아니게 됐습니다.
+	versionCtx := &fakeContext{params: map[string]string{"bucketName": "docs"}, body: []byte(`{"status":"Enabled"}`)}
+	svc.PutBucketVersioning(versionCtx)
+	put("docs", "a.txt", "v1")
+	put("docs", "a.txt", "v2")
+	put("docs", "a.txt", "v3")
+	history, _ := repo.ListObjectVersions(context.Background(), "docs", "a.txt")
+	if len(history) != 3 {
+		t.Fatalf("expected 3 versions got %d", len(history))
+	}
+	history[2].CreatedAt = time.Now().AddDate(0, 0, -30)
+	history[1].CreatedAt = time.Now().AddDate(0, 0, -20)
+	if status := putLifecycle("docs", `{"rules":[{"id":"versions","noncurrent_expiration_days":10}]}`); status != http.StatusOK {
+		t.Fatalf("put lifecycle: expected 200 got %d", status)
+	}
+
+	n, err := svc.ApplyLifecycle(context.Background(), time.Now())
+	if err != nil || n != 3 {
+		t.Fatalf("expected 3 removed items got %d (%v)", n, err)
+	}
+	if obj := repo.trashed["logs/tmp/old.log"]; obj == nil || obj.DeletedAt == nil {
+		t.Fatalf("expired object should be moved to trash")
+	}
+	for _, key := range []string{"logs/tmp/new.log", "logs/app/old.log"} {
+		if repo.objects[key].DeletedAt != nil {
+			t.Fatalf("%s should not expire", key)
+		}
+	}
+	if len(repo.sessions) != 0 {
+		t.Fatalf("incomplete upload should be aborted")
+	}
+	remaining, _ := repo.ListObjectVersions(context.Background(), "docs", "a.txt")
+	if len(remaining) != 2 || remaining[1].VersionID != history[1].VersionID {
+		t.Fatalf("expected only the oldest version to expire, remaining %d", len(remaining))
+	}
+	if _, ok := client.objects["docs/"+history[2].StoragePath]; ok {
+		t.Fatalf("expired version copy should be removed from the backend")
+	}
+
+	eventsCtx := &fakeContext{params: map[string]string{"bucketName": "logs"}}
+	svc.ListLifecycleEvents(eventsCtx)
+	if eventsCtx.status != http.StatusOK {
+		t.Fatalf("list events: expected 200 got %d", eventsCtx.status)
+	}
+	events := eventsCtx.resp.(ListLifecycleEventsResponse).Events
+	if len(events) != 2 {
+		t.Fatalf("expected 2 lifecycle events got %+v", events)
+	}
+	actions := map[string]string{}
+	for _, e := range events {
+		actions[e.Action] = e.Key
+	}
+	if actions[lifecycleExpireObject] != "tmp/old.log" || actions[lifecycleAbortUpload] != "tmp/big.log" {
+		t.Fatalf("unexpected lifecycle events %+v", events)
+	}
+
+	if n, err := svc.ApplyLifecycle(context.Background(), time.Now()); err != nil || n != 0 {
+		t.Fatalf("second evaluation should remove nothing got %d (%v)", n, err)
+	}
+}
+
+func TestLegalHoldDuringCopyBlocksOverwrite(t *testing.T) {
+	client := &fakeStorageClient{existsMap: map[string]bool{"vault": true}}
+	repo := newFakeObjectRepository()
+	repo.lockCfg["vault"] = repository.BucketObjectLock{Enabled: true}
+	svc := NewStorageServiceWithClient(client, "", repo)
+
+	for name, body := range map[string]string{"a.txt": "original", "src.txt": "replacement"} {
+		ctx := &fakeContext{
+			params: map[string]string{"bucketName": "vault", "objectName": name},
+			req:    httptest.NewRequest(http.MethodPut, "/", strings.NewReader(body)),
+		}
+		svc.PutObject(ctx)
+		if ctx.status != http.StatusCreated {
+			t.Fatalf("put %s: expected 201 got %d", name, ctx.status)
+		}
+	}
+
+	// legal hold를 거는 트랜잭션이 키를 잡고 있는 동안 시작한 복사는 커밋된 hold를 보고 거부되어야 합니다.
+	otx, _ := repo.BeginObjectTx(context.Background(), "vault", "a.txt")
+	done := make(chan int)
+	go func() {
+		ctx := &fakeContext{
+			params: map[string]string{"bucketName": "vault", "objectName": "src.txt"},
+			body:   []byte(`{"destination_key":"a.txt"}`),
+		}
+		svc.CopyObject(ctx)
+		done <- ctx.status
+	}()
+	if _, err := otx.SetLegalHold(context.Background(), true); err != nil {
+		t.Fatalf("set legal hold: %v", err)
+	}
+	otx.Commit()
+
+	if status := <-done; status != http.StatusForbidden {
+		t.Fatalf("expected 403 for copy onto held object, got %d", status)
+	}
+	if got, _ := storedObject(client, repo, "vault", "a.txt"); string(got) != "original" {
+		t.Fatalf("held object was overwritten with %q", got)
+	}
 }
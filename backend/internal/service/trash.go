@@ -0,0 +1,325 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"guiio/backend/ent"
+	httpctx "guiio/backend/internal/port/httpctx"
+	"guiio/backend/internal/repository"
+
+	"github.com/minio/minio-go/v7"
+	"github.com/rs/zerolog"
+	"github.com/sphynx/config"
+)
+
+type TrashedObjectInfo struct {
+	Key         string    `json:"key"`
+	Size        int64     `json:"size"`
+	ContentType string    `json:"content_type,omitempty"`
+	ETag        string    `json:"etag"`
+	DeletedAt   time.Time `json:"deleted_at"`
+	// PurgeAt 이후 purge 작업이 돌면 영구 삭제됩니다.
+	PurgeAt time.Time `json:"purge_at"`
+}
+
+type ListTrashResponse struct {
+	Bucket      string              `json:"bucket"`
+	Prefix      string              `json:"prefix,omitempty"`
+	Objects     []TrashedObjectInfo `json:"objects"`
+	IsTruncated bool                `json:"is_truncated"`
+	NextCursor  string              `json:"next_cursor,omitempty"`
+}
+
+type EmptyTrashResponse struct {
+	Bucket string `json:"bucket"`
+	Purged int    `json:"purged"`
+}
+
+func (s *StorageService) ListTrash(ctx httpctx.Context) {
+	bucketName, ok := s.trashBucket(ctx)
+	if !ok {
+		return
+	}
+
+	prefix := ctx.Query("prefix")
+	limit, err := parseListLimit(ctx.Query("limit"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	startAfter, err := decodeListCursor(ctx.Query("cursor"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	objects, err := s.repo.ListTrashedObjects(ctx.Context(), repository.TrashListInput{
+		BucketName: bucketName,
+		Prefix:     prefix,
+		StartAfter: startAfter,
+		Limit:      limit + 1,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("list trash failed: %v", err)})
+		return
+	}
+
+	resp := ListTrashResponse{Bucket: bucketName, Prefix: prefix}
+	if len(objects) > limit {
+		objects = objects[:limit]
+		resp.IsTruncated = true
+		resp.NextCursor = encodeListCursor(objects[limit-1].ObjectName)
+	}
+	retention := trashRetention()
+	resp.Objects = make([]TrashedObjectInfo, 0, len(objects))
+	for _, obj := range objects {
+		resp.Objects = append(resp.Objects, TrashedObjectInfo{
+			Key:         obj.ObjectName,
+			Size:        obj.Size,
+			ContentType: obj.ContentType,
+			ETag:        obj.Etag,
+			DeletedAt:   *obj.DeletedAt,
+			PurgeAt:     obj.DeletedAt.Add(retention),
+		})
+	}
+
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// RestoreTrashedObject는 휴지통의 객체를 원래 이름으로 되돌립니다.
+// 휴지통으로 옮긴 뒤 같은 키에 새 객체를 썼으면 덮어쓰지 않고 409를 반환합니다.
+// 버전 관리 중인 버킷이면 삭제 마커 위에 복원된 내용을 새 버전으로 쌓습니다.
+func (s *StorageService) RestoreTrashedObject(ctx httpctx.Context) {
+	bucketName, ok := s.trashBucket(ctx)
+	if !ok {
+		return
+	}
+	objectName := strings.TrimSpace(ctx.Param("objectName"))
+	if err := validateObjectName(objectName); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	reqCtx := ctx.Context()
+	// 같은 키로 들어오는 업로드나 purge와 겹치지 않도록 키를 잠근 채 되살리고 버전을 남깁니다.
+	otx, err := s.repo.BeginObjectTx(reqCtx, bucketName, objectName)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("lock object failed: %v", err)})
+		return
+	}
+	defer otx.Rollback()
+
+	if otx.Trashed() == nil {
+		ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "object not found in trash"})
+		return
+	}
+	if current := otx.Current(); current.DeletedAt == nil {
+		ctx.JSON(http.StatusConflict, ErrorResponse{Error: "object already exists"})
+		return
+	}
+
+	obj, err := otx.Restore(reqCtx)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "object not found in trash"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("restore object failed: %v", err)})
+		return
+	}
+
+	head := objectHeadFromEnt(bucketName, objectName, obj)
+	head.VersionID, err = s.recordVersion(reqCtx, otx, repository.ObjectUpsertInput{
+		BucketName:     bucketName,
+		ObjectName:     objectName,
+		StoragePath:    head.StorageKey,
+		ContentType:    head.ContentType,
+		Size:           head.Size,
+		ETag:           head.ETag,
+		Metadata:       head.Metadata,
+		ChecksumSHA256: head.ChecksumSHA256,
+		ChecksumCRC32C: head.ChecksumCRC32C,
+	})
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err := otx.Commit(); err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("restore object failed: %v", err)})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newObjectStatResponse(bucketName, objectName, head))
+}
+
+// EmptyTrash는 보존 기간과 관계없이 버킷 휴지통의 객체를 모두 영구 삭제합니다.
+func (s *StorageService) EmptyTrash(ctx httpctx.Context) {
+	bucketName, ok := s.trashBucket(ctx)
+	if !ok {
+		return
+	}
+
+	reqCtx := ctx.Context()
+	purged := 0
+	startAfter := ""
+	for {
+		objects, err := s.repo.ListTrashedObjects(reqCtx, repository.TrashListInput{
+			BucketName: bucketName,
+			StartAfter: startAfter,
+			Limit:      uploadCleanupBatch,
+		})
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("list trash failed: %v", err)})
+			return
+		}
+		if len(objects) == 0 {
+			break
+		}
+		for _, obj := range objects {
+			ok, err := s.purgeTrashedObject(reqCtx, obj)
+			if err != nil {
+				ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("purge %q failed: %v", obj.ObjectName, err)})
+				return
+			}
+			if ok {
+				purged++
+			}
+		}
+		startAfter = objects[len(objects)-1].ObjectName
+	}
+
+	ctx.JSON(http.StatusOK, EmptyTrashResponse{Bucket: bucketName, Purged: purged})
+}
+
+// RunTrashPurger는 trash_purge_interval_minutes마다 trash_retention_hours보다 오래 휴지통에 있던
+// 객체를 영구 삭제합니다. ctx가 끝나면 반환합니다.
+func (s *StorageService) RunTrashPurger(ctx context.Context, log *zerolog.Logger) {
+	interval := time.Duration(config.Get[int]("trash_purge_interval_minutes")) * time.Minute
+	retention := trashRetention()
+	if s.repo == nil || interval <= 0 || retention <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		n, err := s.PurgeExpiredTrash(ctx, time.Now().Add(-retention))
+		if err != nil {
+			log.Error().Err(err).Msg("trash purge failed")
+			continue
+		}
+		if n > 0 {
+			log.Info().Msgf("purged %d objects from trash", n)
+		}
+	}
+}
+
+// PurgeExpiredTrash는 deletedBefore 이전에 휴지통으로 옮겨진 객체를 모두 영구 삭제하고 그 개수를 반환합니다.
+func (s *StorageService) PurgeExpiredTrash(ctx context.Context, deletedBefore time.Time) (int, error) {
+	purged := 0
+	for {
+		objects, err := s.repo.ListExpiredTrash(ctx, deletedBefore, uploadCleanupBatch)
+		if err != nil {
+			return purged, err
+		}
+		if len(objects) == 0 {
+			return purged, nil
+		}
+		for _, obj := range objects {
+			ok, err := s.purgeTrashedObject(ctx, obj)
+			if err != nil {
+				return purged, err
+			}
+			if ok {
+				purged++
+			}
+		}
+	}
+}
+
+// purgeTrashedObject는 키를 잠근 채 휴지통 행의 백엔드 객체를 지운 뒤 그 행을 삭제합니다. blob은 행을 지운 뒤 참조가 남지 않았을 때만 지웁니다.
+// 같은 키에 새로 쓴 살아 있는 객체는 별도 행과 본문을 가지므로 건드리지 않습니다.
+// 목록을 읽은 뒤 되살아났거나 다시 휴지통으로 옮겨진 항목은 건드리지 않고 false를 반환합니다.
+func (s *StorageService) purgeTrashedObject(ctx context.Context, obj *ent.Object) (bool, error) {
+	otx, err := s.repo.BeginObjectTx(ctx, obj.BucketName, obj.ObjectName)
+	if err != nil {
+		return false, fmt.Errorf("lock object: %w", err)
+	}
+	defer otx.Rollback()
+
+	trashed := otx.Trashed()
+	if trashed == nil || trashed.ID != obj.ID || obj.DeletedAt == nil || !trashed.DeletedAt.Equal(*obj.DeletedAt) {
+		return false, nil
+	}
+
+	storageKey := normalizeStoragePath(obj.BucketName, trashed.StoragePath, encodeObjectKey(obj.ObjectName))
+	shared := isBlobKey(storageKey)
+	if !shared {
+		if err := s.client.RemoveObject(ctx, obj.BucketName, storageKey, minio.RemoveObjectOptions{}); err != nil {
+			return false, fmt.Errorf("remove object: %w", err)
+		}
+	}
+	if current := otx.Current(); current.DeletedAt == nil {
+		s.pruneDerivatives(ctx, obj.BucketName, obj.ObjectName, current.Etag)
+	} else {
+		s.removeDerivatives(ctx, obj.BucketName, obj.ObjectName)
+	}
+	if err := otx.DeleteTrashed(ctx); err != nil && !ent.IsNotFound(err) {
+		return false, fmt.Errorf("delete object metadata: %w", err)
+	}
+	if err := otx.Commit(); err != nil {
+		return false, fmt.Errorf("delete object metadata: %w", err)
+	}
+	if shared {
+		s.releaseStorage(ctx, obj.BucketName, storageKey)
+	}
+	return true, nil
+}
+
+// trashBucket은 휴지통 API의 공통 검증(버킷 이름, repository, 버킷 존재)을 처리합니다.
+func (s *StorageService) trashBucket(ctx httpctx.Context) (string, bool) {
+	bucketName := strings.TrimSpace(ctx.Param("bucketName"))
+	if err := validateBucketName(bucketName); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return "", false
+	}
+	if s.repo == nil {
+		ctx.JSON(http.StatusNotImplemented, ErrorResponse{Error: "trash requires object repository"})
+		return "", false
+	}
+
+	exists, err := s.client.BucketExists(ctx.Context(), bucketName)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("check bucket failed: %v", err)})
+		return "", false
+	}
+	if !exists {
+		ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "bucket not found"})
+		return "", false
+	}
+	return bucketName, true
+}
+
+// trashedObject는 otx가 잠근 키의 휴지통 행을 반환합니다. otx가 nil이면(repository 없음) nil입니다.
+func trashedObject(otx repository.ObjectTx) *ent.Object {
+	if otx == nil {
+		return nil
+	}
+	return otx.Trashed()
+}
+
+// trashRetention은 휴지통 보존 기간입니다. 0 이하이면 휴지통을 쓰지 않고 바로 삭제합니다.
+func trashRetention() time.Duration {
+	return time.Duration(config.Get[int]("trash_retention_hours")) * time.Hour
+}
@@ -0,0 +1,473 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"guiio/backend/ent"
+	httpctx "guiio/backend/internal/port/httpctx"
+	"guiio/backend/internal/repository"
+
+	"github.com/rs/zerolog"
+	"github.com/sphynx/config"
+)
+
+const (
+	tusVersion           = "1.0.0"
+	tusExtensions        = "creation,expiration,termination"
+	tusOffsetContentType = "application/offset+octet-stream"
+)
+
+// Upload-Metadata 중 객체 속성으로 쓰는 키입니다. 나머지 키는 사용자 메타데이터로 저장합니다.
+var tusReservedMetadata = map[string]bool{"key": true, "filename": true, "filetype": true}
+
+// TusOptions는 서버가 지원하는 tus 버전과 확장을 알려줍니다.
+func (s *StorageService) TusOptions(ctx httpctx.Context) {
+	ctx.SetHeader("Tus-Resumable", tusVersion)
+	ctx.SetHeader("Tus-Version", tusVersion)
+	ctx.SetHeader("Tus-Extension", tusExtensions)
+	if maxSize := config.Get[int64]("object_max_upload_size"); maxSize > 0 {
+		ctx.SetHeader("Tus-Max-Size", strconv.FormatInt(maxSize, 10))
+	}
+	_ = ctx.Stream(http.StatusNoContent, "", bytes.NewReader(nil))
+}
+
+// CreateTusUpload는 creation 확장의 POST 요청으로 새 업로드를 만들고 Location에 업로드 URL을 돌려줍니다.
+// 객체 이름은 Upload-Metadata의 key(없으면 filename), 콘텐츠 타입은 filetype에서 가져옵니다.
+func (s *StorageService) CreateTusUpload(ctx httpctx.Context) {
+	if !checkTusResumable(ctx) {
+		return
+	}
+	bucketName := strings.TrimSpace(ctx.Param("bucketName"))
+	if err := validateBucketName(bucketName); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if s.repo == nil {
+		ctx.JSON(http.StatusNotImplemented, ErrorResponse{Error: "tus upload requires object repository"})
+		return
+	}
+
+	length, err := strconv.ParseInt(ctx.GetHeader("Upload-Length"), 10, 64)
+	if err != nil || length < 0 {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "valid Upload-Length header is required"})
+		return
+	}
+	if maxSize := config.Get[int64]("object_max_upload_size"); maxSize > 0 && length > maxSize {
+		ctx.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Error: errObjectTooLarge.Error()})
+		return
+	}
+
+	rawMetadata := ctx.GetHeader("Upload-Metadata")
+	meta, err := parseTusMetadata(rawMetadata)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	objectName := strings.TrimSpace(meta["key"])
+	if objectName == "" {
+		objectName = strings.TrimSpace(meta["filename"])
+	}
+	if err := validateObjectName(objectName); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	contentType := strings.TrimSpace(meta["filetype"])
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+
+	reqCtx := ctx.Context()
+
+	exists, err := s.client.BucketExists(reqCtx, bucketName)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("check bucket failed: %v", err)})
+		return
+	}
+	if !exists {
+		ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "bucket not found"})
+		return
+	}
+
+	uploadID := newRandomID()
+	if err := createTusFile(uploadID); err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("create upload file failed: %v", err)})
+		return
+	}
+
+	upload, err := s.repo.CreateTusUpload(reqCtx, repository.TusUploadInput{
+		UploadID:       uploadID,
+		BucketName:     bucketName,
+		ObjectName:     objectName,
+		ContentType:    contentType,
+		UploadLength:   length,
+		UploadMetadata: rawMetadata,
+		ExpiresAt:      tusExpiresAt(),
+	})
+	if err != nil {
+		_ = os.Remove(tusFilePath(uploadID))
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("save tus upload failed: %v", err)})
+		return
+	}
+
+	// 길이가 0인 업로드는 PATCH 없이 끝나므로 바로 객체로 만듭니다.
+	if length == 0 {
+		if err := s.finishTusUpload(reqCtx, upload); err != nil {
+			writeStoreError(ctx, err)
+			return
+		}
+	}
+
+	location := strings.TrimSuffix(ctx.Request().URL.Path, "/") + "/" + uploadID
+	ctx.SetHeader("Location", location)
+	ctx.SetHeader("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	_ = ctx.Stream(http.StatusCreated, "", bytes.NewReader(nil))
+}
+
+// HeadTusUpload는 지금까지 받은 오프셋을 알려줘 클라이언트가 끊긴 지점부터 이어 올리게 합니다.
+func (s *StorageService) HeadTusUpload(ctx httpctx.Context) {
+	if !checkTusResumable(ctx) {
+		return
+	}
+	upload, ok := s.lookupTusUpload(ctx)
+	if !ok {
+		return
+	}
+
+	ctx.SetHeader("Cache-Control", "no-store")
+	ctx.SetHeader("Upload-Offset", strconv.FormatInt(upload.UploadOffset, 10))
+	ctx.SetHeader("Upload-Length", strconv.FormatInt(upload.UploadLength, 10))
+	if upload.UploadMetadata != "" {
+		ctx.SetHeader("Upload-Metadata", upload.UploadMetadata)
+	}
+	ctx.SetHeader("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	_ = ctx.Stream(http.StatusOK, "", bytes.NewReader(nil))
+}
+
+// PatchTusUpload는 Upload-Offset 위치부터 본문을 이어 붙입니다.
+// 중간에 연결이 끊겨도 받은 바이트까지는 오프셋에 반영하고, 마지막 바이트를 받으면 객체로 저장합니다.
+func (s *StorageService) PatchTusUpload(ctx httpctx.Context) {
+	if !checkTusResumable(ctx) {
+		return
+	}
+	if ctx.GetHeader("Content-Type") != tusOffsetContentType {
+		ctx.JSON(http.StatusUnsupportedMediaType, ErrorResponse{Error: "Content-Type must be " + tusOffsetContentType})
+		return
+	}
+	offset, err := strconv.ParseInt(ctx.GetHeader("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "valid Upload-Offset header is required"})
+		return
+	}
+
+	unlock, ok := s.lockTusUpload(ctx)
+	if !ok {
+		return
+	}
+	defer unlock()
+
+	upload, ok := s.lookupTusUpload(ctx)
+	if !ok {
+		return
+	}
+	if offset != upload.UploadOffset {
+		ctx.JSON(http.StatusConflict, ErrorResponse{Error: fmt.Sprintf("offset mismatch: upload is at %d", upload.UploadOffset)})
+		return
+	}
+
+	r := ctx.Request()
+	remaining := upload.UploadLength - upload.UploadOffset
+	if r.ContentLength > remaining {
+		ctx.JSON(http.StatusRequestEntityTooLarge, ErrorResponse{Error: "request body exceeds Upload-Length"})
+		return
+	}
+
+	reqCtx := ctx.Context()
+
+	var n int64
+	var writeErr error
+	if r.Body != nil {
+		defer r.Body.Close()
+		n, writeErr = appendTusFile(upload.UploadID, upload.UploadOffset, io.LimitReader(r.Body, remaining))
+	}
+
+	if n > 0 {
+		upload, err = s.repo.UpdateTusUploadOffset(reqCtx, upload.ID, upload.UploadOffset+n, tusExpiresAt())
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("save upload offset failed: %v", err)})
+			return
+		}
+	}
+	if writeErr != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("write upload failed: %v", writeErr)})
+		return
+	}
+
+	if upload.UploadOffset == upload.UploadLength {
+		if err := s.finishTusUpload(reqCtx, upload); err != nil {
+			writeStoreError(ctx, err)
+			return
+		}
+	}
+
+	ctx.SetHeader("Upload-Offset", strconv.FormatInt(upload.UploadOffset, 10))
+	ctx.SetHeader("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	_ = ctx.Stream(http.StatusNoContent, "", bytes.NewReader(nil))
+}
+
+// TerminateTusUpload는 termination 확장으로 진행 중인 업로드와 스테이징 파일을 지웁니다.
+func (s *StorageService) TerminateTusUpload(ctx httpctx.Context) {
+	if !checkTusResumable(ctx) {
+		return
+	}
+	unlock, ok := s.lockTusUpload(ctx)
+	if !ok {
+		return
+	}
+	defer unlock()
+
+	upload, ok := s.lookupTusUpload(ctx)
+	if !ok {
+		return
+	}
+	if err := s.removeTusUpload(ctx.Context(), upload); err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("terminate upload failed: %v", err)})
+		return
+	}
+	_ = ctx.Stream(http.StatusNoContent, "", bytes.NewReader(nil))
+}
+
+// RunTusCleaner는 tus_cleanup_interval_minutes마다 만료된 tus 업로드를 지웁니다. ctx가 끝나면 반환합니다.
+func (s *StorageService) RunTusCleaner(ctx context.Context, log *zerolog.Logger) {
+	interval := time.Duration(config.Get[int]("tus_cleanup_interval_minutes")) * time.Minute
+	if s.repo == nil || interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		n, err := s.CleanupExpiredTusUploads(ctx, time.Now())
+		if err != nil {
+			log.Error().Err(err).Msg("tus upload cleanup failed")
+			continue
+		}
+		if n > 0 {
+			log.Info().Msgf("removed %d expired tus uploads", n)
+		}
+	}
+}
+
+// CleanupExpiredTusUploads는 now 기준으로 만료된 tus 업로드를 지우고 지운 개수를 반환합니다.
+// PATCH/DELETE가 처리 중인 업로드는 스테이징 파일을 쓰고 있으므로 건너뛰고 다음 주기에 다시 확인합니다.
+func (s *StorageService) CleanupExpiredTusUploads(ctx context.Context, now time.Time) (int, error) {
+	removed := 0
+	for {
+		uploads, err := s.repo.ListExpiredTusUploads(ctx, now, uploadCleanupBatch)
+		if err != nil {
+			return removed, err
+		}
+		skipped := 0
+		for _, upload := range uploads {
+			unlock, ok := s.tryLockTusUpload(upload.BucketName, upload.UploadID)
+			if !ok {
+				skipped++
+				continue
+			}
+			err := s.removeTusUpload(ctx, upload)
+			unlock()
+			// 목록을 읽은 뒤 잠금을 잡기 전에 PATCH가 업로드를 끝냈으면 행이 이미 없습니다.
+			if err != nil && !ent.IsNotFound(err) {
+				return removed, err
+			}
+			removed++
+		}
+		if len(uploads) == 0 || skipped == len(uploads) {
+			return removed, nil
+		}
+	}
+}
+
+// finishTusUpload는 다 받은 스테이징 파일을 일반 업로드와 같은 경로로 저장하고 업로드 상태를 지웁니다.
+func (s *StorageService) finishTusUpload(ctx context.Context, upload *ent.TusUpload) error {
+	f, err := os.Open(tusFilePath(upload.UploadID))
+	if err != nil {
+		return fmt.Errorf("open upload file failed: %w", err)
+	}
+	defer f.Close()
+
+	meta, _ := parseTusMetadata(upload.UploadMetadata)
+	metadata := make(map[string]string, len(meta))
+	for k, v := range meta {
+		if !tusReservedMetadata[k] {
+			metadata[strings.ToLower(k)] = v
+		}
+	}
+
+	if _, err := s.storeObject(ctx, putObjectInput{
+		BucketName:  upload.BucketName,
+		ObjectName:  upload.ObjectName,
+		ContentType: upload.ContentType,
+		Size:        upload.UploadLength,
+		Metadata:    metadata,
+		Body:        f,
+	}); err != nil {
+		return err
+	}
+	return s.removeTusUpload(ctx, upload)
+}
+
+func (s *StorageService) removeTusUpload(ctx context.Context, upload *ent.TusUpload) error {
+	if err := os.Remove(tusFilePath(upload.UploadID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return s.repo.DeleteTusUpload(ctx, upload.ID)
+}
+
+// lookupTusUpload는 bucketName/uploadId 경로 파라미터로 업로드를 찾고 실패 시 에러 응답을 작성합니다.
+// 만료됐지만 아직 정리되지 않은 업로드는 410으로 응답합니다.
+func (s *StorageService) lookupTusUpload(ctx httpctx.Context) (*ent.TusUpload, bool) {
+	bucketName := strings.TrimSpace(ctx.Param("bucketName"))
+	if err := validateBucketName(bucketName); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return nil, false
+	}
+	if s.repo == nil {
+		ctx.JSON(http.StatusNotImplemented, ErrorResponse{Error: "tus upload requires object repository"})
+		return nil, false
+	}
+
+	uploadID := strings.TrimSpace(ctx.Param("uploadId"))
+	upload, err := s.repo.GetTusUpload(ctx.Context(), bucketName, uploadID)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "upload not found"})
+			return nil, false
+		}
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("get tus upload failed: %v", err)})
+		return nil, false
+	}
+	if time.Now().After(upload.ExpiresAt) {
+		ctx.JSON(http.StatusGone, ErrorResponse{Error: "upload expired"})
+		return nil, false
+	}
+	return upload, true
+}
+
+// lockTusUpload는 같은 업로드에 대한 PATCH/DELETE가 겹치지 않게 막습니다.
+// 이미 처리 중이면 423으로 응답하고 false를 반환합니다.
+func (s *StorageService) lockTusUpload(ctx httpctx.Context) (func(), bool) {
+	unlock, ok := s.tryLockTusUpload(ctx.Param("bucketName"), ctx.Param("uploadId"))
+	if !ok {
+		ctx.JSON(http.StatusLocked, ErrorResponse{Error: "upload is being modified by another request"})
+		return nil, false
+	}
+	return unlock, true
+}
+
+// tryLockTusUpload는 업로드 잠금을 잡고 해제 함수를 반환합니다. 이미 잡혀 있으면 false를 반환합니다.
+func (s *StorageService) tryLockTusUpload(bucketName, uploadID string) (func(), bool) {
+	key := bucketName + "/" + uploadID
+	if _, busy := s.tusLocks.LoadOrStore(key, struct{}{}); busy {
+		return nil, false
+	}
+	return func() { s.tusLocks.Delete(key) }, true
+}
+
+// checkTusResumable은 모든 tus 응답에 Tus-Resumable을 붙이고, 요청 버전이 다르면 412로 응답합니다.
+func checkTusResumable(ctx httpctx.Context) bool {
+	ctx.SetHeader("Tus-Resumable", tusVersion)
+	if ctx.GetHeader("Tus-Resumable") != tusVersion {
+		ctx.SetHeader("Tus-Version", tusVersion)
+		ctx.JSON(http.StatusPreconditionFailed, ErrorResponse{Error: "unsupported tus version"})
+		return false
+	}
+	return true
+}
+
+// parseTusMetadata는 "key base64value,key2 base64value2" 형식의 Upload-Metadata 헤더를 해석합니다.
+func parseTusMetadata(header string) (map[string]string, error) {
+	meta := map[string]string{}
+	if strings.TrimSpace(header) == "" {
+		return meta, nil
+	}
+	for _, pair := range strings.Split(header, ",") {
+		key, encoded, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			return nil, errors.New("invalid Upload-Metadata: empty key")
+		}
+		if _, dup := meta[key]; dup {
+			return nil, fmt.Errorf("invalid Upload-Metadata: duplicate key %q", key)
+		}
+		value, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, fmt.Errorf("invalid Upload-Metadata: key %q is not base64", key)
+		}
+		meta[key] = string(value)
+	}
+	return meta, nil
+}
+
+func tusExpiresAt() time.Time {
+	return time.Now().Add(time.Duration(config.Get[int]("tus_upload_expiry_hours")) * time.Hour)
+}
+
+// tusFilePath는 업로드 ID(서버가 만든 hex 문자열)로 스테이징 파일 경로를 만듭니다.
+func tusFilePath(uploadID string) string {
+	dir := config.Get[string]("tus_upload_dir")
+	if dir == "" {
+		dir = filepath.Join(os.TempDir(), "guiio-tus")
+	}
+	return filepath.Join(dir, uploadID)
+}
+
+func createTusFile(uploadID string) error {
+	path := tusFilePath(uploadID)
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// appendTusFile은 offset 위치부터 r을 기록합니다. 이전 요청이 오프셋 저장 전에 실패해
+// 기록된 오프셋 뒤에 남은 바이트는 먼저 잘라냅니다.
+func appendTusFile(uploadID string, offset int64, r io.Reader) (int64, error) {
+	f, err := os.OpenFile(tusFilePath(uploadID), os.O_WRONLY, 0)
+	if err != nil {
+		return 0, err
+	}
+	if err := f.Truncate(offset); err != nil {
+		f.Close()
+		return 0, err
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return 0, err
+	}
+	n, err := io.Copy(f, r)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	return n, err
+}
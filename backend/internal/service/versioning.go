@@ -0,0 +1,424 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"guiio/backend/ent"
+	httpctx "guiio/backend/internal/port/httpctx"
+	"guiio/backend/internal/repository"
+
+	"github.com/minio/minio-go/v7"
+)
+
+const (
+	versioningEnabled   = "Enabled"
+	versioningSuspended = "Suspended"
+	// 버전 관리가 일시 중지된 동안의 업로드/삭제는 항상 이 ID 하나를 덮어씁니다.
+	nullVersionID    = "null"
+	versionKeyPrefix = systemKeyPrefix + "versions/"
+)
+
+type BucketVersioningRequest struct {
+	Status string `json:"status"`
+}
+
+type BucketVersioningResponse struct {
+	Bucket string `json:"bucket"`
+	// 한 번도 켜지 않은 버킷은 빈 문자열입니다.
+	Status string `json:"status"`
+}
+
+type ObjectVersionInfo struct {
+	VersionID      string            `json:"version_id"`
+	IsLatest       bool              `json:"is_latest"`
+	IsDeleteMarker bool              `json:"is_delete_marker"`
+	ContentType    string            `json:"content_type,omitempty"`
+	Size           int64             `json:"size"`
+	ETag           string            `json:"etag,omitempty"`
+	LastModified   time.Time         `json:"last_modified"`
+	Metadata       map[string]string `json:"metadata,omitempty"`
+}
+
+type ListObjectVersionsResponse struct {
+	Bucket   string              `json:"bucket"`
+	Key      string              `json:"key"`
+	Versions []ObjectVersionInfo `json:"versions"`
+}
+
+func (s *StorageService) GetBucketVersioning(ctx httpctx.Context) {
+	bucketName, ok := s.bucketConfigTarget(ctx, "versioning")
+	if !ok {
+		return
+	}
+
+	status, err := s.repo.GetBucketVersioning(ctx.Context(), bucketName)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("get versioning failed: %v", err)})
+		return
+	}
+	ctx.JSON(http.StatusOK, BucketVersioningResponse{Bucket: bucketName, Status: status})
+}
+
+// PutBucketVersioning은 버전 관리를 켜거나(Enabled) 일시 중지(Suspended)합니다.
+// 한 번 켠 버킷은 끌 수 없고, 일시 중지해도 기존 버전은 남습니다.
+func (s *StorageService) PutBucketVersioning(ctx httpctx.Context) {
+	bucketName, ok := s.bucketConfigTarget(ctx, "versioning")
+	if !ok {
+		return
+	}
+
+	var req BucketVersioningRequest
+	if err := ctx.Bind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "invalid request body"})
+		return
+	}
+	var status string
+	switch strings.ToLower(strings.TrimSpace(req.Status)) {
+	case "enabled":
+		status = versioningEnabled
+	case "suspended":
+		status = versioningSuspended
+	default:
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "status must be Enabled or Suspended"})
+		return
+	}
+
+	if err := s.repo.SetBucketVersioning(ctx.Context(), bucketName, status); err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("set versioning failed: %v", err)})
+		return
+	}
+	ctx.JSON(http.StatusOK, BucketVersioningResponse{Bucket: bucketName, Status: status})
+}
+
+func (s *StorageService) ListObjectVersions(ctx httpctx.Context) {
+	bucketName := strings.TrimSpace(ctx.Param("bucketName"))
+	objectName := strings.TrimSpace(ctx.Param("objectName"))
+	if err := validateBucketName(bucketName); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err := validateObjectName(objectName); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if s.repo == nil {
+		ctx.JSON(http.StatusNotImplemented, ErrorResponse{Error: "versioning requires object repository"})
+		return
+	}
+
+	versions, err := s.repo.ListObjectVersions(ctx.Context(), bucketName, objectName)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("list versions failed: %v", err)})
+		return
+	}
+
+	resp := ListObjectVersionsResponse{Bucket: bucketName, Key: objectName, Versions: make([]ObjectVersionInfo, 0, len(versions))}
+	for i, v := range versions {
+		resp.Versions = append(resp.Versions, ObjectVersionInfo{
+			VersionID:      v.VersionID,
+			IsLatest:       i == 0,
+			IsDeleteMarker: v.IsDeleteMarker,
+			ContentType:    v.ContentType,
+			Size:           v.Size,
+			ETag:           v.Etag,
+			LastModified:   v.CreatedAt,
+			Metadata:       v.Metadata,
+		})
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
+
+// RestoreObjectVersion은 이전 버전을 현재 객체로 되돌립니다.
+// 이력은 지우지 않고, 복원 결과를 새 버전으로 쌓습니다.
+func (s *StorageService) RestoreObjectVersion(ctx httpctx.Context) {
+	bucketName := strings.TrimSpace(ctx.Param("bucketName"))
+	objectName := strings.TrimSpace(ctx.Param("objectName"))
+	versionID := strings.TrimSpace(ctx.Param("versionId"))
+	if err := validateBucketName(bucketName); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if err := validateObjectName(objectName); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if s.repo == nil {
+		ctx.JSON(http.StatusNotImplemented, ErrorResponse{Error: "versioning requires object repository"})
+		return
+	}
+
+	reqCtx := ctx.Context()
+
+	version, err := s.repo.GetObjectVersion(reqCtx, bucketName, objectName, versionID)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "version not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("get version failed: %v", err)})
+		return
+	}
+	if version.IsDeleteMarker {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: "cannot restore a delete marker"})
+		return
+	}
+
+	metadata := version.Metadata
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	record := repository.ObjectUpsertInput{
+		BucketName:        bucketName,
+		ObjectName:        objectName,
+		StoragePath:       s.newDataKey(objectName),
+		ContentType:       version.ContentType,
+		ContentTypeSource: version.ContentTypeSource,
+		Size:              version.Size,
+		Metadata:          metadata,
+		ChecksumSHA256:    version.ChecksumSha256,
+		ChecksumCRC32C:    version.ChecksumCrc32c,
+	}
+	bypass := governanceBypass(ctx.Request())
+	check := func(otx repository.ObjectTx) error {
+		return checkOverwrite(otx, bypass)
+	}
+
+	// content-addressable 버킷은 버전 본문의 blob을 다시 가리키고, 아니면 버전 복사본을 새 본문 키로 복사합니다.
+	src := minio.CopySrcOptions{Bucket: bucketName, Object: version.StoragePath}
+	shared, err := s.shareBlob(reqCtx, &objectHead{StorageKey: version.StoragePath, ChecksumSHA256: version.ChecksumSha256}, &record)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("restore failed: %v", err)})
+		return
+	}
+	if !shared {
+		uinfo, err := s.copyStorage(reqCtx, minio.CopyDestOptions{Bucket: bucketName, Object: record.StoragePath}, src, record.Size)
+		if err != nil {
+			ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("restore failed: %v", err)})
+			return
+		}
+		record.ETag = uinfo.ETag
+		src = minio.CopySrcOptions{}
+	}
+	newVersionID, err := s.commitObject(reqCtx, &record, src, check)
+	if err != nil {
+		if errors.Is(err, errObjectLocked) {
+			ctx.JSON(http.StatusForbidden, ErrorResponse{Error: err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, UploadObjectResponse{
+		Bucket:            bucketName,
+		Object:            objectName,
+		ContentType:       record.ContentType,
+		ContentTypeSource: record.ContentTypeSource,
+		Size:              record.Size,
+		ETag:              record.ETag,
+		StoragePath:       record.StoragePath,
+		VersionID:         newVersionID,
+		ChecksumSHA256:    record.ChecksumSHA256,
+		ChecksumCRC32C:    record.ChecksumCRC32C,
+	})
+}
+
+// bucketConfigTarget은 버킷 설정 API(버전 관리, Content-Type 정책)의 공통 검증(버킷 이름, repository, 버킷 존재)을 처리합니다.
+// feature는 repository가 없을 때의 501 메시지에 들어갑니다.
+func (s *StorageService) bucketConfigTarget(ctx httpctx.Context, feature string) (string, bool) {
+	bucketName := strings.TrimSpace(ctx.Param("bucketName"))
+	if err := validateBucketName(bucketName); err != nil {
+		ctx.JSON(http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return "", false
+	}
+	if s.repo == nil {
+		ctx.JSON(http.StatusNotImplemented, ErrorResponse{Error: feature + " requires object repository"})
+		return "", false
+	}
+
+	exists, err := s.client.BucketExists(ctx.Context(), bucketName)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("check bucket failed: %v", err)})
+		return "", false
+	}
+	if !exists {
+		ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "bucket not found"})
+		return "", false
+	}
+	return bucketName, true
+}
+
+// prepareVersion은 버전 관리 중인 버킷이면 src 본문을 새 버전 복사본으로 복사하고, 키를 잠근 뒤 saveVersion으로 기록할 버전 행을 반환합니다.
+// 복사본 경로는 쓰기마다 고유하므로 키를 잠그기 전에 복사해도 다른 쓰기의 본문과 섞이지 않습니다.
+// 버전 관리를 켠 적 없는 버킷이면 nil을 반환합니다.
+func (s *StorageService) prepareVersion(ctx context.Context, in repository.ObjectUpsertInput, src minio.CopySrcOptions) (*repository.ObjectVersionInput, error) {
+	versionID, err := s.nextVersionID(ctx, in.BucketName)
+	if err != nil || versionID == "" {
+		return nil, err
+	}
+
+	// null 버전은 같은 ID를 계속 대체하므로 복사본 경로만 쓰기마다 새로 만들고, 대체된 복사본은 커밋한 뒤 지웁니다.
+	pathID := versionID
+	if versionID == nullVersionID {
+		pathID = nullVersionID + "-" + newRandomID()
+	}
+	versionPath := versionStoragePath(pathID, encodeObjectKey(in.ObjectName))
+	if _, err := s.copyStorage(ctx, minio.CopyDestOptions{Bucket: in.BucketName, Object: versionPath}, src, in.Size); err != nil {
+		return nil, fmt.Errorf("copy version failed: %w", err)
+	}
+	return &repository.ObjectVersionInput{
+		VersionID:         versionID,
+		BucketName:        in.BucketName,
+		ObjectName:        in.ObjectName,
+		StoragePath:       versionPath,
+		ContentType:       in.ContentType,
+		ContentTypeSource: in.ContentTypeSource,
+		Size:              in.Size,
+		ETag:              in.ETag,
+		Metadata:          in.Metadata,
+		ChecksumSHA256:    in.ChecksumSHA256,
+		ChecksumCRC32C:    in.ChecksumCRC32C,
+	}, nil
+}
+
+// saveVersion은 otx가 잠근 키의 버전 이력에 v를 기록하고, null 버전을 대체했으면 이전 복사본 경로를 반환합니다.
+// 이전 복사본은 커밋한 뒤 호출자가 지웁니다.
+func (s *StorageService) saveVersion(ctx context.Context, otx repository.ObjectTx, v repository.ObjectVersionInput) (string, error) {
+	replaced, err := s.nullVersionPath(ctx, v.BucketName, v.ObjectName, v.VersionID)
+	if err != nil {
+		return "", err
+	}
+	if _, err := otx.CreateVersion(ctx, v); err != nil {
+		return "", fmt.Errorf("save version failed: %w", err)
+	}
+	return replaced, nil
+}
+
+// nullVersionPath는 versionID가 null 버전이면 지금 기록된 null 버전의 복사본 경로를 반환합니다. 키를 잠근 채 호출해야 합니다.
+func (s *StorageService) nullVersionPath(ctx context.Context, bucketName, objectName, versionID string) (string, error) {
+	if versionID != nullVersionID {
+		return "", nil
+	}
+	previous, err := s.repo.GetObjectVersion(ctx, bucketName, objectName, nullVersionID)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			return "", nil
+		}
+		return "", fmt.Errorf("get version failed: %w", err)
+	}
+	return previous.StoragePath, nil
+}
+
+// recordVersion은 otx에서 방금 기록한 현재 객체를 같은 트랜잭션의 버전 이력에 남기고 버전 ID를 반환합니다.
+// 키를 잠근 채 본문을 복사하므로 새 본문을 올리지 않는 쓰기(휴지통 복원)에만 씁니다.
+// 버전 관리를 켠 적 없는 버킷이면 아무것도 하지 않고 빈 문자열을 반환합니다.
+func (s *StorageService) recordVersion(ctx context.Context, otx repository.ObjectTx, in repository.ObjectUpsertInput) (string, error) {
+	v, err := s.prepareVersion(ctx, in, minio.CopySrcOptions{Bucket: storageBucket(in.BucketName, in.StoragePath), Object: in.StoragePath})
+	if err != nil || v == nil {
+		return "", err
+	}
+	replaced, err := s.saveVersion(ctx, otx, *v)
+	if err != nil {
+		_ = s.client.RemoveObject(ctx, in.BucketName, v.StoragePath, minio.RemoveObjectOptions{})
+		return "", err
+	}
+	if replaced != "" {
+		_ = s.client.RemoveObject(ctx, in.BucketName, replaced, minio.RemoveObjectOptions{})
+	}
+	return v.VersionID, nil
+}
+
+// recordDeleteMarker는 otx에서 삭제한 객체 위에 같은 트랜잭션으로 삭제 마커 버전을 쌓습니다.
+func (s *StorageService) recordDeleteMarker(ctx context.Context, otx repository.ObjectTx, bucketName, objectName string) error {
+	versionID, err := s.nextVersionID(ctx, bucketName)
+	if err != nil || versionID == "" {
+		return err
+	}
+
+	// 일시 중지 상태의 삭제는 기존 null 버전을 대체하므로 그 복사본도 지웁니다.
+	replaced, err := s.nullVersionPath(ctx, bucketName, objectName, versionID)
+	if err != nil {
+		return err
+	}
+	if replaced != "" {
+		_ = s.client.RemoveObject(ctx, bucketName, replaced, minio.RemoveObjectOptions{})
+	}
+
+	if _, err := otx.CreateVersion(ctx, repository.ObjectVersionInput{
+		VersionID:      versionID,
+		BucketName:     bucketName,
+		ObjectName:     objectName,
+		IsDeleteMarker: true,
+	}); err != nil {
+		return fmt.Errorf("save delete marker failed: %w", err)
+	}
+	return nil
+}
+
+// nextVersionID는 버킷 상태에 따라 새 버전 ID, null 버전 ID, 또는 빈 문자열(미사용)을 반환합니다.
+func (s *StorageService) nextVersionID(ctx context.Context, bucketName string) (string, error) {
+	if s.repo == nil {
+		return "", nil
+	}
+	status, err := s.repo.GetBucketVersioning(ctx, bucketName)
+	if err != nil {
+		return "", fmt.Errorf("get versioning failed: %w", err)
+	}
+	switch status {
+	case versioningEnabled:
+		return newRandomID(), nil
+	case versioningSuspended:
+		return nullVersionID, nil
+	default:
+		return "", nil
+	}
+}
+
+// lookupObjectVersion은 ?versionId= 요청을 위해 버전 행을 objectHead로 변환합니다.
+func (s *StorageService) lookupObjectVersion(ctx httpctx.Context, bucketName, objectName, versionID string) (*objectHead, bool) {
+	if s.repo == nil {
+		ctx.JSON(http.StatusNotImplemented, ErrorResponse{Error: "versioning requires object repository"})
+		return nil, false
+	}
+
+	version, err := s.repo.GetObjectVersion(ctx.Context(), bucketName, objectName, versionID)
+	if err != nil {
+		if ent.IsNotFound(err) {
+			ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "version not found"})
+			return nil, false
+		}
+		ctx.JSON(http.StatusInternalServerError, ErrorResponse{Error: fmt.Sprintf("get version failed: %v", err)})
+		return nil, false
+	}
+	if version.IsDeleteMarker {
+		ctx.SetHeader("X-Guiio-Delete-Marker", "true")
+		ctx.JSON(http.StatusNotFound, ErrorResponse{Error: "version is a delete marker"})
+		return nil, false
+	}
+
+	metadata := version.Metadata
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	return &objectHead{
+		StorageKey:        version.StoragePath,
+		ContentType:       version.ContentType,
+		ContentTypeSource: version.ContentTypeSource,
+		Size:              version.Size,
+		ETag:              version.Etag,
+		LastModified:      version.CreatedAt,
+		Metadata:          metadata,
+		VersionID:         version.VersionID,
+		ChecksumSHA256:    version.ChecksumSha256,
+		ChecksumCRC32C:    version.ChecksumCrc32c,
+	}, true
+}
+
+func versionStoragePath(versionID, storageKey string) string {
+	return versionKeyPrefix + versionID + "/" + storageKey
+}
@@ -6,7 +6,6 @@ import (
 
 	"guiio/backend/internal/middleware"
 	httpctx "guiio/backend/internal/port/httpctx"
-	"guiio/backend/internal/repository"
 	"guiio/backend/internal/service"
 
 	"github.com/go-chi/chi/v5"
@@ -23,18 +22,12 @@ type HttpHandler struct {
 	bucketService service.BucketService
 }
 
-func NewHttpHandler(conf *config.GConfig, log *zerolog.Logger, repo repository.ObjectRepository) (*HttpHandler, error) {
-	//Todo 밖으로 빼기
-	bucketService, err := service.NewStorageService(repo)
-	if err != nil {
-		return nil, err
-	}
-
+func NewHttpHandler(conf *config.GConfig, log *zerolog.Logger, bucketService service.BucketService) *HttpHandler {
 	return &HttpHandler{
 		conf:          conf,
 		log:           log,
 		bucketService: bucketService,
-	}, nil
+	}
 }
 
 func (h *HttpHandler) Start() error {
@@ -58,9 +51,55 @@ func (h *HttpHandler) Start() error {
 		r.Post("/", h.CreateBucket)
 		r.Get("/{bucketName}", h.GetBucket)
 		r.Delete("/{bucketName}", h.DeleteBucket)
+		r.Get("/{bucketName}/versioning", h.GetBucketVersioning)
+		r.Put("/{bucketName}/versioning", h.PutBucketVersioning)
+		r.Get("/{bucketName}/content-type-policy", h.GetBucketContentTypePolicy)
+		r.Put("/{bucketName}/content-type-policy", h.PutBucketContentTypePolicy)
+		r.Get("/{bucketName}/content-addressing", h.GetBucketContentAddressing)
+		r.Put("/{bucketName}/content-addressing", h.PutBucketContentAddressing)
+		r.Get("/{bucketName}/object-lock", h.GetBucketObjectLock)
+		r.Put("/{bucketName}/object-lock", h.PutBucketObjectLock)
+		r.Get("/{bucketName}/lifecycle", h.GetBucketLifecycle)
+		r.Put("/{bucketName}/lifecycle", h.PutBucketLifecycle)
+		r.Delete("/{bucketName}/lifecycle", h.DeleteBucketLifecycle)
+		r.Get("/{bucketName}/lifecycle/events", h.ListLifecycleEvents)
+		r.Get("/{bucketName}/objects", h.ListObjects)
 		r.Post("/{bucketName}/objects", h.UploadObject)
+		r.Post("/{bucketName}/objects:delete", h.DeleteObjects)
 		r.Get("/{bucketName}/objects/{objectName}", h.DownloadObject)
+		r.Head("/{bucketName}/objects/{objectName}", h.HeadObject)
+		r.Post("/{bucketName}/objects/{objectName}:sign", h.SignDownload)
+		r.Put("/{bucketName}/objects/{objectName}", h.PutObject)
+		r.Post("/{bucketName}/objects/{objectName}:copy", h.CopyObject)
+		r.Post("/{bucketName}/objects/{objectName}:move", h.MoveObject)
+		r.Patch("/{bucketName}/objects/{objectName}/metadata", h.UpdateObjectMetadata)
+		r.Get("/{bucketName}/objects/{objectName}/tagging", h.GetObjectTagging)
+		r.Put("/{bucketName}/objects/{objectName}/tagging", h.PutObjectTagging)
+		r.Delete("/{bucketName}/objects/{objectName}/tagging", h.DeleteObjectTagging)
+		r.Get("/{bucketName}/objects/{objectName}/retention", h.GetObjectRetention)
+		r.Put("/{bucketName}/objects/{objectName}/retention", h.PutObjectRetention)
+		r.Get("/{bucketName}/objects/{objectName}/legal-hold", h.GetObjectLegalHold)
+		r.Put("/{bucketName}/objects/{objectName}/legal-hold", h.PutObjectLegalHold)
+		r.Get("/{bucketName}/objects/{objectName}/versions", h.ListObjectVersions)
+		r.Post("/{bucketName}/objects/{objectName}/versions/{versionId}:restore", h.RestoreObjectVersion)
+		r.Get("/{bucketName}/search", h.SearchObjects)
+		r.Get("/{bucketName}/archive", h.DownloadArchive)
+		r.Get("/{bucketName}/trash", h.ListTrash)
+		r.Delete("/{bucketName}/trash", h.EmptyTrash)
+		r.Post("/{bucketName}/trash/{objectName}:restore", h.RestoreTrashedObject)
+		r.Post("/{bucketName}/uploads", h.InitiateMultipartUpload)
+		r.Get("/{bucketName}/uploads/{uploadId}/parts", h.ListParts)
+		r.Put("/{bucketName}/uploads/{uploadId}/parts/{partNumber}", h.UploadPart)
+		r.Post("/{bucketName}/uploads/{uploadId}:complete", h.CompleteMultipartUpload)
+		r.Delete("/{bucketName}/uploads/{uploadId}", h.AbortMultipartUpload)
+		r.Options("/{bucketName}/tus", h.TusOptions)
+		r.Post("/{bucketName}/tus", h.CreateTusUpload)
+		r.Head("/{bucketName}/tus/{uploadId}", h.HeadTusUpload)
+		r.Patch("/{bucketName}/tus/{uploadId}", h.PatchTusUpload)
+		r.Delete("/{bucketName}/tus/{uploadId}", h.TerminateTusUpload)
+		r.Delete("/{bucketName}/objects/{objectName}", h.DeleteObject)
 	})
+	router.Get("/api/v1/search", h.SearchObjects)
 
 	return http.ListenAndServe(fmt.Sprintf(":%d", port), router)
 }
@@ -131,16 +170,24 @@ func (h *HttpHandler) DeleteBucket(w http.ResponseWriter, r *http.Request) {
 // UploadObject godoc
 // @Summary 객체 업로드
 // @Description 멀티파트 파일을 업로드하고 메타데이터와 함께 저장합니다.
+// @Description 파일 파트에 Content-Type이 없거나 application/octet-stream이면 버킷의 Content-Type 정책에 따라 본문 앞부분과 확장자로 판별합니다.
+// @Description extract=true이면 zip/tar/tar.gz 파일을 풀어 각 항목을 prefix 아래 객체로 저장하고 항목별 결과(service.ExtractArchiveResponse)를 반환합니다.
+// @Description 항목 수(extract_max_entries)나 압축 해제 크기 합계(extract_max_size)가 제한을 넘으면 아무것도 저장하지 않고 413을 반환합니다.
 // @Tags buckets
 // @Accept multipart/form-data
 // @Produce json
 // @Param bucketName path string true "버킷 이름"
+// @Param extract query bool false "아카이브를 풀어서 저장"
 // @Param file formData file true "업로드 파일"
 // @Param objectName formData string false "저장할 객체 이름"
+// @Param prefix formData string false "아카이브를 풀 접두사 (extract=true일 때)"
+// @Param X-Guiio-Checksum-Sha256 header string false "파일의 SHA-256 (base64). 다르면 400"
+// @Param X-Guiio-Checksum-Crc32c header string false "파일의 CRC32C (base64). 다르면 400"
 // @Param meta-xxx formData string false "메타데이터 (meta- 접두사 사용)"
 // @Success 201 {object} service.UploadObjectResponse
 // @Failure 400 {object} service.ErrorResponse
 // @Failure 404 {object} service.ErrorResponse
+// @Failure 413 {object} service.ErrorResponse
 // @Failure 500 {object} service.ErrorResponse
 // @Router /api/v1/buckets/{bucketName}/objects [post]
 func (h *HttpHandler) UploadObject(w http.ResponseWriter, r *http.Request) {
@@ -150,18 +197,881 @@ func (h *HttpHandler) UploadObject(w http.ResponseWriter, r *http.Request) {
 
 // DownloadObject godoc
 // @Summary 객체 다운로드
-// @Description 버킷의 객체를 스트리밍으로 반환합니다.
+// @Description 버킷의 객체를 스트리밍으로 반환합니다. stat 쿼리가 있으면 객체 속성을 JSON으로 반환합니다.
+// @Description 업로드 때 계산한 체크섬이 있으면 X-Guiio-Checksum-Sha256, X-Guiio-Checksum-Crc32c 헤더(base64)로 함께 보냅니다.
+// @Description w, h, fit, format 쿼리가 있으면 이미지를 리사이즈한 파생 이미지를 반환합니다. 파생 이미지는 숨김 객체로 캐시하며 자체 ETag를 가집니다. w, h 중 하나는 반드시 있어야 합니다.
+// @Description 압축한 응답은 Content-Length 없이 보내고 ETag를 약한 ETag(W/)로 바꿉니다.
+// @Description response-* 쿼리는 저장된 속성을 바꾸지 않고 이번 응답 헤더만 바꿉니다. 한글 파일 이름은 RFC 6266 filename*(UTF-8)로 인코딩합니다.
+// @Description response-* 쿼리는 :sign으로 서명한 링크(expires, signature 쿼리 포함)에서만 받고, 서명이 없거나 만료되면 403을 반환합니다.
 // @Tags buckets
 // @Produce octet-stream
 // @Param bucketName path string true "버킷 이름"
 // @Param objectName path string true "객체 이름"
+// @Param stat query string false "객체 속성만 JSON으로 조회"
+// @Param Range header string false "바이트 구간 (예: bytes=0-1023)"
+// @Param If-Range header string false "ETag 또는 Last-Modified가 같을 때만 Range 적용"
+// @Param w query int false "리사이즈 가로 크기 (image_resize_sizes 중 하나)"
+// @Param h query int false "리사이즈 세로 크기 (image_resize_sizes 중 하나)"
+// @Param fit query string false "contain(기본), cover, fill"
+// @Param format query string false "webp, jpeg, png (기본: 원본 형식)"
+// @Param Accept-Encoding header string false "zstd, br, gzip. download_compression_types에 맞는 객체만 압축하며 Range 요청은 압축하지 않음"
+// @Param response-content-type query string false "이번 응답의 Content-Type"
+// @Param response-content-disposition query string false "이번 응답의 Content-Disposition (inline 또는 attachment; filename=...)"
+// @Param response-cache-control query string false "이번 응답의 Cache-Control"
+// @Param response-expires query string false "이번 응답의 Expires (HTTP 날짜)"
+// @Param expires query int false "서명 만료 시각 (Unix 초)"
+// @Param signature query string false "response-* 쿼리 서명"
 // @Success 200 {file} binary
+// @Success 206 {file} binary "Partial Content"
 // @Success 304 {string} string "Not Modified"
 // @Failure 400 {object} service.ErrorResponse
+// @Failure 403 {object} service.ErrorResponse
 // @Failure 404 {object} service.ErrorResponse
+// @Failure 413 {object} service.ErrorResponse
+// @Failure 416 {object} service.ErrorResponse
 // @Failure 500 {object} service.ErrorResponse
 // @Router /api/v1/buckets/{bucketName}/objects/{objectName} [get]
 func (h *HttpHandler) DownloadObject(w http.ResponseWriter, r *http.Request) {
 	ctx := httpctx.NewChiContext(w, r)
 	h.bucketService.DownloadObject(ctx)
 }
+
+// ListObjects godoc
+// @Summary 객체 목록 조회
+// @Description 접두사/구분자로 객체와 공통 접두사(폴더)를 조회합니다. cursor로 다음 페이지를 이어서 조회합니다.
+// @Tags buckets
+// @Produce json
+// @Param bucketName path string true "버킷 이름"
+// @Param prefix query string false "객체 이름 접두사"
+// @Param delimiter query string false "폴더 구분자 (예: /)"
+// @Param limit query int false "최대 항목 수 (기본 100, 최대 1000)"
+// @Param cursor query string false "이전 응답의 next_cursor"
+// @Param tag query string false "태그 필터 (key:value)"
+// @Success 200 {object} service.ListObjectsResponse
+// @Failure 400 {object} service.ErrorResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/objects [get]
+func (h *HttpHandler) ListObjects(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.ListObjects(ctx)
+}
+
+// DeleteObject godoc
+// @Summary 객체 삭제
+// @Description 객체를 휴지통으로 옮깁니다. 휴지통을 쓰지 않도록 설정했거나 메타데이터 행이 없는 객체는 바로 삭제합니다.
+// @Description Object Lock으로 잠긴 객체는 403입니다. GOVERNANCE 보존은 X-Guiio-Bypass-Governance-Retention 헤더로 우회할 수 있습니다.
+// @Tags buckets
+// @Produce json
+// @Param bucketName path string true "버킷 이름"
+// @Param objectName path string true "객체 이름"
+// @Param X-Guiio-Bypass-Governance-Retention header string false "GOVERNANCE 보존 우회 토큰"
+// @Success 200 {object} service.DeleteObjectResponse
+// @Failure 400 {object} service.ErrorResponse
+// @Failure 403 {object} service.ErrorResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/objects/{objectName} [delete]
+func (h *HttpHandler) DeleteObject(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.DeleteObject(ctx)
+}
+
+// DeleteObjects godoc
+// @Summary 객체 일괄 삭제
+// @Description 최대 1000개의 키를 받아 삭제하고 키별 결과를 반환합니다.
+// @Tags buckets
+// @Accept json
+// @Produce json
+// @Param bucketName path string true "버킷 이름"
+// @Param request body service.DeleteObjectsRequest true "삭제할 키 목록"
+// @Success 200 {object} service.DeleteObjectsResponse
+// @Failure 400 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/objects:delete [post]
+func (h *HttpHandler) DeleteObjects(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.DeleteObjects(ctx)
+}
+
+// HeadObject godoc
+// @Summary 객체 속성 조회 (HEAD)
+// @Description 본문 없이 Content-Type, Content-Length, ETag, Last-Modified와 X-Guiio-Meta-* 메타데이터 헤더를 반환합니다.
+// @Tags buckets
+// @Param bucketName path string true "버킷 이름"
+// @Param objectName path string true "객체 이름"
+// @Param response-content-type query string false "이번 응답의 Content-Type"
+// @Param response-content-disposition query string false "이번 응답의 Content-Disposition (inline 또는 attachment; filename=...)"
+// @Param response-cache-control query string false "이번 응답의 Cache-Control"
+// @Param response-expires query string false "이번 응답의 Expires (HTTP 날짜)"
+// @Param expires query int false "서명 만료 시각 (Unix 초)"
+// @Param signature query string false "response-* 쿼리 서명"
+// @Success 200 {string} string "OK"
+// @Success 304 {string} string "Not Modified"
+// @Failure 400 {object} service.ErrorResponse
+// @Failure 403 {object} service.ErrorResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/objects/{objectName} [head]
+func (h *HttpHandler) HeadObject(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.HeadObject(ctx)
+}
+
+// PutObject godoc
+// @Summary 객체 업로드 (raw body)
+// @Description 요청 본문을 메모리에 모으지 않고 그대로 저장합니다. Transfer-Encoding: chunked도 지원합니다.
+// @Tags buckets
+// @Accept octet-stream
+// @Produce json
+// @Param bucketName path string true "버킷 이름"
+// @Param objectName path string true "객체 이름"
+// @Param Content-Type header string false "객체 Content-Type. 없으면 버킷의 Content-Type 정책에 따라 판별"
+// @Param X-Guiio-Meta-xxx header string false "메타데이터 (X-Guiio-Meta- 접두사 사용)"
+// @Param Content-MD5 header string false "본문의 MD5 (base64). 다르면 400"
+// @Param X-Guiio-Checksum-Sha256 header string false "본문의 SHA-256 (base64). 다르면 400"
+// @Param X-Guiio-Checksum-Crc32c header string false "본문의 CRC32C (base64). 다르면 400"
+// @Success 201 {object} service.UploadObjectResponse
+// @Failure 400 {object} service.ErrorResponse
+// @Failure 403 {object} service.ErrorResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 413 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/objects/{objectName} [put]
+func (h *HttpHandler) PutObject(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.PutObject(ctx)
+}
+
+// SignDownload godoc
+// @Summary response-* 다운로드 링크 서명
+// @Description response-* 값을 검증한 뒤 response_override_secret으로 서명한 다운로드 경로를 반환합니다. 비밀 키가 설정되지 않았으면 403을 반환합니다.
+// @Tags buckets
+// @Accept json
+// @Produce json
+// @Param bucketName path string true "버킷 이름"
+// @Param objectName path string true "객체 이름"
+// @Param request body service.SignDownloadRequest true "서명할 response-* 값과 유효 기간"
+// @Success 200 {object} service.SignDownloadResponse
+// @Failure 400 {object} service.ErrorResponse
+// @Failure 403 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/objects/{objectName}:sign [post]
+func (h *HttpHandler) SignDownload(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.SignDownload(ctx)
+}
+
+// CopyObject godoc
+// @Summary 객체 복사
+// @Description 스토리지 백엔드 안에서 객체를 복사합니다. 다른 버킷으로도 복사할 수 있고, metadata_directive=REPLACE이면 메타데이터를 요청 값으로 대체합니다.
+// @Tags buckets
+// @Accept json
+// @Produce json
+// @Param bucketName path string true "원본 버킷 이름"
+// @Param objectName path string true "원본 객체 이름"
+// @Param request body service.CopyObjectRequest true "복사 대상"
+// @Success 200 {object} service.CopyObjectResponse
+// @Failure 400 {object} service.ErrorResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/objects/{objectName}:copy [post]
+func (h *HttpHandler) CopyObject(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.CopyObject(ctx)
+}
+
+// MoveObject godoc
+// @Summary 객체 이동
+// @Description 객체를 대상 위치로 복사한 뒤 원본을 삭제합니다. 요청 형식은 복사와 같습니다.
+// @Description 원본과 대상 키를 함께 잠근 채 원본을 지울 수 있는지 확인하므로, 원본이 잠겨 있으면(403) 대상을 만들지 않습니다.
+// @Description 복사하는 동안 원본이 바뀌었으면 409를 반환합니다.
+// @Tags buckets
+// @Accept json
+// @Produce json
+// @Param bucketName path string true "원본 버킷 이름"
+// @Param objectName path string true "원본 객체 이름"
+// @Param request body service.CopyObjectRequest true "이동 대상"
+// @Success 200 {object} service.CopyObjectResponse
+// @Failure 400 {object} service.ErrorResponse
+// @Failure 403 {object} service.ErrorResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 409 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/objects/{objectName}:move [post]
+func (h *HttpHandler) MoveObject(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.MoveObject(ctx)
+}
+
+// UpdateObjectMetadata godoc
+// @Summary 객체 메타데이터 변경
+// @Description 콘텐츠를 다시 올리지 않고 메타데이터, Content-Type, Cache-Control을 바꿉니다. mode=merge(기본값)는 지정한 키만 바꾸고 null 값은 삭제하며, mode=replace는 전체를 대체합니다.
+// @Description Object Lock으로 잠긴 객체는 403입니다.
+// @Tags buckets
+// @Accept json
+// @Produce json
+// @Param bucketName path string true "버킷 이름"
+// @Param objectName path string true "객체 이름"
+// @Param request body service.UpdateObjectMetadataRequest true "변경할 메타데이터"
+// @Param X-Guiio-Bypass-Governance-Retention header string false "GOVERNANCE 보존 우회 토큰"
+// @Success 200 {object} service.ObjectStatResponse
+// @Failure 400 {object} service.ErrorResponse
+// @Failure 403 {object} service.ErrorResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/objects/{objectName}/metadata [patch]
+func (h *HttpHandler) UpdateObjectMetadata(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.UpdateObjectMetadata(ctx)
+}
+
+// GetObjectTagging godoc
+// @Summary 객체 태그 조회
+// @Description 객체에 붙은 태그를 반환합니다. 태그는 업로드 메타데이터와 별도로 관리됩니다.
+// @Tags tagging
+// @Produce json
+// @Param bucketName path string true "버킷 이름"
+// @Param objectName path string true "객체 이름"
+// @Success 200 {object} service.ObjectTaggingResponse
+// @Failure 400 {object} service.ErrorResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/objects/{objectName}/tagging [get]
+func (h *HttpHandler) GetObjectTagging(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.GetObjectTagging(ctx)
+}
+
+// PutObjectTagging godoc
+// @Summary 객체 태그 설정
+// @Description 객체의 태그 전체를 대체합니다. 최대 10개, 키 128자, 값 256자까지 허용합니다.
+// @Tags tagging
+// @Accept json
+// @Produce json
+// @Param bucketName path string true "버킷 이름"
+// @Param objectName path string true "객체 이름"
+// @Param request body service.ObjectTaggingRequest true "설정할 태그"
+// @Success 200 {object} service.ObjectTaggingResponse
+// @Failure 400 {object} service.ErrorResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/objects/{objectName}/tagging [put]
+func (h *HttpHandler) PutObjectTagging(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.PutObjectTagging(ctx)
+}
+
+// DeleteObjectTagging godoc
+// @Summary 객체 태그 삭제
+// @Description 객체의 태그를 모두 지웁니다.
+// @Tags tagging
+// @Produce json
+// @Param bucketName path string true "버킷 이름"
+// @Param objectName path string true "객체 이름"
+// @Success 200 {object} service.ObjectTaggingResponse
+// @Failure 400 {object} service.ErrorResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/objects/{objectName}/tagging [delete]
+func (h *HttpHandler) DeleteObjectTagging(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.DeleteObjectTagging(ctx)
+}
+
+// GetObjectRetention godoc
+// @Summary 객체 보존 설정 조회
+// @Description 객체의 보존 모드, 보존 기한과 legal hold 여부를 반환합니다.
+// @Tags objects
+// @Produce json
+// @Param bucketName path string true "버킷 이름"
+// @Param objectName path string true "객체 이름"
+// @Success 200 {object} service.ObjectLockResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/objects/{objectName}/retention [get]
+func (h *HttpHandler) GetObjectRetention(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.GetObjectRetention(ctx)
+}
+
+// PutObjectRetention godoc
+// @Summary 객체 보존 설정
+// @Description 보존 모드와 기한을 바꿉니다. 기한 연장과 GOVERNANCE에서 COMPLIANCE로의 변경은 언제나 허용합니다.
+// @Description 기한 단축이나 해제(mode, retain_until 모두 생략)는 GOVERNANCE 보존에 X-Guiio-Bypass-Governance-Retention 헤더가 맞을 때만 허용합니다.
+// @Tags objects
+// @Accept json
+// @Produce json
+// @Param bucketName path string true "버킷 이름"
+// @Param objectName path string true "객체 이름"
+// @Param X-Guiio-Bypass-Governance-Retention header string false "GOVERNANCE 보존 우회 토큰"
+// @Param request body service.ObjectRetentionRequest true "보존 설정"
+// @Success 200 {object} service.ObjectLockResponse
+// @Failure 400 {object} service.ErrorResponse
+// @Failure 403 {object} service.ErrorResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/objects/{objectName}/retention [put]
+func (h *HttpHandler) PutObjectRetention(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.PutObjectRetention(ctx)
+}
+
+// GetObjectLegalHold godoc
+// @Summary 객체 legal hold 조회
+// @Description 객체의 legal hold 여부를 보존 설정과 함께 반환합니다.
+// @Tags objects
+// @Produce json
+// @Param bucketName path string true "버킷 이름"
+// @Param objectName path string true "객체 이름"
+// @Success 200 {object} service.ObjectLockResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/objects/{objectName}/legal-hold [get]
+func (h *HttpHandler) GetObjectLegalHold(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.GetObjectLegalHold(ctx)
+}
+
+// PutObjectLegalHold godoc
+// @Summary 객체 legal hold 설정
+// @Description legal hold를 걸거나 풉니다. 걸려 있는 동안에는 보존 기한, 우회 권한과 상관없이 삭제, 덮어쓰기를 거부합니다.
+// @Tags objects
+// @Accept json
+// @Produce json
+// @Param bucketName path string true "버킷 이름"
+// @Param objectName path string true "객체 이름"
+// @Param request body service.ObjectLegalHoldRequest true "legal hold 여부"
+// @Success 200 {object} service.ObjectLockResponse
+// @Failure 400 {object} service.ErrorResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/objects/{objectName}/legal-hold [put]
+func (h *HttpHandler) PutObjectLegalHold(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.PutObjectLegalHold(ctx)
+}
+
+// GetBucketVersioning godoc
+// @Summary 버킷 버전 관리 상태 조회
+// @Description 버킷의 버전 관리 상태(Enabled, Suspended, 한 번도 켜지 않았으면 빈 문자열)를 반환합니다.
+// @Tags versions
+// @Produce json
+// @Param bucketName path string true "버킷 이름"
+// @Success 200 {object} service.BucketVersioningResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/versioning [get]
+func (h *HttpHandler) GetBucketVersioning(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.GetBucketVersioning(ctx)
+}
+
+// PutBucketVersioning godoc
+// @Summary 버킷 버전 관리 설정
+// @Description 버전 관리를 켜거나(Enabled) 일시 중지(Suspended)합니다. 일시 중지해도 기존 버전은 남습니다.
+// @Tags versions
+// @Accept json
+// @Produce json
+// @Param bucketName path string true "버킷 이름"
+// @Param request body service.BucketVersioningRequest true "버전 관리 상태"
+// @Success 200 {object} service.BucketVersioningResponse
+// @Failure 400 {object} service.ErrorResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/versioning [put]
+func (h *HttpHandler) PutBucketVersioning(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.PutBucketVersioning(ctx)
+}
+
+// GetBucketContentTypePolicy godoc
+// @Summary 버킷 Content-Type 정책 조회
+// @Description 업로드할 때 Content-Type을 정하는 정책(trust-client, sniff-if-missing, always-sniff)을 반환합니다. 설정하지 않은 버킷은 sniff-if-missing입니다.
+// @Tags buckets
+// @Produce json
+// @Param bucketName path string true "버킷 이름"
+// @Success 200 {object} service.BucketContentTypePolicyResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/content-type-policy [get]
+func (h *HttpHandler) GetBucketContentTypePolicy(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.GetBucketContentTypePolicy(ctx)
+}
+
+// PutBucketContentTypePolicy godoc
+// @Summary 버킷 Content-Type 정책 설정
+// @Description trust-client는 클라이언트 값을 그대로 쓰고, sniff-if-missing은 값이 없거나 application/octet-stream일 때만,
+// @Description always-sniff는 항상 본문 앞부분(시그니처)과 확장자로 판별합니다. 저장된 객체의 content_type_source에 declared/detected/default가 기록됩니다.
+// @Tags buckets
+// @Accept json
+// @Produce json
+// @Param bucketName path string true "버킷 이름"
+// @Param request body service.BucketContentTypePolicyRequest true "Content-Type 정책"
+// @Success 200 {object} service.BucketContentTypePolicyResponse
+// @Failure 400 {object} service.ErrorResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/content-type-policy [put]
+func (h *HttpHandler) PutBucketContentTypePolicy(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.PutBucketContentTypePolicy(ctx)
+}
+
+// GetBucketContentAddressing godoc
+// @Summary 버킷 content-addressable 저장 조회
+// @Description 업로드와 복사를 본문 SHA-256 blob에 저장해 중복을 없애는지 반환합니다. 설정하지 않은 버킷은 false입니다.
+// @Tags buckets
+// @Produce json
+// @Param bucketName path string true "버킷 이름"
+// @Success 200 {object} service.BucketContentAddressingResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/content-addressing [get]
+func (h *HttpHandler) GetBucketContentAddressing(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.GetBucketContentAddressing(ctx)
+}
+
+// PutBucketContentAddressing godoc
+// @Summary 버킷 content-addressable 저장 설정
+// @Description 켜면 이후 업로드와 복사는 같은 본문끼리 blob_bucket 버킷의 .guiio/blobs/<sha256> blob 하나를 함께 가리키고, 마지막 참조가 지워질 때 blob을 삭제합니다.
+// @Description 이미 저장한 객체는 옮기지 않습니다. blob은 서버 전체에서 공유하므로 다른 content-addressable 버킷의 같은 본문도 같은 blob을 가리킵니다.
+// @Tags buckets
+// @Accept json
+// @Produce json
+// @Param bucketName path string true "버킷 이름"
+// @Param request body service.BucketContentAddressingRequest true "content-addressable 저장 사용 여부"
+// @Success 200 {object} service.BucketContentAddressingResponse
+// @Failure 400 {object} service.ErrorResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/content-addressing [put]
+func (h *HttpHandler) PutBucketContentAddressing(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.PutBucketContentAddressing(ctx)
+}
+
+// GetBucketObjectLock godoc
+// @Summary 버킷 Object Lock 설정 조회
+// @Description Object Lock 사용 여부와 새 객체에 붙이는 기본 보존(모드, 일 수)을 반환합니다.
+// @Tags buckets
+// @Produce json
+// @Param bucketName path string true "버킷 이름"
+// @Success 200 {object} service.BucketObjectLockResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/object-lock [get]
+func (h *HttpHandler) GetBucketObjectLock(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.GetBucketObjectLock(ctx)
+}
+
+// PutBucketObjectLock godoc
+// @Summary 버킷 Object Lock 설정
+// @Description Object Lock(WORM)을 켜고 기본 보존(GOVERNANCE 또는 COMPLIANCE, 일 수)을 정합니다. 한 번 켜면 끌 수 없습니다.
+// @Description 잠긴 객체(보존 기한 전이거나 legal hold)는 삭제, 덮어쓰기가 403으로 거부됩니다.
+// @Tags buckets
+// @Accept json
+// @Produce json
+// @Param bucketName path string true "버킷 이름"
+// @Param request body service.BucketObjectLockRequest true "Object Lock 설정"
+// @Success 200 {object} service.BucketObjectLockResponse
+// @Failure 400 {object} service.ErrorResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/object-lock [put]
+func (h *HttpHandler) PutBucketObjectLock(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.PutBucketObjectLock(ctx)
+}
+
+// GetBucketLifecycle godoc
+// @Summary 버킷 수명 주기 규칙 조회
+// @Description 버킷에 저장된 수명 주기 규칙을 rule id 순으로 반환합니다. 규칙이 없으면 빈 목록입니다.
+// @Tags buckets
+// @Produce json
+// @Param bucketName path string true "버킷 이름"
+// @Success 200 {object} service.BucketLifecycleResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/lifecycle [get]
+func (h *HttpHandler) GetBucketLifecycle(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.GetBucketLifecycle(ctx)
+}
+
+// PutBucketLifecycle godoc
+// @Summary 버킷 수명 주기 규칙 설정
+// @Description 버킷의 규칙을 모두 요청 본문의 규칙으로 대체합니다. 규칙은 prefix, tags로 대상을 고르고
+// @Description 현재 객체 만료(expiration_days 또는 expiration_date), 이전 버전 만료, 미완료 멀티파트 업로드 중단을 설정합니다.
+// @Description 주기적인 평가가 일반 삭제 경로로 지우므로 휴지통, 삭제 마커, Object Lock이 그대로 적용됩니다.
+// @Tags buckets
+// @Accept json
+// @Produce json
+// @Param bucketName path string true "버킷 이름"
+// @Param request body service.BucketLifecycleRequest true "수명 주기 규칙"
+// @Success 200 {object} service.BucketLifecycleResponse
+// @Failure 400 {object} service.ErrorResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/lifecycle [put]
+func (h *HttpHandler) PutBucketLifecycle(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.PutBucketLifecycle(ctx)
+}
+
+// DeleteBucketLifecycle godoc
+// @Summary 버킷 수명 주기 규칙 삭제
+// @Description 버킷의 수명 주기 규칙을 모두 지웁니다. 평가 기록은 남습니다.
+// @Tags buckets
+// @Produce json
+// @Param bucketName path string true "버킷 이름"
+// @Success 200 {object} service.BucketLifecycleResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/lifecycle [delete]
+func (h *HttpHandler) DeleteBucketLifecycle(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.DeleteBucketLifecycle(ctx)
+}
+
+// ListLifecycleEvents godoc
+// @Summary 수명 주기 평가 기록 조회
+// @Description 수명 주기 규칙이 만료한 객체, 버전과 중단한 업로드를 최신 기록부터 조회합니다.
+// @Tags buckets
+// @Produce json
+// @Param bucketName path string true "버킷 이름"
+// @Param limit query int false "최대 항목 수 (기본 100, 최대 1000)"
+// @Param cursor query string false "이전 응답의 next_cursor"
+// @Success 200 {object} service.ListLifecycleEventsResponse
+// @Failure 400 {object} service.ErrorResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/lifecycle/events [get]
+func (h *HttpHandler) ListLifecycleEvents(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.ListLifecycleEvents(ctx)
+}
+
+// ListObjectVersions godoc
+// @Summary 객체 버전 목록
+// @Description 객체의 버전과 삭제 마커를 최신순으로 반환합니다. 특정 버전은 GET .../objects/{objectName}?versionId=로 받을 수 있습니다.
+// @Tags versions
+// @Produce json
+// @Param bucketName path string true "버킷 이름"
+// @Param objectName path string true "객체 이름"
+// @Success 200 {object} service.ListObjectVersionsResponse
+// @Failure 400 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/objects/{objectName}/versions [get]
+func (h *HttpHandler) ListObjectVersions(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.ListObjectVersions(ctx)
+}
+
+// RestoreObjectVersion godoc
+// @Summary 객체 버전 복원
+// @Description 지정한 버전을 현재 객체로 되돌리고, 복원 결과를 새 버전으로 기록합니다.
+// @Tags versions
+// @Produce json
+// @Param bucketName path string true "버킷 이름"
+// @Param objectName path string true "객체 이름"
+// @Param versionId path string true "복원할 버전 ID"
+// @Success 200 {object} service.UploadObjectResponse
+// @Failure 400 {object} service.ErrorResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/objects/{objectName}/versions/{versionId}:restore [post]
+func (h *HttpHandler) RestoreObjectVersion(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.RestoreObjectVersion(ctx)
+}
+
+// SearchObjects godoc
+// @Summary 객체 검색
+// @Description 메타데이터(일치/접두사), Content-Type, 크기, 생성/수정 시각, 이름 패턴으로 객체를 검색합니다. 버킷을 지정하지 않으면 모든 버킷을 검색합니다.
+// @Description q가 있으면 이름과 메타데이터 값을 전문 검색해 관련도 순으로 정렬하고, 일치한 필드를 highlights로 반환합니다.
+// @Tags search
+// @Produce json
+// @Param bucketName path string false "버킷 이름 (버킷 경로에서만)"
+// @Param bucket query string false "버킷 이름 (/search에서만)"
+// @Param q query string false "전문 검색어 (예: invoice 2024 march, \"exact phrase\", a OR b, -draft)"
+// @Param name query string false "객체 이름 glob (예: reports/*.pdf)"
+// @Param meta query []string false "메타데이터 일치 (key:value, 여러 번 지정 가능)" collectionFormat(multi)
+// @Param meta_prefix query []string false "메타데이터 값 접두사 (key:prefix, 여러 번 지정 가능)" collectionFormat(multi)
+// @Param content_type query string false "Content-Type (image/* 처럼 접두사 가능)"
+// @Param min_size query int false "최소 크기 (바이트)"
+// @Param max_size query int false "최대 크기 (바이트)"
+// @Param created_after query string false "생성 시각 하한 (RFC 3339)"
+// @Param created_before query string false "생성 시각 상한 (RFC 3339)"
+// @Param updated_after query string false "수정 시각 하한 (RFC 3339)"
+// @Param updated_before query string false "수정 시각 상한 (RFC 3339)"
+// @Param sort query string false "정렬 기준 (relevance, name, size, created, updated). q가 있으면 relevance가 기본값"
+// @Param order query string false "정렬 방향 (asc, desc)"
+// @Param limit query int false "최대 항목 수 (기본 100, 최대 1000)"
+// @Param cursor query string false "이전 응답의 next_cursor"
+// @Success 200 {object} service.SearchObjectsResponse
+// @Failure 400 {object} service.ErrorResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/search [get]
+// @Router /api/v1/buckets/{bucketName}/search [get]
+func (h *HttpHandler) SearchObjects(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.SearchObjects(ctx)
+}
+
+// DownloadArchive godoc
+// @Summary 접두사 아카이브 다운로드
+// @Description prefix로 시작하는 객체를 ZIP 또는 tar.gz로 묶어 스트리밍합니다. 항목 경로는 prefix의 마지막 '/' 이후 상대 경로입니다.
+// @Description 객체 수(archive_max_objects)나 크기 합계(archive_max_size)가 제한을 넘으면 413을 반환합니다.
+// @Tags objects
+// @Produce application/zip
+// @Produce application/gzip
+// @Param bucketName path string true "버킷 이름"
+// @Param prefix query string false "객체 이름 접두사"
+// @Param format query string false "아카이브 형식 (zip, tar.gz, 기본 zip)"
+// @Success 200 {file} file
+// @Failure 400 {object} service.ErrorResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 413 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/archive [get]
+func (h *HttpHandler) DownloadArchive(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.DownloadArchive(ctx)
+}
+
+// ListTrash godoc
+// @Summary 휴지통 목록 조회
+// @Description 삭제되어 휴지통에 있는 객체를 이름순으로 조회합니다. purge_at이 지나면 영구 삭제됩니다.
+// @Tags trash
+// @Produce json
+// @Param bucketName path string true "버킷 이름"
+// @Param prefix query string false "객체 이름 접두사"
+// @Param limit query int false "최대 항목 수 (기본 100, 최대 1000)"
+// @Param cursor query string false "이전 응답의 next_cursor"
+// @Success 200 {object} service.ListTrashResponse
+// @Failure 400 {object} service.ErrorResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/trash [get]
+func (h *HttpHandler) ListTrash(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.ListTrash(ctx)
+}
+
+// EmptyTrash godoc
+// @Summary 휴지통 비우기
+// @Description 보존 기간과 관계없이 휴지통의 객체를 모두 영구 삭제합니다.
+// @Tags trash
+// @Produce json
+// @Param bucketName path string true "버킷 이름"
+// @Success 200 {object} service.EmptyTrashResponse
+// @Failure 400 {object} service.ErrorResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/trash [delete]
+func (h *HttpHandler) EmptyTrash(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.EmptyTrash(ctx)
+}
+
+// RestoreTrashedObject godoc
+// @Summary 휴지통 객체 복원
+// @Description 휴지통의 객체를 원래 이름으로 되돌립니다. 휴지통으로 옮긴 뒤 같은 이름에 새 객체를 썼으면 409를 반환합니다.
+// @Tags trash
+// @Produce json
+// @Param bucketName path string true "버킷 이름"
+// @Param objectName path string true "객체 이름"
+// @Success 200 {object} service.ObjectStatResponse
+// @Failure 400 {object} service.ErrorResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 409 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/trash/{objectName}:restore [post]
+func (h *HttpHandler) RestoreTrashedObject(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.RestoreTrashedObject(ctx)
+}
+
+// InitiateMultipartUpload godoc
+// @Summary 멀티파트 업로드 시작
+// @Description 대용량 객체를 파트 단위로 올리기 위한 업로드 세션을 만듭니다.
+// @Tags uploads
+// @Accept json
+// @Produce json
+// @Param bucketName path string true "버킷 이름"
+// @Param request body service.InitiateMultipartUploadRequest true "업로드 대상 객체"
+// @Success 201 {object} service.MultipartUploadResponse
+// @Failure 400 {object} service.ErrorResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/uploads [post]
+func (h *HttpHandler) InitiateMultipartUpload(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.InitiateMultipartUpload(ctx)
+}
+
+// UploadPart godoc
+// @Summary 파트 업로드
+// @Description 요청 본문을 지정한 번호의 파트로 저장합니다. 같은 번호로 다시 올리면 덮어씁니다.
+// @Description 올린 파트 크기의 합이 object_max_upload_size를 넘으면 413입니다.
+// @Tags uploads
+// @Accept octet-stream
+// @Produce json
+// @Param bucketName path string true "버킷 이름"
+// @Param uploadId path string true "업로드 ID"
+// @Param partNumber path int true "파트 번호 (1-10000)"
+// @Param Content-MD5 header string false "파트 본문의 MD5 (base64)"
+// @Param X-Guiio-Checksum-Sha256 header string false "파트 본문의 SHA-256 (base64)"
+// @Param X-Guiio-Checksum-Crc32c header string false "파트 본문의 CRC32C (base64)"
+// @Success 200 {object} service.UploadPartResponse
+// @Failure 400 {object} service.ErrorResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 411 {object} service.ErrorResponse
+// @Failure 413 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/uploads/{uploadId}/parts/{partNumber} [put]
+func (h *HttpHandler) UploadPart(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.UploadPart(ctx)
+}
+
+// ListParts godoc
+// @Summary 업로드된 파트 목록
+// @Tags uploads
+// @Produce json
+// @Param bucketName path string true "버킷 이름"
+// @Param uploadId path string true "업로드 ID"
+// @Success 200 {object} service.ListPartsResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/uploads/{uploadId}/parts [get]
+func (h *HttpHandler) ListParts(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.ListParts(ctx)
+}
+
+// CompleteMultipartUpload godoc
+// @Summary 멀티파트 업로드 완료
+// @Description 파트를 번호 순으로 합쳐 객체를 만듭니다. parts를 생략하면 업로드된 모든 파트를 사용합니다.
+// @Tags uploads
+// @Accept json
+// @Produce json
+// @Param bucketName path string true "버킷 이름"
+// @Param uploadId path string true "업로드 ID"
+// @Param request body service.CompleteMultipartUploadRequest false "합칠 파트 목록"
+// @Success 200 {object} service.UploadObjectResponse
+// @Failure 400 {object} service.ErrorResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 413 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/uploads/{uploadId}:complete [post]
+func (h *HttpHandler) CompleteMultipartUpload(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.CompleteMultipartUpload(ctx)
+}
+
+// AbortMultipartUpload godoc
+// @Summary 멀티파트 업로드 중단
+// @Description 업로드 세션과 지금까지 올라간 파트를 버립니다.
+// @Tags uploads
+// @Produce json
+// @Param bucketName path string true "버킷 이름"
+// @Param uploadId path string true "업로드 ID"
+// @Success 200 {object} service.AbortMultipartUploadResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/uploads/{uploadId} [delete]
+func (h *HttpHandler) AbortMultipartUpload(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.AbortMultipartUpload(ctx)
+}
+
+// TusOptions godoc
+// @Summary tus 서버 기능 조회
+// @Description 지원하는 tus 버전(Tus-Version), 확장(Tus-Extension), 최대 크기(Tus-Max-Size)를 헤더로 반환합니다.
+// @Tags tus
+// @Param bucketName path string true "버킷 이름"
+// @Success 204
+// @Router /api/v1/buckets/{bucketName}/tus [options]
+func (h *HttpHandler) TusOptions(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.TusOptions(ctx)
+}
+
+// CreateTusUpload godoc
+// @Summary tus 업로드 생성
+// @Description tus creation 확장입니다. Upload-Metadata의 key(없으면 filename)를 객체 이름, filetype을 콘텐츠 타입으로 사용하고 Location 헤더로 업로드 URL을 반환합니다.
+// @Tags tus
+// @Param bucketName path string true "버킷 이름"
+// @Param Tus-Resumable header string true "tus 버전 (1.0.0)"
+// @Param Upload-Length header int true "전체 업로드 크기"
+// @Param Upload-Metadata header string false "key base64값 쌍 목록"
+// @Success 201
+// @Failure 400 {object} service.ErrorResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 412 {object} service.ErrorResponse
+// @Failure 413 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/tus [post]
+func (h *HttpHandler) CreateTusUpload(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.CreateTusUpload(ctx)
+}
+
+// HeadTusUpload godoc
+// @Summary tus 업로드 오프셋 조회
+// @Description 지금까지 받은 바이트 수를 Upload-Offset 헤더로 반환합니다.
+// @Tags tus
+// @Param bucketName path string true "버킷 이름"
+// @Param uploadId path string true "업로드 ID"
+// @Param Tus-Resumable header string true "tus 버전 (1.0.0)"
+// @Success 200
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 410 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/tus/{uploadId} [head]
+func (h *HttpHandler) HeadTusUpload(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.HeadTusUpload(ctx)
+}
+
+// PatchTusUpload godoc
+// @Summary tus 업로드 이어 올리기
+// @Description Upload-Offset 위치부터 본문을 이어 붙입니다. 마지막 바이트를 받으면 객체로 저장합니다.
+// @Tags tus
+// @Accept application/offset+octet-stream
+// @Param bucketName path string true "버킷 이름"
+// @Param uploadId path string true "업로드 ID"
+// @Param Tus-Resumable header string true "tus 버전 (1.0.0)"
+// @Param Upload-Offset header int true "현재 오프셋"
+// @Success 204
+// @Failure 400 {object} service.ErrorResponse
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 409 {object} service.ErrorResponse
+// @Failure 410 {object} service.ErrorResponse
+// @Failure 415 {object} service.ErrorResponse
+// @Failure 423 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/tus/{uploadId} [patch]
+func (h *HttpHandler) PatchTusUpload(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.PatchTusUpload(ctx)
+}
+
+// TerminateTusUpload godoc
+// @Summary tus 업로드 중단
+// @Description tus termination 확장입니다. 진행 중인 업로드와 받은 데이터를 삭제합니다.
+// @Tags tus
+// @Param bucketName path string true "버킷 이름"
+// @Param uploadId path string true "업로드 ID"
+// @Param Tus-Resumable header string true "tus 버전 (1.0.0)"
+// @Success 204
+// @Failure 404 {object} service.ErrorResponse
+// @Failure 423 {object} service.ErrorResponse
+// @Failure 500 {object} service.ErrorResponse
+// @Router /api/v1/buckets/{bucketName}/tus/{uploadId} [delete]
+func (h *HttpHandler) TerminateTusUpload(w http.ResponseWriter, r *http.Request) {
+	ctx := httpctx.NewChiContext(w, r)
+	h.bucketService.TerminateTusUpload(ctx)
+}
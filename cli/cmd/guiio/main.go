@@ -143,7 +143,7 @@ func runUpload(ctx context.Context, c *client.Client, args []string) {
 		fmt.Fprintf(os.Stderr, "upload failed: %v\n", err)
 		os.Exit(1)
 	}
-	fmt.Printf("uploaded %s/%s size=%d etag=%s\n", resp.Bucket, resp.Object, resp.Size, resp.ETag)
+	fmt.Printf("uploaded %s/%s size=%d etag=%s sha256=%s\n", resp.Bucket, resp.Object, resp.Size, resp.ETag, resp.ChecksumSHA256)
 }
 
 func runDownload(ctx context.Context, c *client.Client, args []string) {
@@ -170,5 +170,9 @@ func runDownload(ctx context.Context, c *client.Client, args []string) {
 		fmt.Fprintf(os.Stderr, "write file failed: %v\n", err)
 		os.Exit(1)
 	}
-	fmt.Printf("downloaded %s (%d bytes) -> %s\n", object, len(res.Data), path)
+	verified := "not verified: server sent no checksum"
+	if res.Verified {
+		verified = "checksum verified"
+	}
+	fmt.Printf("downloaded %s (%d bytes, %s) -> %s\n", object, len(res.Data), verified, path)
 }
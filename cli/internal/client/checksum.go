@@ -0,0 +1,77 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"io"
+	"net/http"
+)
+
+// 서버가 업로드 때 계산해 다운로드 응답에 싣는 체크섬 헤더입니다. 값은 다이제스트의 base64입니다.
+const (
+	ChecksumSHA256Header = "X-Guiio-Checksum-Sha256"
+	ChecksumCRC32CHeader = "X-Guiio-Checksum-Crc32c"
+)
+
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+var crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+
+type Checksums struct {
+	SHA256 string
+	CRC32C string
+}
+
+// checksumWriter는 io.MultiWriter에 끼워 쓰면서 SHA-256, CRC32C를 계산합니다.
+type checksumWriter struct {
+	sha256 hash.Hash
+	crc32c hash.Hash32
+}
+
+func newChecksumWriter() *checksumWriter {
+	return &checksumWriter{sha256: sha256.New(), crc32c: crc32.New(crc32cTable)}
+}
+
+func (w *checksumWriter) Write(p []byte) (int, error) {
+	w.sha256.Write(p)
+	w.crc32c.Write(p)
+	return len(p), nil
+}
+
+func (w *checksumWriter) Sums() Checksums {
+	return Checksums{
+		SHA256: base64.StdEncoding.EncodeToString(w.sha256.Sum(nil)),
+		CRC32C: base64.StdEncoding.EncodeToString(w.crc32c.Sum(nil)),
+	}
+}
+
+// verifyChecksums는 응답 헤더에 있는 체크섬만 비교합니다. 비교한 값이 하나라도 있으면 true를 반환합니다.
+func verifyChecksums(h http.Header, got Checksums) (bool, error) {
+	verified := false
+	for _, c := range []struct{ header, got string }{
+		{ChecksumSHA256Header, got.SHA256},
+		{ChecksumCRC32CHeader, got.CRC32C},
+	} {
+		want := h.Get(c.header)
+		if want == "" {
+			continue
+		}
+		if want != c.got {
+			return false, fmt.Errorf("%w: %s expected %s, got %s", ErrChecksumMismatch, c.header, want, c.got)
+		}
+		verified = true
+	}
+	return verified, nil
+}
+
+func checksumsOf(r io.Reader) (Checksums, error) {
+	w := newChecksumWriter()
+	if _, err := io.Copy(w, r); err != nil {
+		return Checksums{}, err
+	}
+	return w.Sums(), nil
+}
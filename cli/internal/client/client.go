@@ -59,12 +59,18 @@ type UploadObjectResponse struct {
 	Size        int64  `json:"size"`
 	ETag        string `json:"etag"`
 	StoragePath string `json:"storage_path"`
+	// ChecksumSHA256, ChecksumCRC32C는 서버가 받은 본문으로 계산한 값(base64)입니다.
+	ChecksumSHA256 string `json:"checksum_sha256,omitempty"`
+	ChecksumCRC32C string `json:"checksum_crc32c,omitempty"`
 }
 
 type DownloadResult struct {
 	Data        []byte
 	ContentType string
 	FileName    string
+	Checksums   Checksums
+	// Verified는 서버가 보낸 체크섬 헤더와 받은 데이터가 일치함을 확인했을 때 true입니다.
+	Verified bool
 }
 
 func New(baseURL string) *Client {
@@ -124,9 +130,11 @@ func (c *Client) UploadObject(ctx context.Context, bucket, filePath, objectName
 	if err != nil {
 		return out, err
 	}
-	if _, err := io.Copy(fh, f); err != nil {
+	sums := newChecksumWriter()
+	if _, err := io.Copy(io.MultiWriter(fh, sums), f); err != nil {
 		return out, err
 	}
+	want := sums.Sums()
 
 	if objectName != "" {
 		_ = writer.WriteField("objectName", objectName)
@@ -145,10 +153,16 @@ func (c *Client) UploadObject(ctx context.Context, bucket, filePath, objectName
 		return out, err
 	}
 	req.Header.Set("Content-Type", writer.FormDataContentType())
+	// 서버는 전송 중 손상되면 400으로 거부하고, 저장한 값을 응답으로 돌려줍니다.
+	req.Header.Set(ChecksumSHA256Header, want.SHA256)
+	req.Header.Set(ChecksumCRC32CHeader, want.CRC32C)
 
 	if err := c.do(req, &out); err != nil {
 		return out, err
 	}
+	if out.ChecksumSHA256 != "" && out.ChecksumSHA256 != want.SHA256 {
+		return out, fmt.Errorf("%w: stored sha256 %s, local %s", ErrChecksumMismatch, out.ChecksumSHA256, want.SHA256)
+	}
 	return out, nil
 }
 
@@ -175,6 +189,13 @@ func (c *Client) DownloadObject(ctx context.Context, bucket, object string) (Dow
 	if err != nil {
 		return out, err
 	}
+	out.Checksums, err = checksumsOf(bytes.NewReader(data))
+	if err != nil {
+		return out, err
+	}
+	if out.Verified, err = verifyChecksums(resp.Header, out.Checksums); err != nil {
+		return out, err
+	}
 	out.Data = data
 	out.ContentType = resp.Header.Get("Content-Type")
 	out.FileName = object